@@ -0,0 +1,80 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DeletionGracePeriod is how long a deleted account can still be restored by signing in
+const DeletionGracePeriod = time.Hour * 24 * 30
+
+type PostgresRepositoryQueries interface {
+	GetDueForDeletion(ctx context.Context, now time.Time) ([]int64, error)
+	Export(ctx context.Context, userID int64) (map[string]json.RawMessage, error)
+}
+
+type PostgresRepositoryCommand interface {
+	Purge(ctx context.Context, userID int64) (bool, error)
+}
+
+type Service struct {
+	postgresRepoQuery   PostgresRepositoryQueries
+	postgresRepoCommand PostgresRepositoryCommand
+}
+
+// NewService will create a new account service object
+func NewService(pq PostgresRepositoryQueries, pc PostgresRepositoryCommand) *Service {
+	return &Service{
+		postgresRepoQuery:   pq,
+		postgresRepoCommand: pc,
+	}
+}
+
+// Export returns everything stored about the user, keyed by section
+func (s *Service) Export(ctx context.Context, userID int64) (map[string]json.RawMessage, error) {
+	return s.postgresRepoQuery.Export(ctx, userID)
+}
+
+// PurgeDue anonymises every account whose deletion grace period has ended
+func (s *Service) PurgeDue(ctx context.Context) error {
+	ids, err := s.postgresRepoQuery.GetDueForDeletion(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		purged, err := s.postgresRepoCommand.Purge(ctx, id)
+		if err != nil {
+			return err
+		}
+		if purged {
+			logrus.WithFields(logrus.Fields{
+				"event":   "account_purged",
+				"user_id": id,
+			}).Info("Deleted account purged")
+		}
+	}
+
+	return nil
+}
+
+// RunPurger calls PurgeDue every interval until the context is cancelled
+func (s *Service) RunPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.PurgeDue(ctx); err != nil {
+			logrus.Errorf("Failed to purge deleted accounts: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
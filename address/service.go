@@ -3,6 +3,7 @@ package address
 import (
 	"context"
 	"santapan/domain"
+	"santapan/policy"
 )
 
 // PostgresRepositoryQueries defines the methods for querying the category repository.
@@ -15,7 +16,7 @@ type PostgresRepositoryQueries interface {
 type PostgresRepositoryCommand interface {
 	Create(ctx context.Context, a domain.Address) (domain.Address, error)
 	Update(ctx context.Context, a domain.Address) (domain.Address, error)
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, userID int64, id int64) error
 }
 
 //go:generate mockery --name CategoryRepository
@@ -41,13 +42,10 @@ func (c *Service) GetByUserID(ctx context.Context, userID int64) ([]domain.Addre
 	return res, nil
 }
 
-// GetByID retrieves an address by the given ID.
-func (c *Service) GetByID(ctx context.Context, id int64) (domain.Address, error) {
-	res, err := c.postgresRepoQuery.GetByID(ctx, id)
-	if err != nil {
-		return res, err
-	}
-	return res, nil
+// GetByID retrieves an address of the user by the given ID. Addresses of other
+// users are reported as domain.ErrNotFound.
+func (c *Service) GetByID(ctx context.Context, userID int64, id int64) (domain.Address, error) {
+	return policy.Load(ctx, userID, id, c.postgresRepoQuery.GetByID)
 }
 
 // Create creates a new address.
@@ -59,8 +57,14 @@ func (c *Service) Create(ctx context.Context, a domain.Address) (domain.Address,
 	return res, nil
 }
 
-// Update updates an address.
+// Update updates an address owned by a.UserID.
 func (c *Service) Update(ctx context.Context, a domain.Address) (domain.Address, error) {
+	existing, err := policy.Load(ctx, a.UserID, a.ID, c.postgresRepoQuery.GetByID)
+	if err != nil {
+		return domain.Address{}, err
+	}
+
+	a.CreatedAt = existing.CreatedAt
 	res, err := c.postgresRepoCommand.Update(ctx, a)
 	if err != nil {
 		return res, err
@@ -68,9 +72,13 @@ func (c *Service) Update(ctx context.Context, a domain.Address) (domain.Address,
 	return res, nil
 }
 
-// Delete deletes an address.
-func (c *Service) Delete(ctx context.Context, id int64) error {
-	err := c.postgresRepoCommand.Delete(ctx, id)
+// Delete deletes an address of the user.
+func (c *Service) Delete(ctx context.Context, userID int64, id int64) error {
+	if _, err := policy.Load(ctx, userID, id, c.postgresRepoQuery.GetByID); err != nil {
+		return err
+	}
+
+	err := c.postgresRepoCommand.Delete(ctx, userID, id)
 	if err != nil {
 		return err
 	}
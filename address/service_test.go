@@ -0,0 +1,116 @@
+package address
+
+import (
+	"context"
+	"santapan/domain"
+	"testing"
+)
+
+const (
+	alice int64 = 1
+	bob   int64 = 2
+)
+
+// memoryRepository keeps addresses in a map and records the writes it receives
+type memoryRepository struct {
+	addresses map[int64]domain.Address
+	updated   []int64
+	deleted   []int64
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{addresses: map[int64]domain.Address{
+		10: {ID: 10, UserID: alice, Label: "Home", Address: "Jl. Melati 1"},
+	}}
+}
+
+func (r *memoryRepository) GetByUserID(ctx context.Context, userID int64) ([]domain.Address, error) {
+	res := make([]domain.Address, 0)
+	for _, a := range r.addresses {
+		if a.UserID == userID {
+			res = append(res, a)
+		}
+	}
+	return res, nil
+}
+
+func (r *memoryRepository) GetByID(ctx context.Context, id int64) (domain.Address, error) {
+	a, ok := r.addresses[id]
+	if !ok {
+		return domain.Address{}, domain.ErrNotFound
+	}
+	return a, nil
+}
+
+func (r *memoryRepository) Create(ctx context.Context, a domain.Address) (domain.Address, error) {
+	a.ID = int64(len(r.addresses) + 100)
+	r.addresses[a.ID] = a
+	return a, nil
+}
+
+func (r *memoryRepository) Update(ctx context.Context, a domain.Address) (domain.Address, error) {
+	r.updated = append(r.updated, a.ID)
+	r.addresses[a.ID] = a
+	return a, nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, userID int64, id int64) error {
+	r.deleted = append(r.deleted, id)
+	delete(r.addresses, id)
+	return nil
+}
+
+func TestGetByID(t *testing.T) {
+	repo := newMemoryRepository()
+	service := NewService(repo, repo)
+
+	a, err := service.GetByID(context.Background(), alice, 10)
+	if err != nil || a.ID != 10 {
+		t.Fatalf("owner: got %+v, %v, want address 10", a, err)
+	}
+
+	a, err = service.GetByID(context.Background(), bob, 10)
+	if err != domain.ErrNotFound {
+		t.Fatalf("other user: got error %v, want domain.ErrNotFound", err)
+	}
+	if a != (domain.Address{}) {
+		t.Fatalf("other user: got %+v, want no address", a)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	repo := newMemoryRepository()
+	service := NewService(repo, repo)
+
+	_, err := service.Update(context.Background(), domain.Address{ID: 10, UserID: bob, Address: "Jl. Mawar 2"})
+	if err != domain.ErrNotFound {
+		t.Fatalf("other user: got error %v, want domain.ErrNotFound", err)
+	}
+	if len(repo.updated) != 0 || repo.addresses[10].Address != "Jl. Melati 1" {
+		t.Fatalf("other user changed the address: %+v", repo.addresses[10])
+	}
+
+	a, err := service.Update(context.Background(), domain.Address{ID: 10, UserID: alice, Address: "Jl. Mawar 2"})
+	if err != nil || a.Address != "Jl. Mawar 2" {
+		t.Fatalf("owner: got %+v, %v, want the updated address", a, err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	repo := newMemoryRepository()
+	service := NewService(repo, repo)
+
+	if err := service.Delete(context.Background(), bob, 10); err != domain.ErrNotFound {
+		t.Fatalf("other user: got error %v, want domain.ErrNotFound", err)
+	}
+	if len(repo.deleted) != 0 {
+		t.Fatalf("other user deleted address %v", repo.deleted)
+	}
+
+	if err := service.Delete(context.Background(), alice, 10); err != nil {
+		t.Fatalf("owner: got error %v, want nil", err)
+	}
+	if _, ok := repo.addresses[10]; ok {
+		t.Fatal("owner: address 10 was not deleted")
+	}
+}
@@ -4,24 +4,54 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"santapan/account"
 	"santapan/address"
 	"santapan/article"
 	"santapan/banner"
 	"santapan/bundling"
+	"santapan/cart"
 	"santapan/category"
+	"santapan/checkout"
 	"santapan/courier"
+	"santapan/device"
+	"santapan/domain"
+	"santapan/idempotency"
+	"santapan/identity"
+	memoryRepository "santapan/internal/repository/memory"
 	postgresCommands "santapan/internal/repository/postgres/commands"
 	postgresQueries "santapan/internal/repository/postgres/queries"
+	redisRepository "santapan/internal/repository/redis"
 	"santapan/internal/rest"
+	"santapan/internal/rest/middleware"
+	"santapan/invoice"
+	"santapan/loginattempt"
+	"santapan/magiclink"
 	"santapan/menu"
+	"santapan/mfa"
 	"santapan/nutrition"
+	"santapan/order"
+	"santapan/otp"
+	"santapan/passwordreset"
+	"santapan/payment"
 	"santapan/personalisasi"
 	pkgEcho "santapan/pkg/echo"
+	"santapan/pkg/jwtkey"
+	"santapan/pkg/mailer"
+	"santapan/pkg/oidc"
+	"santapan/pkg/otpsender"
+	"santapan/pkg/password"
+	"santapan/pkg/paymentgateway"
+	pkgRedis "santapan/pkg/redis"
+	"santapan/pkg/secretbox"
 	"santapan/pkg/sql"
+	"santapan/role"
 	"santapan/token"
 	"santapan/user"
+	"santapan/voucher"
 	"syscall"
+	"time"
 
+	"context"
 	"fmt"
 
 	"github.com/golang-migrate/migrate/v4"
@@ -30,6 +60,8 @@ import (
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq" // Import the PostgreSQL driver
+	goRedis "github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -59,6 +91,9 @@ func main() {
 	tokenQueryRepo := postgresQueries.NewPostgresTokenQueryRepository(conn)
 	tokenCommandRepo := postgresCommands.NewPostgresTokenCommandRepository(conn)
 
+	deviceQueryRepo := postgresQueries.NewPostgresDeviceQueryRepository(conn)
+	deviceCommandRepo := postgresCommands.NewPostgresDeviceCommandRepository(conn)
+
 	articleQueryRepo := postgresQueries.NewArticleRepository(conn)
 	articleCommandRepo := postgresQueries.NewArticleRepository(conn)
 
@@ -86,9 +121,39 @@ func main() {
 
 	nutritionQueryRepo := postgresQueries.NewNutritionRepository(conn)
 
+	mfaQueryRepo := postgresQueries.NewPostgresMfaQueryRepository(conn)
+	mfaCommandRepo := postgresCommands.NewPostgresMfaCommandRepository(conn)
+
+	accountQueryRepo := postgresQueries.NewPostgresAccountQueryRepository(conn)
+	accountCommandRepo := postgresCommands.NewPostgresAccountCommandRepository(conn)
+
+	passwordResetCommandRepo := postgresCommands.NewPostgresPasswordResetCommandRepository(conn)
+	magicLinkCommandRepo := postgresCommands.NewPostgresMagicLinkCommandRepository(conn)
+	cartQueryRepo := postgresQueries.NewPostgresCartQueryRepository(conn)
+	cartCommandRepo := postgresCommands.NewPostgresCartCommandRepository(conn)
+	paymentQueryRepo := postgresQueries.NewPostgresPaymentQueryRepository(conn)
+	paymentCommandRepo := postgresCommands.NewPostgresPaymentCommandRepository(conn)
+	checkoutCommandRepo := postgresCommands.NewPostgresCheckoutCommandRepository(conn)
+	voucherQueryRepo := postgresQueries.NewPostgresVoucherQueryRepository(conn)
+	voucherCommandRepo := postgresCommands.NewPostgresVoucherCommandRepository(conn)
+	idempotencyCommandRepo := postgresCommands.NewPostgresIdempotencyCommandRepository(conn)
+	orderQueryRepo := postgresQueries.NewPostgresOrderQueryRepository(conn)
+	orderCommandRepo := postgresCommands.NewPostgresOrderCommandRepository(conn)
+	invoiceQueryRepo := postgresQueries.NewPostgresInvoiceQueryRepository(conn)
+	invoiceCommandRepo := postgresCommands.NewPostgresInvoiceCommandRepository(conn)
+	identityQueryRepo := postgresQueries.NewPostgresIdentityQueryRepository(conn)
+	identityCommandRepo := postgresCommands.NewPostgresIdentityCommandRepository(conn)
+
+	roleQueryRepo := postgresQueries.NewPostgresRoleQueryRepository(conn)
+	roleCommandRepo := postgresCommands.NewPostgresRoleCommandRepository(conn)
+
+	otpQueryRepo := postgresQueries.NewPostgresOtpQueryRepository(conn)
+	otpCommandRepo := postgresCommands.NewPostgresOtpCommandRepository(conn)
+
 	// Initialize services
 	tokenService := token.NewService(tokenQueryRepo, tokenCommandRepo)
-	userService := user.NewService(userQueryRepo, userQueryCommand)
+	userService := user.NewService(userQueryRepo, userQueryCommand, password.NewFromEnv())
+	deviceService := device.NewService(deviceQueryRepo, deviceCommandRepo)
 	articleService := article.NewService(articleQueryRepo, articleCommandRepo)
 	categoryService := category.NewService(categoryQueryRepo, categoryCommandRepo)
 	bannerService := banner.NewService(bannerQueryRepo, bannerCommandRepo)
@@ -98,15 +163,104 @@ func main() {
 	courierService := courier.NewService(courierQueryRepo)
 	personalisasiService := personalisasi.NewService(personalisasiCommandRepo, personalisasiQueryRepo)
 	nutritionService := nutrition.NewService(nutritionQueryRepo)
+	cartService := cart.NewService(cartQueryRepo, cartCommandRepo, menuQueryRepo, bundlingQueryRepo)
+	// The payment gateway selected by PAYMENT_PROVIDER, the fake one needs APP_ENV=development.
+	// Without one the API runs with checkout and payments switched off.
+	paymentProvider, err := paymentgateway.NewFromEnv(rest.AppURL())
+	if err == paymentgateway.ErrNotConfigured {
+		logrus.Warn("No payment provider configured, checkout and payments are disabled")
+	} else if err != nil {
+		log.Fatalf("Failed to configure payment provider: %v", err)
+	}
+	paymentService := payment.NewService(paymentQueryRepo, paymentCommandRepo, paymentProvider, os.Getenv("PAYMENT_RETURN_URL"))
+	orderService := order.NewService(orderQueryRepo, orderCommandRepo, cartQueryRepo, addressQueryRepo, courierQueryRepo, paymentQueryRepo)
+	voucherService := voucher.NewService(voucherQueryRepo, voucherCommandRepo, cartService)
+	invoiceService := invoice.NewService(invoiceQueryRepo, invoiceCommandRepo, orderService, userQueryRepo)
+	// Service fee and PPN charged at checkout, set with SERVICE_FEE_RATE and PPN_RATE
+	checkoutService := checkout.NewService(checkoutCommandRepo, cartService, addressService, courierService, voucherService, paymentService, checkout.RatesFromEnv())
+	accountService := account.NewService(accountQueryRepo, accountCommandRepo)
+	passwordResetService := passwordreset.NewService(passwordResetCommandRepo)
+	magicLinkService := magiclink.NewService(magicLinkCommandRepo)
+	// Codes are sent through OTP_DRIVER, without one phone verification and OTP login are switched off
+	otpSenders := make(map[domain.OtpType]otp.OtpSender)
+	for _, channel := range []domain.OtpType{domain.Sms, domain.Whatsapp} {
+		sender, err := otpsender.NewFromEnv(string(channel))
+		if err == otpsender.ErrNotConfigured {
+			logrus.Warn("No OTP sender configured, phone verification and OTP login are disabled")
+			break
+		}
+		if err != nil {
+			log.Fatalf("Failed to configure OTP sender: %v", err)
+		}
+		otpSenders[channel] = sender
+	}
+	otpService := otp.NewService(otpQueryRepo, otpCommandRepo, otpSenders)
+	var phoneOtpService rest.OtpService
+	if len(otpSenders) > 0 {
+		phoneOtpService = otpService
+	}
+	// The user who verified BOOTSTRAP_ADMIN_EMAIL becomes the first admin when signing in
+	roleService := role.NewService(roleQueryRepo, roleCommandRepo, os.Getenv("BOOTSTRAP_ADMIN_EMAIL"))
+	redisClient := newRedisClient()
+	loginAttemptService := loginattempt.NewService(newLoginAttemptStore(redisClient))
+	idempotencyService := idempotency.NewService(newIdempotencyStore(redisClient, idempotencyCommandRepo), idempotency.TTLFromEnv())
+	// Load the JWT keys shared by the token issuer and AuthMiddleware
+	keySet, err := jwtkey.LoadFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+
+	// Mails verification, password reset and sign-in links, logged only in development
+	mailService, err := mailer.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure mailer: %v", err)
+	}
+
+	// Encrypts secrets stored at rest, such as TOTP secrets
+	secretBox, err := secretbox.NewFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load secretbox key: %v", err)
+	}
+	mfaService := mfa.NewService(mfaQueryRepo, mfaCommandRepo, secretBox)
+
+	// Verifies ID tokens of the social identity providers configured in OIDC_*
+	identityService := identity.NewService(identityQueryRepo, identityCommandRepo, oidc.LoadFromEnv(), userService)
+
 	e := pkgEcho.Setup()
 
-	rest.NewAuthHandler(e, tokenService, userService)
+	middleware.SetKeyProvider(keySet)
+	// Reject access tokens whose session was logged out or revoked
+	middleware.SetSessionChecker(tokenService)
+
+	rest.NewAuthHandler(e, tokenService, userService, deviceService, roleService, loginAttemptService, mfaService, magicLinkService, phoneOtpService, identityService, keySet, mailService)
+	rest.NewDeviceHandler(e, deviceService, tokenService)
+	rest.NewVerificationHandler(e, userService, keySet, mailService)
+	// Confirms sensitive changes, also for accounts without a password
+	reauth := rest.Reauthenticator{UserService: userService, Identities: identityService, MagicLinks: magicLinkService}
+	rest.NewProfileHandler(e, userService, keySet, mailService, reauth)
+	rest.NewMfaHandler(e, mfaService, userService)
+	rest.NewIdentityHandler(e, identityService, userService)
+	rest.NewAccountHandler(e, accountService, userService, tokenService, reauth)
+	rest.NewPasswordHandler(e, userService, tokenService, passwordResetService, mailService)
+	if phoneOtpService != nil {
+		rest.NewPhoneHandler(e, phoneOtpService, userService)
+	}
+	rest.NewJWKSHandler(e, keySet)
+	rest.NewRoleHandler(e, roleService, userService, tokenService)
 	rest.NewArticleHandler(e, articleService)
 	rest.NewCategoryHandler(e, categoryService)
 	rest.NewBannerHandler(e, bannerService)
 	rest.NewMenuHandler(e, menuService, personalisasiService)
 	rest.NewBundlingHandler(e, bundlingService)
 	rest.NewAddressHandler(e, addressService)
+	rest.NewCartHandler(e, cartService)
+	rest.NewVoucherHandler(e, voucherService)
+	if paymentProvider != nil {
+		rest.NewCheckoutHandler(e, checkoutService, userService, idempotencyService)
+		rest.NewPaymentHandler(e, paymentService, paymentProvider, idempotencyService)
+	}
+	rest.NewOrderHandler(e, orderService, idempotencyService)
+	rest.NewInvoiceHandler(e, invoiceService)
 	rest.NewCourierHandler(e, courierService)
 	rest.NewPersonalisasiHandler(e, personalisasiService)
 	rest.NewNutritionHandler(e, nutritionService)
@@ -114,6 +268,20 @@ func main() {
 		pkgEcho.Start(e)
 	}()
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	// Purge accounts whose deletion grace period has ended
+	go accountService.RunPurger(jobsCtx, time.Hour)
+
+	// Settle payments whose webhook never arrived
+	if paymentProvider != nil {
+		go paymentService.RunReconciler(jobsCtx, payment.ReconcileAfter)
+	}
+
+	// Forget idempotency keys once their window has passed
+	go idempotencyService.RunCleaner(jobsCtx, time.Hour)
+
 	// Channel to listen for termination signals
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
@@ -124,6 +292,47 @@ func main() {
 	pkgEcho.Shutdown(e, defaultTimeout)
 }
 
+// newRedisClient connects to Redis when REDIS_HOST is set, nil means Redis is not available
+func newRedisClient() *goRedis.Client {
+	host := os.Getenv("REDIS_HOST")
+	if host == "" {
+		logrus.Warn("REDIS_HOST is not set")
+		return nil
+	}
+
+	client := pkgRedis.NewRedisClient(host, os.Getenv("REDIS_PASSWORD"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		logrus.Warnf("Redis is unreachable: %v", err)
+		return nil
+	}
+
+	return client
+}
+
+// newLoginAttemptStore shares failed login attempts through Redis when it is available
+// and falls back to process memory otherwise
+func newLoginAttemptStore(client *goRedis.Client) loginattempt.Store {
+	if client == nil {
+		logrus.Warn("Tracking login attempts in memory")
+		return memoryRepository.NewLoginAttemptRepository()
+	}
+
+	return redisRepository.NewLoginAttemptRepository(client)
+}
+
+// newIdempotencyStore keeps idempotency keys in Redis when it is available and in Postgres otherwise
+func newIdempotencyStore(client *goRedis.Client, postgresStore idempotency.Store) idempotency.Store {
+	if client == nil {
+		return postgresStore
+	}
+
+	return redisRepository.NewIdempotencyRepository(client)
+}
+
 // runMigrations runs the database migrations
 func runMigrations() error {
 	// Build the database connection string from environment variables
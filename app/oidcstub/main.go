@@ -0,0 +1,38 @@
+// Command oidcstub runs a local stand-in identity provider for social sign-in.
+// Point a provider of the API at it, for example:
+//
+//	OIDC_PROVIDERS=google OIDC_GOOGLE_CLIENT_IDS=santapan-dev \
+//	OIDC_GOOGLE_ISSUER=http://localhost:9999 OIDC_GOOGLE_JWKS_URL=http://localhost:9999/jwks
+//
+// and mint ID tokens with:
+//
+//	curl -X POST localhost:9999/token -d '{"aud":"santapan-dev","sub":"123","email":"a@example.com","email_verified":true}'
+package main
+
+import (
+	"net/http"
+	"os"
+	"santapan/pkg/oidc"
+
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	address := os.Getenv("OIDC_STUB_ADDR")
+	if address == "" {
+		address = ":9999"
+	}
+
+	issuer := os.Getenv("OIDC_STUB_ISSUER")
+	if issuer == "" {
+		issuer = "http://localhost" + address
+	}
+
+	stub, err := oidc.NewStub(issuer)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	logrus.Infof("OIDC stub with issuer %s listening on %s", issuer, address)
+	logrus.Fatal(http.ListenAndServe(address, stub))
+}
@@ -0,0 +1,150 @@
+package cart
+
+import (
+	"context"
+	"santapan/domain"
+)
+
+// MaxQuantity is the largest quantity of a single item in a cart
+const MaxQuantity = 99
+
+type PostgresRepositoryQueries interface {
+	GetActiveByUserID(ctx context.Context, userID int64) (domain.Cart, error)
+	GetByID(ctx context.Context, id int64) (domain.Cart, error)
+}
+
+type PostgresRepositoryCommand interface {
+	EnsureActive(ctx context.Context, userID int64) (int64, error)
+	AddItem(ctx context.Context, item domain.CartItem, maxQuantity int) error
+	UpdateItemQuantity(ctx context.Context, cartID int64, itemID int64, quantity int) error
+	DeleteItem(ctx context.Context, cartID int64, itemID int64) error
+	Reprice(ctx context.Context, cartID int64) error
+}
+
+// MenuRepository looks up the menus that can be added to a cart
+type MenuRepository interface {
+	GetByID(ctx context.Context, id int64) (domain.Menu, error)
+}
+
+// BundlingRepository looks up the bundlings that can be added to a cart
+type BundlingRepository interface {
+	GetByID(ctx context.Context, id int64) (domain.Bundling, error)
+}
+
+type Service struct {
+	postgresRepoQuery   PostgresRepositoryQueries
+	postgresRepoCommand PostgresRepositoryCommand
+	menuRepo            MenuRepository
+	bundlingRepo        BundlingRepository
+}
+
+// NewService will create a new cart service object
+func NewService(pq PostgresRepositoryQueries, pc PostgresRepositoryCommand, menuRepo MenuRepository, bundlingRepo BundlingRepository) *Service {
+	return &Service{
+		postgresRepoQuery:   pq,
+		postgresRepoCommand: pc,
+		menuRepo:            menuRepo,
+		bundlingRepo:        bundlingRepo,
+	}
+}
+
+// GetActive returns the active cart of the user priced at the current menu and
+// bundling prices. A user without a cart gets an empty one, which is not stored.
+func (s *Service) GetActive(ctx context.Context, userID int64) (domain.Cart, error) {
+	cart, err := s.postgresRepoQuery.GetActiveByUserID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return domain.Cart{
+			UserID: userID,
+			Status: domain.CartStatusActive,
+			Items:  make([]domain.CartItem, 0),
+		}, nil
+	}
+	if err != nil {
+		return domain.Cart{}, err
+	}
+
+	return s.reprice(ctx, cart.ID)
+}
+
+// AddItem adds a menu or a bundling to the active cart of the user, creating the
+// cart when needed. Returns domain.ErrBadParamInput unless exactly one of the IDs is
+// set and domain.ErrNotFound when the menu or bundling does not exist.
+func (s *Service) AddItem(ctx context.Context, userID int64, body domain.AddCartItemBody) (domain.Cart, error) {
+	if (body.MenuID == nil) == (body.BundlingID == nil) || body.Quantity < 1 || body.Quantity > MaxQuantity {
+		return domain.Cart{}, domain.ErrBadParamInput
+	}
+
+	item := domain.CartItem{
+		MenuID:     body.MenuID,
+		BundlingID: body.BundlingID,
+		Quantity:   body.Quantity,
+	}
+
+	if body.MenuID != nil {
+		menu, err := s.menuRepo.GetByID(ctx, *body.MenuID)
+		if err != nil {
+			return domain.Cart{}, err
+		}
+		item.Name, item.ImageURL, item.Price = menu.Title, menu.ImageURL, menu.Price
+	} else {
+		bundling, err := s.bundlingRepo.GetByID(ctx, *body.BundlingID)
+		if err != nil {
+			return domain.Cart{}, err
+		}
+		item.Name, item.ImageURL, item.Price = bundling.BundlingName, bundling.ImageURL, bundling.Price
+	}
+
+	cartID, err := s.postgresRepoCommand.EnsureActive(ctx, userID)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+	item.CartID = cartID
+
+	if err = s.postgresRepoCommand.AddItem(ctx, item, MaxQuantity); err != nil {
+		return domain.Cart{}, err
+	}
+
+	return s.reprice(ctx, cartID)
+}
+
+// UpdateItem sets the quantity of an item in the active cart of the user
+func (s *Service) UpdateItem(ctx context.Context, userID int64, itemID int64, quantity int) (domain.Cart, error) {
+	if quantity < 1 || quantity > MaxQuantity {
+		return domain.Cart{}, domain.ErrBadParamInput
+	}
+
+	cart, err := s.postgresRepoQuery.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+
+	if err = s.postgresRepoCommand.UpdateItemQuantity(ctx, cart.ID, itemID, quantity); err != nil {
+		return domain.Cart{}, err
+	}
+
+	return s.reprice(ctx, cart.ID)
+}
+
+// RemoveItem removes an item from the active cart of the user
+func (s *Service) RemoveItem(ctx context.Context, userID int64, itemID int64) (domain.Cart, error) {
+	cart, err := s.postgresRepoQuery.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+
+	if err = s.postgresRepoCommand.DeleteItem(ctx, cart.ID, itemID); err != nil {
+		return domain.Cart{}, err
+	}
+
+	return s.reprice(ctx, cart.ID)
+}
+
+// reprice recalculates the cart from the current prices and returns it. Prices sent
+// by clients are never used.
+func (s *Service) reprice(ctx context.Context, cartID int64) (domain.Cart, error) {
+	if err := s.postgresRepoCommand.Reprice(ctx, cartID); err != nil {
+		return domain.Cart{}, err
+	}
+
+	return s.postgresRepoQuery.GetByID(ctx, cartID)
+}
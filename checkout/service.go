@@ -0,0 +1,175 @@
+package checkout
+
+import (
+	"context"
+	"os"
+	"santapan/domain"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+type PostgresRepositoryCommand interface {
+	Create(ctx context.Context, cartID int64, itemsTotal domain.Money, payment *domain.Payment, transaction *domain.Transaction, redemption *domain.VoucherRedemption) error
+}
+
+// CartService supplies the active cart priced at the current prices
+type CartService interface {
+	GetActive(ctx context.Context, userID int64) (domain.Cart, error)
+}
+
+// AddressService looks up the delivery addresses of a user
+type AddressService interface {
+	GetByID(ctx context.Context, userID int64, id int64) (domain.Address, error)
+}
+
+// CourierService looks up couriers and their price
+type CourierService interface {
+	FetchByID(ctx context.Context, id int) (*domain.Courier, error)
+}
+
+// VoucherService quotes the discount of a voucher on a cart
+type VoucherService interface {
+	Quote(ctx context.Context, userID int64, code string, cart domain.Cart) (domain.VoucherQuote, error)
+}
+
+// PaymentService creates the hosted payment page of a new payment
+type PaymentService interface {
+	StartSession(ctx context.Context, payment *domain.Payment) error
+}
+
+type Service struct {
+	postgresRepoCommand PostgresRepositoryCommand
+	cartService         CartService
+	addressService      AddressService
+	courierService      CourierService
+	voucherService      VoucherService
+	paymentService      PaymentService
+	rates               domain.FeeRates
+}
+
+// RatesFromEnv reads the service fee and PPN percentages from SERVICE_FEE_RATE and
+// PPN_RATE, falling back to the defaults when unset or invalid
+func RatesFromEnv() domain.FeeRates {
+	return domain.FeeRates{
+		ServiceFee: rateFromEnv("SERVICE_FEE_RATE", domain.DefaultServiceFeeRate),
+		Tax:        rateFromEnv("PPN_RATE", domain.DefaultTaxRate),
+	}
+}
+
+func rateFromEnv(name string, fallback float64) float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate < 0 || rate > 100 {
+		logrus.Warnf("Invalid %s %q, using %g", name, value, fallback)
+		return fallback
+	}
+	return rate
+}
+
+// NewService will create a new checkout service object
+func NewService(pc PostgresRepositoryCommand, cartService CartService, addressService AddressService, courierService CourierService, voucherService VoucherService, paymentService PaymentService, rates domain.FeeRates) *Service {
+	return &Service{
+		postgresRepoCommand: pc,
+		cartService:         cartService,
+		addressService:      addressService,
+		courierService:      courierService,
+		voucherService:      voucherService,
+		paymentService:      paymentService,
+		rates:               rates,
+	}
+}
+
+// Checkout turns the active cart of the user into a transaction with an unpaid
+// payment of the items total minus the voucher discount plus the courier price, the
+// service fee and PPN, and
+// opens the hosted payment page of the payment. When the provider is unreachable the
+// order still stands and the page can be requested again later. Returns
+// domain.ErrBadParamInput for an empty cart, domain.ErrNotFound for an unknown address
+// or courier, domain.ErrConflict when the cart changed while checking out and the
+// voucher errors when the voucher can not be used, an unknown code included.
+func (s *Service) Checkout(ctx context.Context, userID int64, body domain.CheckoutBody) (domain.Checkout, error) {
+	cart, err := s.cartService.GetActive(ctx, userID)
+	if err != nil {
+		return domain.Checkout{}, err
+	}
+	if cart.ID == 0 || len(cart.Items) == 0 {
+		return domain.Checkout{}, domain.ErrBadParamInput
+	}
+
+	address, err := s.addressService.GetByID(ctx, userID, body.AddressID)
+	if err != nil {
+		return domain.Checkout{}, err
+	}
+
+	courier, err := s.courierService.FetchByID(ctx, int(body.CourierID))
+	if err != nil {
+		return domain.Checkout{}, err
+	}
+	if courier == nil {
+		return domain.Checkout{}, domain.ErrNotFound
+	}
+
+	var (
+		discount   domain.Money
+		voucherID  *int64
+		redemption *domain.VoucherRedemption
+	)
+	if body.VoucherCode != "" {
+		quote, err := s.voucherService.Quote(ctx, userID, body.VoucherCode, cart)
+		if err == domain.ErrNotFound {
+			return domain.Checkout{}, domain.ErrVoucherUnavailable
+		}
+		if err != nil {
+			return domain.Checkout{}, err
+		}
+		discount, voucherID = quote.Discount, &quote.VoucherID
+		redemption = &domain.VoucherRedemption{VoucherID: quote.VoucherID, UserID: userID, Discount: quote.Discount}
+	}
+
+	subtotal := cart.TotalPrice - discount
+	serviceFee, tax := s.rates.Charges(subtotal, courier.Price)
+	amount := subtotal + courier.Price + serviceFee + tax
+
+	payment := domain.Payment{
+		ReferenceID: body.PaymentMethod,
+		UserID:      userID,
+		Amount:      amount,
+		Status:      domain.PaymentStatusUnpaid,
+	}
+	transaction := domain.Transaction{
+		UserID:         userID,
+		CourierID:      courier.ID,
+		AddressID:      address.ID,
+		VoucherID:      voucherID,
+		Discount:       discount,
+		CourierFee:     courier.Price,
+		ServiceFee:     serviceFee,
+		ServiceFeeRate: s.rates.ServiceFee,
+		Tax:            tax,
+		TaxRate:        s.rates.Tax,
+		Amount:         amount,
+		Status:         domain.OrderStatusPendingPayment,
+	}
+
+	if err = s.postgresRepoCommand.Create(ctx, cart.ID, cart.TotalPrice, &payment, &transaction, redemption); err != nil {
+		return domain.Checkout{}, err
+	}
+
+	if err = s.paymentService.StartSession(ctx, &payment); err != nil {
+		logrus.Errorf("Failed to start session of payment %d: %v", payment.ID, err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":          "checkout",
+		"user_id":        userID,
+		"transaction_id": transaction.ID,
+		"amount":         amount,
+	}).Info("Cart checked out")
+
+	return domain.Checkout{Transaction: transaction, Payment: payment}, nil
+}
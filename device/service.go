@@ -8,11 +8,16 @@ import (
 
 type PostgresRepositoryQueries interface {
 	GetByUserID(ctx context.Context, id int64) (res domain.Device, err error)
+	FetchByUserID(ctx context.Context, userID int64) (res []domain.Device, err error)
+	GetByID(ctx context.Context, id int64) (res domain.Device, err error)
+	GetByUniqueID(ctx context.Context, userID int64, uniqueID string) (res domain.Device, err error)
 }
 
 type PostgresRepositoryCommand interface {
 	Store(ctx context.Context, token *domain.Device) (err error)
 	Update(ctx context.Context, token *domain.Device) (err error)
+	Touch(ctx context.Context, id int64, ipAddress string, lastSeenAt time.Time) (err error)
+	Delete(ctx context.Context, id int64) (err error)
 }
 
 //go:generate mockery --name ArticleRepository
@@ -46,3 +51,53 @@ func (a *Service) Update(ctx context.Context, device *domain.Device) (err error)
 	device.UpdatedAt = time.Now()
 	return a.postgresRepoCommand.Update(ctx, device)
 }
+
+// FetchByUserID retrieves every signed in device of the user
+func (s *Service) FetchByUserID(ctx context.Context, userID int64) ([]domain.Device, error) {
+	return s.postgresRepoQuery.FetchByUserID(ctx, userID)
+}
+
+// GetByID retrieves a signed in device by its ID
+func (s *Service) GetByID(ctx context.Context, id int64) (domain.Device, error) {
+	return s.postgresRepoQuery.GetByID(ctx, id)
+}
+
+// Register records the device the user signs in from, registering it again
+// if it was signed out before
+func (s *Service) Register(ctx context.Context, userID int64, info domain.DeviceHeaderInformation) (domain.Device, error) {
+	now := time.Now()
+
+	device, err := s.postgresRepoQuery.GetByUniqueID(ctx, userID, info.DeviceID)
+	if err != nil && err != domain.ErrNotFound {
+		return domain.Device{}, err
+	}
+
+	device.Name = info.DeviceName
+	device.Brand = info.DeviceBrand
+	device.Model = info.DeviceModel
+	device.IPAddress = info.IPAddress
+	device.LastSeenAt = now
+
+	if err == domain.ErrNotFound {
+		device.UniqueID = info.DeviceID
+		device.UserID = userID
+		device.CreatedAt = now
+		device.UpdatedAt = now
+		err = s.postgresRepoCommand.Store(ctx, &device)
+		return device, err
+	}
+
+	device.DeletedAt = nil
+	err = s.Update(ctx, &device)
+	return device, err
+}
+
+// Touch records the IP address the device was last seen from
+func (s *Service) Touch(ctx context.Context, id int64, ipAddress string) error {
+	return s.postgresRepoCommand.Touch(ctx, id, ipAddress, time.Now())
+}
+
+// Delete marks the device as signed out
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	return s.postgresRepoCommand.Delete(ctx, id)
+}
@@ -14,3 +14,8 @@ type Address struct {
 	CreatedAt time.Time `json:"created_at"` // Timestamp when the address was created
 	UpdatedAt time.Time `json:"updated_at"` // Timestamp when the address was last updated
 }
+
+// OwnerID returns the ID of the user the address belongs to
+func (a Address) OwnerID() int64 {
+	return a.UserID
+}
@@ -8,13 +8,33 @@ type LoginBody struct {
 type RegisterBody struct {
 	FullName        string `json:"full_name" validate:"required"`
 	Email           string `json:"email" validate:"required,email"`
-	Password        string `json:"password" validate:"required"`
+	Password        string `json:"password" validate:"required,password"`
+	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=Password"`
+}
+
+type RefreshTokenBody struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+type ForgotPasswordBody struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordBody struct {
+	Token           string `json:"token" validate:"required"`
+	Password        string `json:"password" validate:"required,password"`
+	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=Password"`
+}
+
+type ChangePasswordBody struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	Password        string `json:"password" validate:"required,password"`
 	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=Password"`
 }
 
 type VerifyPhoneBody struct {
-	Phone       int64   `json:"phone"`
-	CountryCode int32   `json:"countryCode"`
+	Phone       int64   `json:"phone" validate:"required"`
+	CountryCode int32   `json:"countryCode" validate:"required"`
 	Type        OtpType `json:"type" validate:"required,oneof=sms whatsapp"`
 }
 
@@ -33,3 +53,11 @@ type VerifyOtpBody struct {
 	CountryCode int32  `json:"countryCode" validate:"required"`
 	Code        string `json:"otp" validate:"required"`
 }
+
+type MagicLinkBody struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type MagicLinkLoginBody struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -10,7 +10,7 @@ type Bundling struct {
 	ImageURL     string    `json:"image_url"`
 	BundlingName string    `json:"bundling_name"`
 	BundlingType string    `json:"bundling_type"`
-	Price        float64   `json:"price"`
+	Price        Money     `json:"price"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
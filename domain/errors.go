@@ -11,4 +11,26 @@ var (
 	ErrConflict = errors.New("Your Item already exist")
 	// ErrBadParamInput will throw if the given request-body or params is not valid
 	ErrBadParamInput = errors.New("given Param is not valid")
+	// ErrInvalidToken will throw if the given token is malformed, expired or revoked
+	ErrInvalidToken = errors.New("token is invalid or has been revoked")
+	// ErrInvalidOtp will throw if the given OTP code is wrong or has expired
+	ErrInvalidOtp = errors.New("otp code is invalid or has expired")
+	// ErrTooManyAttempts will throw if an action was attempted too often
+	ErrTooManyAttempts = errors.New("too many attempts, please try again later")
+	// ErrTokenReused will throw if an already rotated refresh token is presented again
+	ErrTokenReused = errors.New("refresh token has already been used")
+	// ErrInvalidTransition will throw if an order can not move to the requested status
+	ErrInvalidTransition = errors.New("order can not move to the requested status")
+	// ErrIdempotencyKeyReused will throw if an Idempotency-Key is sent again with a different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+	// ErrVoucherUnavailable will throw if a voucher is inactive or outside its validity window
+	ErrVoucherUnavailable = errors.New("voucher is not valid at this time")
+	// ErrVoucherLimitReached will throw if a voucher was redeemed as often as it may be
+	ErrVoucherLimitReached = errors.New("voucher usage limit has been reached")
+	// ErrVoucherMinSpend will throw if the eligible items do not reach the minimum spend of a voucher
+	ErrVoucherMinSpend = errors.New("minimum spend of the voucher is not reached")
+	// ErrVoucherNotEligible will throw if no item in the cart is eligible for a voucher
+	ErrVoucherNotEligible = errors.New("no item in the cart is eligible for the voucher")
+	// ErrInvoiceUnavailable will throw if an invoice is requested for an order that was never paid
+	ErrInvoiceUnavailable = errors.New("order has no invoice until it is paid")
 )
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord is a request sent with an Idempotency-Key and, once handled, its response
+type IdempotencyRecord struct {
+	Key         string    // Maps to key VARCHAR(300), the client key scoped to the user
+	Fingerprint string    // Maps to fingerprint VARCHAR(64), hash of the method, path and body
+	StatusCode  int       // Maps to status_code INT, 0 while the request is being handled
+	ContentType string    // Maps to content_type VARCHAR(255)
+	Body        []byte    // Maps to body BYTEA
+	ExpiresAt   time.Time // Maps to expires_at TIMESTAMP WITH TIME ZONE
+	LockedUntil time.Time // Maps to locked_until TIMESTAMP WITH TIME ZONE, the key is free again if not completed by then
+}
+
+// Completed reports whether the response of the request was recorded
+func (r IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
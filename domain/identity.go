@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// UserIdentity links an account of an external identity provider to a user
+type UserIdentity struct {
+	ID        int64     `json:"id"`         // Maps to id BIGSERIAL
+	UserID    int64     `json:"-"`          // Maps to user_id BIGINT
+	Provider  string    `json:"provider"`   // Maps to provider VARCHAR(50)
+	Subject   string    `json:"-"`          // Maps to subject VARCHAR(255)
+	Email     string    `json:"email"`      // Maps to email VARCHAR(255)
+	CreatedAt time.Time `json:"created_at"` // Maps to created_at TIMESTAMP WITH TIME ZONE
+}
+
+type IdentityTokenBody struct {
+	Provider string `json:"provider" validate:"required"`
+	IDToken  string `json:"idToken" validate:"required"`
+}
@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Default fee rates in percent, PPN is the Indonesian value added tax
+const (
+	DefaultTaxRate        = 11.0
+	DefaultServiceFeeRate = 0.0
+)
+
+// InvoiceTimeZone is Western Indonesian Time, invoice dates and the monthly numbering
+// follow it. Indonesia has no daylight saving time.
+var InvoiceTimeZone = time.FixedZone("WIB", 7*60*60)
+
+// FeeRates are the percentages charged on top of the items at checkout
+type FeeRates struct {
+	ServiceFee float64 // Percent of the items total after the discount
+	Tax        float64 // PPN, percent of the discounted items, the courier fee and the service fee
+}
+
+// Charges returns the service fee and the PPN of an order with the given items total
+// after the discount and courier fee
+func (r FeeRates) Charges(subtotal Money, courierFee Money) (serviceFee Money, tax Money) {
+	serviceFee = subtotal.Percent(r.ServiceFee)
+	tax = (subtotal + courierFee + serviceFee).Percent(r.Tax)
+	return serviceFee, tax
+}
+
+// Invoice is the bill of a paid order. It is a snapshot taken when it is issued and
+// never changes afterwards, later edits of the order, menu or address do not show up.
+type Invoice struct {
+	ID              int64           `json:"id"`
+	Number          string          `json:"number"`         // Sequential per month, e.g. 'INV/202610/00042'
+	TransactionID   int64           `json:"transaction_id"` // The invoiced order, 0 once the order was deleted
+	UserID          int64           `json:"-"`              // The billed customer
+	CustomerName    string          `json:"customer_name"`
+	CustomerEmail   string          `json:"customer_email"`
+	RecipientName   string          `json:"recipient_name"` // Name and phone of the delivery address
+	RecipientPhone  string          `json:"recipient_phone"`
+	DeliveryAddress string          `json:"delivery_address"`
+	Courier         string          `json:"courier"`
+	PaymentMethod   string          `json:"payment_method"` // 'CC', 'BT' or 'EW'
+	Lines           []InvoiceLine   `json:"lines"`
+	ItemsTotal      Money           `json:"items_total"`
+	Discount        Money           `json:"discount"`
+	CourierFee      Money           `json:"courier_fee"`
+	ServiceFee      Money           `json:"service_fee"`
+	ServiceFeeRate  float64         `json:"service_fee_rate"`
+	Tax             Money           `json:"tax"` // PPN
+	TaxRate         float64         `json:"tax_rate"`
+	Total           Money           `json:"total"`   // The amount paid
+	Charges         []InvoiceCharge `json:"charges"` // Breakdown of the total with Indonesian labels, not stored
+	OrderedAt       time.Time       `json:"ordered_at"`
+	IssuedAt        time.Time       `json:"issued_at"`
+}
+
+// OwnerID returns the billed customer
+func (i Invoice) OwnerID() int64 {
+	return i.UserID
+}
+
+// InvoiceLine is an item of an invoice
+type InvoiceLine struct {
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitPrice   Money  `json:"unit_price"`
+	Amount      Money  `json:"amount"`
+}
+
+// InvoiceCharge is a line of the breakdown below the items of an invoice
+type InvoiceCharge struct {
+	Label  string `json:"label"`
+	Amount Money  `json:"amount"` // Negative for discounts
+}
+
+// InvoiceNumber formats the number of the sequence-th invoice issued in the month of issuedAt
+func InvoiceNumber(issuedAt time.Time, sequence int) string {
+	return fmt.Sprintf("INV/%s/%05d", InvoicePeriod(issuedAt), sequence)
+}
+
+// InvoicePeriod returns the month invoices are numbered in, 'YYYYMM'
+func InvoicePeriod(issuedAt time.Time) string {
+	return issuedAt.In(InvoiceTimeZone).Format("200601")
+}
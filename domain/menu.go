@@ -9,7 +9,7 @@ type Menu struct {
 	ID          int64           `json:"id"`
 	Title       string          `json:"title"`
 	Description string          `json:"description"` // Allows NULL values
-	Price       float64         `json:"price"`       // Change from int64 to float64
+	Price       Money           `json:"price"`       // Price in whole rupiah
 	ImageURL    string          `json:"image_url"`   // Allows NULL values
 	Nutrition   json.RawMessage `json:"nutrition"`   // Decoded JSON
 	Features    json.RawMessage `json:"features"`    // Decoded JSON
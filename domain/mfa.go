@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// UserMfa represents the TOTP second factor of a user
+type UserMfa struct {
+	UserID       int64      `json:"user_id"`    // Maps to user_id BIGINT
+	Secret       string     `json:"-"`          // Maps to secret TEXT, encrypted
+	EnabledAt    *time.Time `json:"enabled_at"` // Maps to enabled_at TIMESTAMP WITH TIME ZONE, nullable
+	LastUsedStep int64      `json:"-"`          // Maps to last_used_step BIGINT
+	CreatedAt    time.Time  `json:"created_at"` // Maps to created_at TIMESTAMP WITH TIME ZONE
+	UpdatedAt    time.Time  `json:"updated_at"` // Maps to updated_at TIMESTAMP WITH TIME ZONE
+}
+
+// MfaEnrollment is returned when enrolling, the secret is shown to the user only once
+type MfaEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type MfaCodeBody struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableMfaBody struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type LoginMfaBody struct {
+	MfaToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"` // TOTP code or recovery code
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in whole rupiah. Prices are kept as integers so totals, discounts
+// and fees add up exactly, the rupiah has no subunit in use.
+type Money int64
+
+// Percent returns p percent of the amount rounded half up to whole rupiah. p may have
+// up to two decimals, e.g. 12.5 for 12.5%.
+func (m Money) Percent(p float64) Money {
+	basisPoints := int64(math.Round(p * 100))
+	product := int64(m) * basisPoints
+	if product < 0 {
+		return -Money((-product + 5000) / 10000)
+	}
+	return Money((product + 5000) / 10000)
+}
+
+// Min returns the smaller of both amounts
+func (m Money) Min(other Money) Money {
+	if other < m {
+		return other
+	}
+	return m
+}
+
+// String formats the amount the Indonesian way, e.g. "Rp 1.250.000"
+func (m Money) String() string {
+	digits := strconv.FormatInt(int64(m), 10)
+	sign := ""
+	if m < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(digit)
+	}
+
+	return sign + "Rp " + b.String()
+}
+
+// Scan reads NUMERIC, DECIMAL, integer and floating point columns. Fractions of a
+// rupiah are rounded half up.
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = Money(v)
+	case float64:
+		*m = Money(math.Round(v))
+	case []byte:
+		return m.parse(string(v))
+	case string:
+		return m.parse(v)
+	default:
+		return fmt.Errorf("can not scan %T into Money", src)
+	}
+	return nil
+}
+
+// Value stores the amount as an integer
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+// MarshalJSON encodes the amount as a JSON integer
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(int64(m), 10)), nil
+}
+
+// UnmarshalJSON accepts a JSON integer, amounts with a fraction are rejected
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("money must be a whole rupiah amount, got %s", data)
+	}
+	*m = Money(value)
+	return nil
+}
+
+// parse reads a decimal such as "35000.00" without going through floating point
+func (m *Money) parse(s string) error {
+	text := strings.TrimSpace(s)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimLeft(text, "+-")
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" {
+		whole = "0"
+	}
+
+	value, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid money amount %q", s)
+	}
+	for _, digit := range fraction {
+		if digit < '0' || digit > '9' {
+			return fmt.Errorf("invalid money amount %q", s)
+		}
+	}
+	if fraction != "" && fraction[0] >= '5' {
+		value++
+	}
+	if negative {
+		value = -value
+	}
+
+	*m = Money(value)
+	return nil
+}
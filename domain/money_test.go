@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMoneyPercent(t *testing.T) {
+	tests := []struct {
+		amount  Money
+		percent float64
+		want    Money
+	}{
+		{100000, 10, 10000},
+		{35000, 12.5, 4375},
+		{10001, 12.5, 1250},   // 1250.125
+		{10004, 12.5, 1251},   // 1250.5, half up
+		{33333, 15, 5000},     // 4999.95
+		{99999, 33.33, 33330}, // 33329.6667
+		{1, 50, 1},            // 0.5, half up
+		{1, 49.99, 0},
+		{-10004, 12.5, -1251}, // refunds round away from zero like charges
+		{-1, 50, -1},
+		{50000, 0, 0},
+		{50000, 100, 50000},
+		{0, 25, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.Percent(tt.percent); got != tt.want {
+			t.Fatalf("%d at %v%%: got %d, want %d", tt.amount, tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		amount Money
+		want   string
+	}{
+		{0, "Rp 0"},
+		{500, "Rp 500"},
+		{1000, "Rp 1.000"},
+		{35000, "Rp 35.000"},
+		{1250000, "Rp 1.250.000"},
+		{-1250000, "-Rp 1.250.000"},
+		{-999, "-Rp 999"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Fatalf("%d: got %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    Money
+		wantErr bool
+	}{
+		{"decimal", []byte("35000.00"), 35000, false},
+		{"decimal string", "35000.00", 35000, false},
+		{"half a rupiah rounds up", []byte("35000.50"), 35001, false},
+		{"below half rounds down", []byte("35000.49"), 35000, false},
+		{"negative", []byte("-35000.50"), -35001, false},
+		{"no fraction", []byte("12500"), 12500, false},
+		{"only a fraction", []byte(".75"), 1, false},
+		{"integer", int64(42000), 42000, false},
+		{"float", float64(42000.5), 42001, false},
+		{"null", nil, 0, false},
+		{"not a number", []byte("abc"), 0, true},
+		{"letters in the fraction", []byte("100.5x"), 0, true},
+		{"unsupported type", true, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Money(-7)
+			err := m.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && m != tt.want {
+				t.Fatalf("got %d, want %d", m, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var body struct {
+		Amount Money `json:"amount"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount":15000}`), &body); err != nil {
+		t.Fatalf("integer: got error %v, want nil", err)
+	}
+	if body.Amount != 15000 {
+		t.Fatalf("integer: got %d, want 15000", body.Amount)
+	}
+
+	for _, data := range []string{`{"amount":15000.5}`, `{"amount":"15000"}`, `{"amount":1e4}`} {
+		if err := json.Unmarshal([]byte(data), &body); err == nil {
+			t.Fatalf("%s: got nil, want an error", data)
+		}
+	}
+
+	out, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"amount":15000}` {
+		t.Fatalf("got %s, want {\"amount\":15000}", out)
+	}
+}
@@ -0,0 +1,135 @@
+package domain
+
+import "time"
+
+// OrderStatus is the lifecycle state of an order, stored in transaction.status
+type OrderStatus string
+
+const (
+	OrderStatusPendingPayment OrderStatus = "pending_payment" // Placed, waiting for the payment
+	OrderStatusPaid           OrderStatus = "paid"            // Paid, waiting for the kitchen
+	OrderStatusPreparing      OrderStatus = "preparing"       // The kitchen is preparing the food
+	OrderStatusOutForDelivery OrderStatus = "out_for_delivery"
+	OrderStatusDelivered      OrderStatus = "delivered"
+	OrderStatusCancelled      OrderStatus = "cancelled" // Cancelled before it was paid
+	OrderStatusRefunded       OrderStatus = "refunded"  // Cancelled after it was paid, the money was returned
+	OrderStatusFailed         OrderStatus = "failed"    // The payment failed or expired
+)
+
+// orderTransitions lists the statuses an order may move to from each status.
+// A failed order can still become paid when the provider captures the money late,
+// unless its voucher reached its usage limits in the meantime.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPendingPayment: {OrderStatusPaid, OrderStatusCancelled, OrderStatusFailed},
+	OrderStatusPaid:           {OrderStatusPreparing, OrderStatusRefunded},
+	OrderStatusPreparing:      {OrderStatusOutForDelivery, OrderStatusRefunded},
+	OrderStatusOutForDelivery: {OrderStatusDelivered, OrderStatusRefunded},
+	OrderStatusDelivered:      {OrderStatusRefunded},
+	OrderStatusFailed:         {OrderStatusPaid},
+}
+
+// Valid reports whether the status is a known order status
+func (s OrderStatus) Valid() bool {
+	switch s {
+	case OrderStatusPendingPayment, OrderStatusPaid, OrderStatusPreparing, OrderStatusOutForDelivery,
+		OrderStatusDelivered, OrderStatusCancelled, OrderStatusRefunded, OrderStatusFailed:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo reports whether an order may move from s to next
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Invoiceable reports whether an invoice may be issued for an order in the status,
+// which is the case from the moment it was paid until it is delivered
+func (s OrderStatus) Invoiceable() bool {
+	switch s {
+	case OrderStatusPaid, OrderStatusPreparing, OrderStatusOutForDelivery, OrderStatusDelivered:
+		return true
+	}
+	return false
+}
+
+// OrderStatusesBefore returns every status an order may move to next from
+func OrderStatusesBefore(next OrderStatus) []OrderStatus {
+	statuses := make([]OrderStatus, 0)
+	for from, targets := range orderTransitions {
+		for _, target := range targets {
+			if target == next {
+				statuses = append(statuses, from)
+			}
+		}
+	}
+	return statuses
+}
+
+// OrderStatusPermission returns the permission staff need to move an order to the
+// status. Couriers hand orders over, the kitchen handles everything before that.
+func OrderStatusPermission(status OrderStatus) string {
+	switch status {
+	case OrderStatusOutForDelivery, OrderStatusDelivered:
+		return PermissionDeliveriesManage
+	default:
+		return PermissionOrdersManage
+	}
+}
+
+// Actors changing the status of an order
+const (
+	OrderActorCustomer        = "customer"
+	OrderActorStaff           = "staff"
+	OrderActorPaymentProvider = "payment_provider"
+	OrderActorSystem          = "system"
+)
+
+// OrderActor is who changed the status of an order, ID is 0 for non-user actors
+type OrderActor struct {
+	Type string
+	ID   int64
+}
+
+// OrderStatusHistory is one entry of the timeline of an order
+type OrderStatusHistory struct {
+	ID            int64        `json:"id"`                    // Maps to id BIGSERIAL
+	TransactionID int64        `json:"transaction_id"`        // Maps to transaction_id BIGINT
+	FromStatus    *OrderStatus `json:"from_status,omitempty"` // Maps to from_status VARCHAR(30), NULL for the first entry
+	ToStatus      OrderStatus  `json:"to_status"`             // Maps to to_status VARCHAR(30)
+	ActorType     string       `json:"actor_type"`            // Maps to actor_type VARCHAR(30)
+	ActorID       *int64       `json:"actor_id,omitempty"`    // Maps to actor_id BIGINT, nullable
+	Note          string       `json:"note,omitempty"`        // Maps to note TEXT
+	CreatedAt     time.Time    `json:"created_at"`            // Maps to created_at TIMESTAMP WITH TIME ZONE
+}
+
+type CancelOrderBody struct {
+	Note string `json:"note" validate:"max=500"`
+}
+
+type UpdateOrderStatusBody struct {
+	Status OrderStatus `json:"status" validate:"required"`
+	Note   string      `json:"note" validate:"max=500"`
+}
+
+// OrderFilter narrows the orders listed for a user, zero values do not filter
+type OrderFilter struct {
+	Statuses []OrderStatus
+	From     time.Time // Orders placed at or after From
+	To       time.Time // Orders placed before To
+}
+
+// OrderDetail is an order together with everything it refers to
+type OrderDetail struct {
+	Transaction
+	Items    []CartItem           `json:"items"`    // Items as priced when the order was placed
+	Address  Address              `json:"address"`  // The delivery address
+	Courier  Courier              `json:"courier"`  // The courier delivering the order
+	Payment  Payment              `json:"payment"`  // The payment of the order
+	Timeline []OrderStatusHistory `json:"timeline"` // Status changes, oldest first
+}
@@ -6,11 +6,13 @@ import (
 
 type Otp struct {
 	ID        int64     `json:"id"`
-	Code      string    `json:"code"`
+	Code      string    `json:"-"` // bcrypt hash of the code
 	Retry     int16     `json:"retry"`
 	Type      string    `json:"type"`
+	Phone     string    `json:"phone"`
 	UserId    int64     `json:"user_id"`
 	DeviceId  string    `json:"device_id"`
+	ExpiresAt time.Time `json:"expires_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// PasswordReset represents a single-use password reset token, stored hashed
+type PasswordReset struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
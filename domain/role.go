@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// Built-in roles seeded by the migrations
+const (
+	RoleAdmin   = "admin"
+	RoleKitchen = "kitchen"
+	RoleCourier = "courier"
+)
+
+// Built-in permissions seeded by the migrations
+const (
+	PermissionUsersManage      = "users:manage"
+	PermissionCatalogManage    = "catalog:manage"
+	PermissionOrdersManage     = "orders:manage"
+	PermissionDeliveriesManage = "deliveries:manage"
+	PermissionPromotionsManage = "promotions:manage"
+)
+
+// Role represents a role entity in the system
+type Role struct {
+	ID          int64     `json:"id"`          // Maps to id BIGINT
+	Name        string    `json:"name"`        // Maps to name VARCHAR(50)
+	Description string    `json:"description"` // Maps to description VARCHAR(255)
+	CreatedAt   time.Time `json:"created_at"`  // Maps to created_at TIMESTAMP WITH TIME ZONE
+}
+
+// Access holds the roles of a user and the permissions they grant
+type Access struct {
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+type AssignRoleBody struct {
+	Role string `json:"role" validate:"required"`
+}
@@ -15,7 +15,10 @@ type JWTCustomClaims struct {
 type Token struct {
 	ID           int64      `json:"id" db:"id"`                           // Primary key
 	UserID       int64      `json:"user_id" db:"user_id"`                 // Foreign key to the user table
+	DeviceID     int64      `json:"device_id" db:"device_id"`             // Foreign key to the devices table, 0 when unknown
 	RefreshToken string     `json:"refresh_token" db:"refresh_token"`     // Refresh token string
+	FamilyID     string     `json:"family_id" db:"family_id"`             // Shared by all tokens rotated from the same login
+	ExpiresAt    time.Time  `json:"expires_at" db:"expires_at"`           // Expiry of the refresh token
 	CreatedAt    time.Time  `json:"created_at" db:"created_at"`           // Timestamp of creation
 	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`           // Timestamp of last update
 	DeletedAt    *time.Time `json:"deleted_at,omitempty" db:"deleted_at"` // Nullable timestamp for soft deletion
@@ -2,13 +2,111 @@ package domain
 
 import "time"
 
+// Cart statuses
+const (
+	CartStatusActive = "active" // The cart is being filled
+	CartStatusUsed   = "used"   // The cart was checked out and can no longer change
+)
+
+// Cart represents the shopping cart of a user
+type Cart struct {
+	ID         int64      `json:"id"`          // Unique identifier for the cart
+	UserID     int64      `json:"-"`           // The user owning the cart
+	TotalPrice Money      `json:"total_price"` // Sum of the item subtotals, always calculated server-side
+	Status     string     `json:"status"`      // Status of the cart ('active', 'used')
+	Items      []CartItem `json:"items"`       // Items in the cart
+	CreatedAt  time.Time  `json:"created_at"`  // Timestamp of when the cart was created
+	UpdatedAt  time.Time  `json:"updated_at"`  // Timestamp of the last update to the cart
+}
+
 // CartItem represents an item in the shopping cart
 type CartItem struct {
-	ID        int64     `json:"id"`         // Unique identifier for the cart item
-	Name      string    `json:"name"`       // Name of the item
-	Quantity  int       `json:"quantity"`   // Quantity of the item in the cart
-	Price     float64   `json:"price"`      // Price per unit of the item
-	Subtotal  float64   `json:"subtotal"`   // Calculated as Quantity * Price
-	CreatedAt time.Time `json:"created_at"` // Timestamp of when the item was added to the cart
-	UpdatedAt time.Time `json:"updated_at"` // Timestamp of the last update to the item
+	ID         int64     `json:"id"`                    // Unique identifier for the cart item
+	CartID     int64     `json:"-"`                     // The cart the item belongs to
+	MenuID     *int64    `json:"menu_id,omitempty"`     // Set when the item is a menu
+	BundlingID *int64    `json:"bundling_id,omitempty"` // Set when the item is a bundling
+	ImageURL   string    `json:"image_url"`             // Image of the menu or bundling
+	Name       string    `json:"name"`                  // Name of the item
+	Quantity   int       `json:"quantity"`              // Quantity of the item in the cart
+	Price      Money     `json:"price"`                 // Price per unit of the item
+	Subtotal   Money     `json:"subtotal"`              // Calculated as Quantity * Price
+	CreatedAt  time.Time `json:"created_at"`            // Timestamp of when the item was added to the cart
+	UpdatedAt  time.Time `json:"updated_at"`            // Timestamp of the last update to the item
+}
+
+// AddCartItemBody adds a menu or a bundling to the cart, exactly one of the IDs is set
+type AddCartItemBody struct {
+	MenuID     *int64 `json:"menuId"`
+	BundlingID *int64 `json:"bundlingId"`
+	Quantity   int    `json:"quantity" validate:"required,min=1,max=99"`
+}
+
+type UpdateCartItemBody struct {
+	Quantity int `json:"quantity" validate:"required,min=1,max=99"`
+}
+
+// Payment statuses
+const (
+	PaymentStatusUnpaid  = "unpaid"  // Waiting for the customer to pay
+	PaymentStatusPaid    = "paid"    // The provider captured the money
+	PaymentStatusFailed  = "failed"  // The provider declined the payment
+	PaymentStatusExpired = "expired" // The payment session ended unpaid
+)
+
+// Payment represents the payment of a transaction
+type Payment struct {
+	ID             int64     `json:"id"`              // Unique identifier for the payment
+	ReferenceID    string    `json:"reference_id"`    // Payment method, e.g. 'CC', 'BT', 'EW'
+	SessionID      string    `json:"session_id"`      // Session of the payment provider
+	UserID         int64     `json:"-"`               // The user paying
+	Amount         Money     `json:"amount"`          // Amount to pay
+	Status         string    `json:"status"`          // Status of the payment ('unpaid', 'paid', ...)
+	URL            string    `json:"url"`             // Where the user completes the payment
+	RefundRequired bool      `json:"refund_required"` // Captured for a cancelled order, staff have to refund it
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// OwnerID returns the user paying
+func (p Payment) OwnerID() int64 {
+	return p.UserID
+}
+
+// Transaction represents an order placed from a cart
+type Transaction struct {
+	ID             int64       `json:"id"`                   // Unique identifier for the transaction
+	UserID         int64       `json:"-"`                    // The user placing the order
+	CartID         int64       `json:"cart_id"`              // The checked out cart
+	PaymentID      int64       `json:"payment_id"`           // The payment of the order
+	CourierID      int64       `json:"courier_id"`           // The courier delivering the order
+	AddressID      int64       `json:"address_id"`           // The delivery address
+	VoucherID      *int64      `json:"voucher_id,omitempty"` // The voucher redeemed on the order
+	Discount       Money       `json:"discount"`             // Discount of the voucher
+	CourierFee     Money       `json:"courier_fee"`          // Price of the courier at checkout
+	ServiceFee     Money       `json:"service_fee"`          // Service fee charged at checkout
+	ServiceFeeRate float64     `json:"service_fee_rate"`     // Percent the service fee was charged at
+	Tax            Money       `json:"tax"`                  // PPN charged at checkout
+	TaxRate        float64     `json:"tax_rate"`             // Percent the PPN was charged at
+	Amount         Money       `json:"amount"`               // Items total minus the discount plus the courier fee, service fee and PPN
+	Status         OrderStatus `json:"status"`               // Lifecycle state of the order
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+// OwnerID returns the user who placed the order
+func (t Transaction) OwnerID() int64 {
+	return t.UserID
+}
+
+// Checkout is the result of checking out a cart
+type Checkout struct {
+	Transaction Transaction `json:"transaction"`
+	Payment     Payment     `json:"payment"`
+}
+
+type CheckoutBody struct {
+	AddressID     int64  `json:"addressId" validate:"required"`
+	CourierID     int64  `json:"courierId" validate:"required"`
+	PaymentMethod string `json:"paymentMethod" validate:"required,oneof=CC BT EW"`
+	VoucherCode   string `json:"voucherCode" validate:"max=50"`
 }
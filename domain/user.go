@@ -8,9 +8,77 @@ type User struct {
 	ID              int64      `json:"id"`
 	FullName        string     `json:"full_name"`
 	Email           string     `json:"email"`
-	Password        string     `json:"password"`
+	Password        string     `json:"-"` // Password hash, never serialized
 	CreatedAt       time.Time  `json:"created_at"`
 	UpdatedAt       time.Time  `json:"updated_at"`
 	DeletedAt       *time.Time `json:"deleted_at,omitempty" db:"deleted_at"` // Nullable timestamp for soft deletion
 	EmailVerifiedAt *time.Time `json:"email_verified_at"`                    // Change to pointer
+	Phone           string     `json:"phone"`                                // E.164 formatted, empty when not set
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at"`
+	AvatarURL       string     `json:"avatar_url"` // Empty when not set
+
+	EmailVerificationSentAt *time.Time `json:"-"` // Last time a verification email was sent
+	PendingEmail            string     `json:"-"` // New email address waiting for verification
+	DeletionScheduledAt     *time.Time `json:"-"` // When a requested account deletion becomes final
+}
+
+// UserResponse is the representation of a user returned by the API, it never carries credentials
+type UserResponse struct {
+	ID              int64      `json:"id"`
+	FullName        string     `json:"full_name"`
+	Email           string     `json:"email"`
+	PendingEmail    string     `json:"pending_email,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Phone           string     `json:"phone"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at"`
+	AvatarURL       string     `json:"avatar_url"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+// Response converts the user to its API representation
+func (u User) Response() UserResponse {
+	return UserResponse{
+		ID:              u.ID,
+		FullName:        u.FullName,
+		Email:           u.Email,
+		PendingEmail:    u.PendingEmail,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		Phone:           u.Phone,
+		PhoneVerifiedAt: u.PhoneVerifiedAt,
+		AvatarURL:       u.AvatarURL,
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
+
+		DeletionScheduledAt: u.DeletionScheduledAt,
+	}
+}
+
+// UpdateProfileBody holds the profile fields to change, omitted fields are left untouched
+type UpdateProfileBody struct {
+	FullName    *string `json:"full_name" validate:"omitempty,min=1,max=255"`
+	Phone       *int64  `json:"phone"`
+	CountryCode *int32  `json:"countryCode"`
+	AvatarURL   *string `json:"avatar_url" validate:"omitempty,url,max=512"`
+}
+
+type ChangeEmailBody struct {
+	Email string `json:"email" validate:"required,email"`
+	ReauthBody
+}
+
+type DeleteAccountBody struct {
+	ReauthBody
+}
+
+// ReauthBody proves the signed in user is present before a sensitive change, with
+// the password or, for accounts without one, a magic link token or an ID token of
+// a linked identity provider
+type ReauthBody struct {
+	Password       string `json:"password"`
+	MagicLinkToken string `json:"magicLinkToken"`
+	Provider       string `json:"provider"`
+	IDToken        string `json:"idToken"`
 }
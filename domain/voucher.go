@@ -0,0 +1,96 @@
+package domain
+
+import "time"
+
+// Voucher discount types
+const (
+	VoucherDiscountPercentage = "percentage" // DiscountPercent percent of the eligible items
+	VoucherDiscountFixed      = "fixed"      // DiscountAmount rupiah off the eligible items
+)
+
+// Voucher represents a promo code
+type Voucher struct {
+	ID                int64      `json:"id"`                             // Maps to id BIGSERIAL
+	Code              string     `json:"code"`                           // Maps to code VARCHAR(50), upper case
+	Description       string     `json:"description"`                    // Maps to description TEXT
+	DiscountType      string     `json:"discount_type"`                  // Maps to discount_type VARCHAR(20)
+	DiscountAmount    Money      `json:"discount_amount"`                // Maps to discount_amount DECIMAL(10, 2), 0 unless fixed
+	DiscountPercent   float64    `json:"discount_percent"`               // Maps to discount_percent DECIMAL(5, 2), 0 unless percentage
+	MinSpend          Money      `json:"min_spend"`                      // Maps to min_spend DECIMAL(10, 2)
+	MaxDiscount       *Money     `json:"max_discount,omitempty"`         // Maps to max_discount DECIMAL(10, 2), nullable
+	StartsAt          time.Time  `json:"starts_at"`                      // Maps to starts_at TIMESTAMP WITH TIME ZONE
+	EndsAt            *time.Time `json:"ends_at,omitempty"`              // Maps to ends_at TIMESTAMP WITH TIME ZONE, nullable
+	UsageLimit        *int       `json:"usage_limit,omitempty"`          // Maps to usage_limit INT, nullable
+	UsageLimitPerUser *int       `json:"usage_limit_per_user,omitempty"` // Maps to usage_limit_per_user INT, nullable
+	Active            bool       `json:"active"`                         // Maps to active BOOLEAN
+	CategoryIDs       []int64    `json:"category_ids"`                   // Eligible categories, from voucher_eligibility
+	MenuIDs           []int64    `json:"menu_ids"`                       // Eligible menus, from voucher_eligibility
+	BundlingTypes     []string   `json:"bundling_types"`                 // Eligible bundling types, from voucher_eligibility
+	CreatedAt         time.Time  `json:"created_at"`                     // Maps to created_at TIMESTAMP WITH TIME ZONE
+	UpdatedAt         time.Time  `json:"updated_at"`                     // Maps to updated_at TIMESTAMP WITH TIME ZONE
+}
+
+// Restricted reports whether the voucher only applies to some items
+func (v Voucher) Restricted() bool {
+	return len(v.CategoryIDs) > 0 || len(v.MenuIDs) > 0 || len(v.BundlingTypes) > 0
+}
+
+// ValidAt reports whether the voucher can be redeemed at the given time
+func (v Voucher) ValidAt(now time.Time) bool {
+	return v.Active && !now.Before(v.StartsAt) && (v.EndsAt == nil || now.Before(*v.EndsAt))
+}
+
+// Discount returns the discount on the eligible subtotal, never more than the subtotal itself
+func (v Voucher) Discount(eligibleSubtotal Money) Money {
+	discount := v.DiscountAmount
+	if v.DiscountType == VoucherDiscountPercentage {
+		discount = eligibleSubtotal.Percent(v.DiscountPercent)
+		if v.MaxDiscount != nil {
+			discount = discount.Min(*v.MaxDiscount)
+		}
+	}
+
+	return discount.Min(eligibleSubtotal)
+}
+
+// VoucherRedemption is a voucher used on an order
+type VoucherRedemption struct {
+	ID            int64     `json:"id"`             // Maps to id BIGSERIAL
+	VoucherID     int64     `json:"voucher_id"`     // Maps to voucher_id BIGINT
+	UserID        int64     `json:"user_id"`        // Maps to user_id BIGINT
+	TransactionID int64     `json:"transaction_id"` // Maps to transaction_id BIGINT
+	Discount      Money     `json:"discount"`       // Maps to discount DECIMAL(10, 2)
+	CreatedAt     time.Time `json:"created_at"`     // Maps to created_at TIMESTAMP WITH TIME ZONE
+}
+
+// VoucherQuote is the discount a voucher gives on a cart
+type VoucherQuote struct {
+	VoucherID        int64  `json:"-"`
+	Code             string `json:"code"`
+	Description      string `json:"description"`
+	ItemsTotal       Money  `json:"items_total"`       // Total of every item in the cart
+	EligibleSubtotal Money  `json:"eligible_subtotal"` // Total of the items the voucher applies to
+	Discount         Money  `json:"discount"`
+	Total            Money  `json:"total"` // Items total minus the discount, before delivery
+}
+
+type ApplyVoucherBody struct {
+	Code string `json:"code" validate:"required,max=50"`
+}
+
+type CreateVoucherBody struct {
+	Code              string     `json:"code" validate:"required,alphanum,max=50"`
+	Description       string     `json:"description" validate:"max=1000"`
+	DiscountType      string     `json:"discountType" validate:"required,oneof=percentage fixed"`
+	DiscountAmount    Money      `json:"discountAmount" validate:"min=0"`          // Required for fixed vouchers
+	DiscountPercent   float64    `json:"discountPercent" validate:"min=0,max=100"` // Required for percentage vouchers
+	MinSpend          Money      `json:"minSpend" validate:"min=0"`
+	MaxDiscount       *Money     `json:"maxDiscount" validate:"omitempty,gt=0"`
+	StartsAt          *time.Time `json:"startsAt"`
+	EndsAt            *time.Time `json:"endsAt"`
+	UsageLimit        *int       `json:"usageLimit" validate:"omitempty,min=1"`
+	UsageLimitPerUser *int       `json:"usageLimitPerUser" validate:"omitempty,min=1"`
+	CategoryIDs       []int64    `json:"categoryIds"`
+	MenuIDs           []int64    `json:"menuIds"`
+	BundlingTypes     []string   `json:"bundlingTypes" validate:"dive,oneof=weekly monthly"`
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVoucherDiscount(t *testing.T) {
+	maxDiscount := Money(20000)
+
+	tests := []struct {
+		name     string
+		voucher  Voucher
+		subtotal Money
+		want     Money
+	}{
+		{"fixed", Voucher{DiscountType: VoucherDiscountFixed, DiscountAmount: 15000}, 100000, 15000},
+		{"fixed over the subtotal", Voucher{DiscountType: VoucherDiscountFixed, DiscountAmount: 15000}, 10000, 10000},
+		{"percentage", Voucher{DiscountType: VoucherDiscountPercentage, DiscountPercent: 10}, 100000, 10000},
+		{"percentage rounded half up", Voucher{DiscountType: VoucherDiscountPercentage, DiscountPercent: 12.5}, 10004, 1251},
+		{"percentage under the cap", Voucher{DiscountType: VoucherDiscountPercentage, DiscountPercent: 10, MaxDiscount: &maxDiscount}, 150000, 15000},
+		{"percentage capped", Voucher{DiscountType: VoucherDiscountPercentage, DiscountPercent: 50, MaxDiscount: &maxDiscount}, 100000, 20000},
+		{"full percentage", Voucher{DiscountType: VoucherDiscountPercentage, DiscountPercent: 100}, 35000, 35000},
+		{"nothing eligible", Voucher{DiscountType: VoucherDiscountFixed, DiscountAmount: 15000}, 0, 0},
+		{"fixed amount ignored on a percentage voucher", Voucher{DiscountType: VoucherDiscountPercentage, DiscountAmount: 5000, DiscountPercent: 10}, 10000, 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.voucher.Discount(tt.subtotal); got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVoucherValidAt(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ends := now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		voucher Voucher
+		want    bool
+	}{
+		{"running", Voucher{Active: true, StartsAt: now.Add(-time.Hour), EndsAt: &ends}, true},
+		{"no end", Voucher{Active: true, StartsAt: now}, true},
+		{"inactive", Voucher{StartsAt: now.Add(-time.Hour)}, false},
+		{"not started", Voucher{Active: true, StartsAt: now.Add(time.Minute)}, false},
+		{"ended", Voucher{Active: true, StartsAt: now.Add(-2 * time.Hour), EndsAt: &now}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.voucher.ValidAt(now); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package idempotency
+
+import (
+	"context"
+	"os"
+	"santapan/domain"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultTTL is how long a key is remembered when IDEMPOTENCY_TTL is not set
+	DefaultTTL = 24 * time.Hour
+	// Lease is how long a request may take before its key is considered abandoned, a
+	// failed Complete or a crash only blocks retries for this long
+	Lease = time.Minute
+)
+
+// Store keeps Idempotency-Key records until they expire
+type Store interface {
+	// Reserve stores the record unless a live record exists for its key, which is returned
+	// instead. A record that was not completed before its LockedUntil is not live.
+	Reserve(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, record domain.IdempotencyRecord) error
+	Delete(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type Service struct {
+	store Store
+	ttl   time.Duration
+}
+
+// NewService will create a new idempotency service remembering keys for ttl
+func NewService(store Store, ttl time.Duration) *Service {
+	return &Service{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// TTLFromEnv reads IDEMPOTENCY_TTL as a duration such as "24h", DefaultTTL otherwise
+func TTLFromEnv() time.Duration {
+	value := os.Getenv("IDEMPOTENCY_TTL")
+	if value == "" {
+		return DefaultTTL
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 {
+		logrus.Warnf("Invalid IDEMPOTENCY_TTL %q, using %s", value, DefaultTTL)
+		return DefaultTTL
+	}
+	return ttl
+}
+
+// Begin claims the key for the request identified by fingerprint. A zero record
+// means the request is new and must be handled, a completed record holds the
+// response to replay. Returns domain.ErrIdempotencyKeyReused when the key belongs
+// to a different request and domain.ErrConflict while the first request is still
+// being handled, which is at most Lease.
+func (s *Service) Begin(ctx context.Context, key string, fingerprint string) (domain.IdempotencyRecord, error) {
+	now := time.Now()
+	existing, reserved, err := s.store.Reserve(ctx, domain.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(s.ttl),
+		LockedUntil: now.Add(Lease),
+	})
+	if err != nil {
+		return domain.IdempotencyRecord{}, err
+	}
+	if reserved {
+		return domain.IdempotencyRecord{}, nil
+	}
+
+	if existing.Fingerprint != fingerprint {
+		return domain.IdempotencyRecord{}, domain.ErrIdempotencyKeyReused
+	}
+	if !existing.Completed() {
+		return domain.IdempotencyRecord{}, domain.ErrConflict
+	}
+
+	return existing, nil
+}
+
+// Complete records the response of a request claimed with Begin, it is replayed for
+// the TTL from now on
+func (s *Service) Complete(ctx context.Context, key string, fingerprint string, statusCode int, contentType string, body []byte) error {
+	return s.store.Complete(ctx, domain.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		StatusCode:  statusCode,
+		ContentType: contentType,
+		Body:        body,
+		ExpiresAt:   time.Now().Add(s.ttl),
+	})
+}
+
+// Release frees a key whose request failed so the client can retry it
+func (s *Service) Release(ctx context.Context, key string) error {
+	return s.store.Delete(ctx, key)
+}
+
+// RunCleaner removes expired keys every interval until the context is cancelled
+func (s *Service) RunCleaner(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if removed, err := s.store.DeleteExpired(ctx, time.Now()); err != nil {
+			logrus.Errorf("Failed to remove expired idempotency keys: %v", err)
+		} else if removed > 0 {
+			logrus.Infof("Removed %d expired idempotency keys", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
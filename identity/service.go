@@ -0,0 +1,230 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"santapan/domain"
+	"santapan/pkg/oidc"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type PostgresRepositoryQueries interface {
+	GetByProviderSubject(ctx context.Context, provider string, subject string) (domain.UserIdentity, error)
+	FetchByUserID(ctx context.Context, userID int64) ([]domain.UserIdentity, error)
+}
+
+type PostgresRepositoryCommand interface {
+	Store(ctx context.Context, identity *domain.UserIdentity) error
+	Delete(ctx context.Context, userID int64, provider string) error
+}
+
+// Verifier checks ID tokens of the configured identity providers
+type Verifier interface {
+	Verify(provider string, idToken string) (oidc.Claims, error)
+}
+
+// UserService looks up and creates the users identities belong to
+type UserService interface {
+	GetByID(ctx context.Context, id int64) (domain.User, error)
+	GetByEmail(ctx context.Context, email string) (domain.User, error)
+	Store(ctx context.Context, user *domain.User) error
+	UpdateEmailVerifiedAt(ctx context.Context, id int64, verifiedAt time.Time) error
+}
+
+type Service struct {
+	postgresRepoQuery   PostgresRepositoryQueries
+	postgresRepoCommand PostgresRepositoryCommand
+	verifier            Verifier
+	userService         UserService
+}
+
+// NewService will create a new identity service object
+func NewService(pq PostgresRepositoryQueries, pc PostgresRepositoryCommand, verifier Verifier, userService UserService) *Service {
+	return &Service{
+		postgresRepoQuery:   pq,
+		postgresRepoCommand: pc,
+		verifier:            verifier,
+		userService:         userService,
+	}
+}
+
+// SignIn returns the user the provider account belongs to. An unknown account is
+// linked to the user with the same email when both the provider and the user
+// verified that email, otherwise a new user without a password is created. Returns domain.ErrInvalidToken
+// for a token that fails verification, domain.ErrBadParamInput for an unknown provider
+// or a token without email, and domain.ErrConflict when the email belongs to an
+// account that has to link the provider itself.
+func (s *Service) SignIn(ctx context.Context, provider string, idToken string) (domain.User, error) {
+	claims, err := s.verify(provider, idToken)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	identity, err := s.postgresRepoQuery.GetByProviderSubject(ctx, provider, claims.Subject)
+	if err == nil {
+		return s.userService.GetByID(ctx, identity.UserID)
+	}
+	if err != domain.ErrNotFound {
+		return domain.User{}, err
+	}
+
+	if claims.Email == "" {
+		return domain.User{}, domain.ErrBadParamInput
+	}
+
+	user, err := s.userService.GetByEmail(ctx, claims.Email)
+	switch {
+	case err == nil && claims.EmailVerified && user.EmailVerifiedAt != nil:
+		// Both the provider and the local account proved they own the mailbox, so it is
+		// the same person. An unverified local account may have been registered by
+		// someone else to keep access once the owner arrives through the provider.
+	case err == nil:
+		return domain.User{}, domain.ErrConflict
+	case err == domain.ErrNotFound:
+		if user, err = s.createUser(ctx, claims); err != nil {
+			return domain.User{}, err
+		}
+	default:
+		return domain.User{}, err
+	}
+
+	if _, err = s.link(ctx, user.ID, provider, claims); err != nil {
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+// Link adds the provider account to the signed in user. Returns domain.ErrConflict
+// when the account is linked to another user or the user already linked the provider.
+func (s *Service) Link(ctx context.Context, userID int64, provider string, idToken string) (domain.UserIdentity, error) {
+	claims, err := s.verify(provider, idToken)
+	if err != nil {
+		return domain.UserIdentity{}, err
+	}
+
+	existing, err := s.postgresRepoQuery.GetByProviderSubject(ctx, provider, claims.Subject)
+	if err == nil {
+		if existing.UserID != userID {
+			return domain.UserIdentity{}, domain.ErrConflict
+		}
+		return existing, nil
+	}
+	if err != domain.ErrNotFound {
+		return domain.UserIdentity{}, err
+	}
+
+	return s.link(ctx, userID, provider, claims)
+}
+
+// Reauthenticate checks that the ID token belongs to a provider account linked to the
+// user, which proves the user is present without a password. Returns
+// domain.ErrInvalidToken for any other token.
+func (s *Service) Reauthenticate(ctx context.Context, userID int64, provider string, idToken string) error {
+	claims, err := s.verify(provider, idToken)
+	if err != nil {
+		return domain.ErrInvalidToken
+	}
+
+	identity, err := s.postgresRepoQuery.GetByProviderSubject(ctx, provider, claims.Subject)
+	if err == domain.ErrNotFound || (err == nil && identity.UserID != userID) {
+		return domain.ErrInvalidToken
+	}
+	return err
+}
+
+// FetchByUserID returns every identity linked to the user
+func (s *Service) FetchByUserID(ctx context.Context, userID int64) ([]domain.UserIdentity, error) {
+	return s.postgresRepoQuery.FetchByUserID(ctx, userID)
+}
+
+// Unlink removes the provider from the user. The last way to sign in can not be
+// removed from an account without a password, that returns domain.ErrConflict.
+func (s *Service) Unlink(ctx context.Context, user domain.User, provider string) error {
+	if user.Password == "" {
+		identities, err := s.postgresRepoQuery.FetchByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if len(identities) <= 1 {
+			return domain.ErrConflict
+		}
+	}
+
+	if err := s.postgresRepoCommand.Delete(ctx, user.ID, provider); err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":    "identity_unlinked",
+		"user_id":  user.ID,
+		"provider": provider,
+	}).Info("Identity provider unlinked")
+
+	return nil
+}
+
+func (s *Service) verify(provider string, idToken string) (oidc.Claims, error) {
+	claims, err := s.verifier.Verify(provider, idToken)
+	if errors.Is(err, oidc.ErrUnknownProvider) {
+		return oidc.Claims{}, domain.ErrBadParamInput
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"event":    "id_token_rejected",
+			"provider": provider,
+		}).Warn(err)
+		return oidc.Claims{}, domain.ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// createUser registers a user for the provider account. The user has no password
+// until they set one through the password reset flow.
+func (s *Service) createUser(ctx context.Context, claims oidc.Claims) (domain.User, error) {
+	fullName := strings.TrimSpace(claims.Name)
+	if fullName == "" {
+		fullName = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+
+	user := domain.User{
+		FullName: fullName,
+		Email:    claims.Email,
+	}
+	if err := s.userService.Store(ctx, &user); err != nil {
+		return domain.User{}, err
+	}
+
+	if claims.EmailVerified {
+		now := time.Now()
+		if err := s.userService.UpdateEmailVerifiedAt(ctx, user.ID, now); err != nil {
+			return domain.User{}, err
+		}
+		user.EmailVerifiedAt = &now
+	}
+
+	return user, nil
+}
+
+func (s *Service) link(ctx context.Context, userID int64, provider string, claims oidc.Claims) (domain.UserIdentity, error) {
+	identity := domain.UserIdentity{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+	if err := s.postgresRepoCommand.Store(ctx, &identity); err != nil {
+		return domain.UserIdentity{}, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":    "identity_linked",
+		"user_id":  identity.UserID,
+		"provider": identity.Provider,
+	}).Info("Identity provider linked")
+
+	return identity, nil
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// failureLog holds the failures of a key still inside the window they were counted in
+type failureLog struct {
+	at        []time.Time
+	expiresAt time.Time // When the newest failure leaves the window
+}
+
+type strike struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// LoginAttemptRepository keeps failed login attempts in process memory. It is the
+// fallback when Redis is not configured and only protects a single instance. Expired
+// entries are swept regularly, so traffic over many keys does not grow it unbounded.
+type LoginAttemptRepository struct {
+	mu        sync.Mutex
+	failures  map[string]failureLog
+	strikes   map[string]strike
+	locks     map[string]time.Time
+	lastSweep time.Time
+}
+
+// sweepInterval is how often expired entries are removed
+const sweepInterval = time.Minute
+
+// NewLoginAttemptRepository creates an empty in-memory attempt store
+func NewLoginAttemptRepository() *LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		failures: make(map[string]failureLog),
+		strikes:  make(map[string]strike),
+		locks:    make(map[string]time.Time),
+	}
+}
+
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sweep(now)
+
+	cutoff := now.Add(-window)
+	kept := make([]time.Time, 0, len(r.failures[key].at)+1)
+	for _, at := range r.failures[key].at {
+		if at.After(cutoff) {
+			kept = append(kept, at)
+		}
+	}
+	kept = append(kept, now)
+	r.failures[key] = failureLog{at: kept, expiresAt: now.Add(window)}
+
+	return int64(len(kept)), nil
+}
+
+// sweep drops the failures, strikes and locks that expired, at most once per
+// sweepInterval. The caller holds the lock.
+func (r *LoginAttemptRepository) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < sweepInterval {
+		return
+	}
+	r.lastSweep = now
+
+	for key, entry := range r.failures {
+		if !now.Before(entry.expiresAt) {
+			delete(r.failures, key)
+		}
+	}
+	for key, s := range r.strikes {
+		if now.After(s.expiresAt) {
+			delete(r.strikes, key)
+		}
+	}
+	for key, until := range r.locks {
+		if now.After(until) {
+			delete(r.locks, key)
+		}
+	}
+}
+
+func (r *LoginAttemptRepository) ResetFailures(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.failures, key)
+	return nil
+}
+
+func (r *LoginAttemptRepository) IncrementStrikes(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.sweep(now)
+
+	s := r.strikes[key]
+	if now.After(s.expiresAt) {
+		s = strike{}
+	}
+	s.count++
+	s.expiresAt = now.Add(ttl)
+	r.strikes[key] = s
+
+	return s.count, nil
+}
+
+func (r *LoginAttemptRepository) SetLock(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.locks[key] = until
+	return nil
+}
+
+func (r *LoginAttemptRepository) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, ok := r.locks[key]
+	if !ok {
+		return time.Time{}, nil
+	}
+	if time.Now().After(until) {
+		delete(r.locks, key)
+		return time.Time{}, nil
+	}
+	return until, nil
+}
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type PostgresAccountCommandRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresAccountCommandRepository(conn *sql.DB) *PostgresAccountCommandRepository {
+	return &PostgresAccountCommandRepository{Conn: conn}
+}
+
+// purgeStatements remove or anonymise the personal data of a user, each takes the user ID as $1.
+// Carts, payments, transactions and the addresses they point to are kept for bookkeeping,
+// those addresses are blanked instead.
+var purgeStatements = []string{
+	`DELETE FROM token WHERE user_id = $1`,
+	`DELETE FROM devices WHERE user_id = $1`,
+	`DELETE FROM user_condition WHERE user_id = $1`,
+	`DELETE FROM otp WHERE user_id = $1`,
+	`DELETE FROM password_reset WHERE user_id = $1`,
+	`DELETE FROM user_roles WHERE user_id = $1`,
+	`DELETE FROM user_identity WHERE user_id = $1`,
+	`DELETE FROM mfa_recovery_code WHERE user_id = $1`,
+	`DELETE FROM user_mfa WHERE user_id = $1`,
+	`DELETE FROM magic_link WHERE user_id = $1`,
+	`DELETE FROM address WHERE user_id = $1
+	 AND id NOT IN (SELECT address_id FROM transaction WHERE address_id IS NOT NULL)`,
+	`UPDATE address SET label = '', address = '', name = '', notes = NULL, phone = '' WHERE user_id = $1`,
+	`DELETE FROM cart WHERE user_id = $1 AND status = 'active'
+	 AND id NOT IN (SELECT cart_id FROM transaction WHERE cart_id IS NOT NULL)`,
+	// Redemptions stay with their orders, unlinked like the foreign key does when a user is deleted
+	`UPDATE voucher_redemption SET user_id = NULL WHERE user_id = $1`,
+}
+
+// Purge anonymises the account and removes its personal data in one transaction.
+// It returns false without changing anything when the deletion was cancelled meanwhile.
+func (r *PostgresAccountCommandRepository) Purge(ctx context.Context, userID int64) (purged bool, err error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil || !purged {
+			tx.Rollback()
+		}
+	}()
+
+	// The account row is locked by this update, a concurrent cancel waits for the purge
+	query := `UPDATE users SET
+			  full_name = 'Deleted user',
+			  email = 'deleted-' || id || '@deleted.invalid',
+			  password = '',
+			  phone = NULL,
+			  phone_verified_at = NULL,
+			  avatar_url = NULL,
+			  pending_email = NULL,
+			  email_verified_at = NULL,
+			  email_verification_sent_at = NULL,
+			  deletion_scheduled_at = NULL,
+			  deleted_at = CURRENT_TIMESTAMP
+			  WHERE id = $1 AND deleted_at IS NULL
+			  AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= CURRENT_TIMESTAMP`
+
+	res, err := tx.ExecContext(ctx, query, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to anonymise user: %w", err)
+	}
+
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affect == 0 {
+		return false, nil
+	}
+
+	for _, statement := range purgeStatements {
+		if _, err = tx.ExecContext(ctx, statement, userID); err != nil {
+			return false, fmt.Errorf("failed to purge user data: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
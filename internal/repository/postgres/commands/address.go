@@ -25,23 +25,36 @@ func (m *PostgresAddressCommandRepository) Create(ctx context.Context, a domain.
 	return a, nil
 }
 
-// Update method for modifying an existing address, including label and notes
+// Update method for modifying an existing address, including label and notes.
+// Only the owner's row is matched, an address of another user is reported as not found.
 func (m *PostgresAddressCommandRepository) Update(ctx context.Context, a domain.Address) (domain.Address, error) {
 	query := `UPDATE address SET name=$1, address=$2, phone=$3, label=$4, notes=$5, updated_at=NOW() 
-              WHERE id=$6`
-	_, err := m.Conn.ExecContext(ctx, query, a.Name, a.Address, a.Phone, a.Label, a.Notes, a.ID)
+              WHERE id=$6 AND user_id=$7
+              RETURNING updated_at`
+	err := m.Conn.QueryRowContext(ctx, query, a.Name, a.Address, a.Phone, a.Label, a.Notes, a.ID, a.UserID).Scan(&a.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return a, domain.ErrNotFound
+	}
 	if err != nil {
 		return a, err
 	}
 	return a, nil
 }
 
-// Delete method remains unchanged
-func (m *PostgresAddressCommandRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM address WHERE id=$1`
-	_, err := m.Conn.ExecContext(ctx, query, id)
+// Delete method for removing an address of the given user
+func (m *PostgresAddressCommandRepository) Delete(ctx context.Context, userID int64, id int64) error {
+	query := `DELETE FROM address WHERE id=$1 AND user_id=$2`
+	result, err := m.Conn.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
 	return nil
 }
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+)
+
+type PostgresCartCommandRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresCartCommandRepository(conn *sql.DB) *PostgresCartCommandRepository {
+	return &PostgresCartCommandRepository{Conn: conn}
+}
+
+// EnsureActive returns the ID of the active cart of the user, creating the cart when there is none
+func (r *PostgresCartCommandRepository) EnsureActive(ctx context.Context, userID int64) (id int64, err error) {
+	// Concurrent requests race on the partial unique index, the loser reads the winner's cart
+	query := `INSERT INTO cart (user_id, total_price, status) VALUES ($1, 0, $2)
+			  ON CONFLICT (user_id) WHERE status = 'active' DO NOTHING
+			  RETURNING id`
+
+	err = r.Conn.QueryRowContext(ctx, query, userID, domain.CartStatusActive).Scan(&id)
+	if err == sql.ErrNoRows {
+		err = r.Conn.QueryRowContext(ctx, `SELECT id FROM cart WHERE user_id = $1 AND status = $2`, userID, domain.CartStatusActive).Scan(&id)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get active cart: %w", err)
+	}
+
+	return id, nil
+}
+
+// AddItem inserts the item into the cart, or raises the quantity up to maxQuantity
+// when the menu or bundling is already in it
+func (r *PostgresCartCommandRepository) AddItem(ctx context.Context, item domain.CartItem, maxQuantity int) (err error) {
+	conflict := `(cart_id, menu_id) WHERE menu_id IS NOT NULL`
+	if item.BundlingID != nil {
+		conflict = `(cart_id, bundling_id) WHERE bundling_id IS NOT NULL`
+	}
+
+	// Items only go into an active cart, the share lock waits for a checkout in progress
+	query := fmt.Sprintf(`INSERT INTO cart_item (cart_id, menu_id, bundling_id, image_url, name, quantity, price)
+			  SELECT id, $2::BIGINT, $3::BIGINT, $4::VARCHAR, $5::VARCHAR, $6::INT, $7::DECIMAL FROM cart WHERE id = $1 AND status = 'active' FOR SHARE
+			  ON CONFLICT %s DO UPDATE SET quantity = LEAST(cart_item.quantity + EXCLUDED.quantity, $8::INT)`, conflict)
+
+	result, err := r.Conn.ExecContext(ctx, query, item.CartID, item.MenuID, item.BundlingID, item.ImageURL, item.Name, item.Quantity, item.Price, maxQuantity)
+	if err == nil {
+		var affected int64
+		if affected, err = result.RowsAffected(); err == nil && affected == 0 {
+			return domain.ErrNotFound
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to add cart item: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateItemQuantity sets the quantity of an item of an active cart
+func (r *PostgresCartCommandRepository) UpdateItemQuantity(ctx context.Context, cartID int64, itemID int64, quantity int) (err error) {
+	query := `UPDATE cart_item SET quantity = $1
+			  WHERE id = $2 AND cart_id = (SELECT id FROM cart WHERE id = $3 AND status = 'active' FOR SHARE)`
+
+	return r.execItem(ctx, query, quantity, itemID, cartID)
+}
+
+// DeleteItem removes an item from an active cart
+func (r *PostgresCartCommandRepository) DeleteItem(ctx context.Context, cartID int64, itemID int64) (err error) {
+	query := `DELETE FROM cart_item
+			  WHERE id = $1 AND cart_id = (SELECT id FROM cart WHERE id = $2 AND status = 'active' FOR SHARE)`
+
+	return r.execItem(ctx, query, itemID, cartID)
+}
+
+// Reprice copies the current name, image and price of every menu and bundling into
+// the items of an active cart and recalculates its total, all in one transaction
+func (r *PostgresCartCommandRepository) Reprice(ctx context.Context, cartID int64) (err error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	statements := []string{
+		`SELECT id FROM cart WHERE id = $1 AND status = 'active' FOR UPDATE`,
+		`UPDATE cart_item ci SET price = m.price, name = m.title, image_url = m.image_url
+		 FROM menu m WHERE ci.menu_id = m.id AND ci.cart_id = $1
+		 AND (ci.price <> m.price OR ci.name <> m.title OR ci.image_url IS DISTINCT FROM m.image_url)`,
+		`UPDATE cart_item ci SET price = b.price, name = COALESCE(b.bundling_name, ''), image_url = b.image_url
+		 FROM bundling b WHERE ci.bundling_id = b.id AND ci.cart_id = $1
+		 AND (ci.price <> b.price OR ci.name <> COALESCE(b.bundling_name, '') OR ci.image_url IS DISTINCT FROM b.image_url)`,
+		`UPDATE cart SET total_price = COALESCE((SELECT SUM(price * quantity) FROM cart_item WHERE cart_id = $1), 0)
+		 WHERE id = $1`,
+	}
+
+	var locked int64
+	if err = tx.QueryRowContext(ctx, statements[0], cartID).Scan(&locked); err != nil {
+		if err == sql.ErrNoRows {
+			err = domain.ErrNotFound
+			return err
+		}
+		return fmt.Errorf("failed to lock cart: %w", err)
+	}
+
+	for _, statement := range statements[1:] {
+		if _, err = tx.ExecContext(ctx, statement, cartID); err != nil {
+			return fmt.Errorf("failed to reprice cart: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresCartCommandRepository) execItem(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.Conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update cart item: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
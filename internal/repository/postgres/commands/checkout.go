@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+	"time"
+)
+
+type PostgresCheckoutCommandRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresCheckoutCommandRepository(conn *sql.DB) *PostgresCheckoutCommandRepository {
+	return &PostgresCheckoutCommandRepository{Conn: conn}
+}
+
+// Create places the order in one transaction: it locks the active cart, checks its
+// items still add up to itemsTotal, creates the payment and the transaction, starts the
+// timeline of the order, redeems the voucher if any and marks the cart used. Returns
+// domain.ErrNotFound when the cart is no longer active, domain.ErrConflict when its
+// items changed since they were priced and the voucher errors when the voucher can
+// no longer be redeemed.
+func (r *PostgresCheckoutCommandRepository) Create(ctx context.Context, cartID int64, itemsTotal domain.Money, payment *domain.Payment, transaction *domain.Transaction, redemption *domain.VoucherRedemption) (err error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var locked int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM cart WHERE id = $1 AND user_id = $2 AND status = $3 FOR UPDATE`,
+		cartID, transaction.UserID, domain.CartStatusActive).Scan(&locked)
+	if err == sql.ErrNoRows {
+		err = domain.ErrNotFound
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock cart: %w", err)
+	}
+
+	var total domain.Money
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(price * quantity), 0) FROM cart_item WHERE cart_id = $1`, cartID).Scan(&total)
+	if err != nil {
+		return fmt.Errorf("failed to total cart: %w", err)
+	}
+	if total != itemsTotal {
+		err = domain.ErrConflict
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO payment (reference_id, session_id, user_id, amount, status, url)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at`,
+		payment.ReferenceID, payment.SessionID, payment.UserID, payment.Amount, payment.Status, payment.URL,
+	).Scan(&payment.ID, &payment.CreatedAt, &payment.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create payment: %w", err)
+	}
+
+	transaction.CartID = cartID
+	transaction.PaymentID = payment.ID
+	err = tx.QueryRowContext(ctx, `INSERT INTO transaction (user_id, cart_id, payment_id, courier_id, address_id, voucher_id, discount,
+		courier_fee, service_fee, service_fee_rate, tax, tax_rate, amount, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, created_at, updated_at`,
+		transaction.UserID, transaction.CartID, transaction.PaymentID, transaction.CourierID, transaction.AddressID,
+		transaction.VoucherID, transaction.Discount, transaction.CourierFee, transaction.ServiceFee, transaction.ServiceFeeRate,
+		transaction.Tax, transaction.TaxRate, transaction.Amount, transaction.Status,
+	).Scan(&transaction.ID, &transaction.CreatedAt, &transaction.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	if redemption != nil {
+		redemption.TransactionID = transaction.ID
+		if err = redeemVoucher(ctx, tx, redemption, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if err = insertOrderStatusHistory(ctx, tx, transaction.ID, nil, transaction.Status, domain.OrderActor{Type: domain.OrderActorCustomer, ID: transaction.UserID}, ""); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE cart SET status = $1 WHERE id = $2`, domain.CartStatusUsed, cartID); err != nil {
+		return fmt.Errorf("failed to mark cart used: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
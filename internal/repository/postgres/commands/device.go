@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"santapan/domain"
+	"time"
 )
 
 type PostgresDeviceCommandRepository struct {
@@ -17,8 +18,8 @@ func NewPostgresDeviceCommandRepository(Conn *sql.DB) *PostgresDeviceCommandRepo
 
 // Store inserts a new device into the database
 func (r *PostgresDeviceCommandRepository) Store(ctx context.Context, device *domain.Device) (err error) {
-	query := `INSERT INTO devices (name, brand, unique_id, user_id, ip_address, created_at, updated_at) 
-              VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	query := `INSERT INTO devices (name, brand, model, unique_id, user_id, ip_address, last_seen_at, created_at, updated_at) 
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
 
 	// Prepare the statement
 	stmt, err := r.Conn.PrepareContext(ctx, query)
@@ -28,7 +29,7 @@ func (r *PostgresDeviceCommandRepository) Store(ctx context.Context, device *dom
 	defer stmt.Close()
 
 	// Execute the statement
-	err = stmt.QueryRowContext(ctx, device.Name, device.Brand, device.UniqueID, device.UserID, device.IPAddress, device.CreatedAt, device.UpdatedAt).Scan(&device.ID)
+	err = stmt.QueryRowContext(ctx, device.Name, device.Brand, device.Model, device.UniqueID, device.UserID, device.IPAddress, device.LastSeenAt, device.CreatedAt, device.UpdatedAt).Scan(&device.ID)
 	if err != nil {
 		return fmt.Errorf("failed to execute statement: %w", err)
 	}
@@ -39,8 +40,8 @@ func (r *PostgresDeviceCommandRepository) Store(ctx context.Context, device *dom
 // Update modifies an existing device in the database
 func (r *PostgresDeviceCommandRepository) Update(ctx context.Context, device *domain.Device) (err error) {
 	query := `UPDATE devices 
-              SET name = $1, brand = $2, unique_id = $3, ip_address = $4, updated_at = $5 
-              WHERE id = $6`
+              SET name = $1, brand = $2, model = $3, unique_id = $4, ip_address = $5, last_seen_at = $6, updated_at = $7, deleted_at = $8 
+              WHERE id = $9`
 
 	// Prepare the statement
 	stmt, err := r.Conn.PrepareContext(ctx, query)
@@ -50,10 +51,41 @@ func (r *PostgresDeviceCommandRepository) Update(ctx context.Context, device *do
 	defer stmt.Close()
 
 	// Execute the statement
-	_, err = stmt.ExecContext(ctx, device.Name, device.Brand, device.UniqueID, device.IPAddress, device.UpdatedAt, device.ID)
+	_, err = stmt.ExecContext(ctx, device.Name, device.Brand, device.Model, device.UniqueID, device.IPAddress, device.LastSeenAt, device.UpdatedAt, device.DeletedAt, device.ID)
 	if err != nil {
 		return fmt.Errorf("failed to execute statement: %w", err)
 	}
 
 	return nil
 }
+
+// Touch records the IP address and time a device was last seen
+func (r *PostgresDeviceCommandRepository) Touch(ctx context.Context, id int64, ipAddress string, lastSeenAt time.Time) (err error) {
+	query := `UPDATE devices SET ip_address = $1, last_seen_at = $2 WHERE id = $3`
+
+	if _, err = r.Conn.ExecContext(ctx, query, ipAddress, lastSeenAt, id); err != nil {
+		return fmt.Errorf("failed to execute statement: %w", err)
+	}
+
+	return nil
+}
+
+// Delete soft deletes a device, marking it as signed out
+func (r *PostgresDeviceCommandRepository) Delete(ctx context.Context, id int64) (err error) {
+	query := `UPDATE devices SET deleted_at = CURRENT_TIMESTAMP WHERE id = $1 AND deleted_at IS NULL`
+
+	res, err := r.Conn.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to execute statement: %w", err)
+	}
+
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affect != 1 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
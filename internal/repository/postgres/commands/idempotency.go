@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+	"time"
+)
+
+type PostgresIdempotencyCommandRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresIdempotencyCommandRepository(conn *sql.DB) *PostgresIdempotencyCommandRepository {
+	return &PostgresIdempotencyCommandRepository{Conn: conn}
+}
+
+// Reserve stores the record unless a live record exists for its key, which is returned
+// instead. An expired record, or one whose lease ran out before it was completed, is
+// replaced as if it never existed.
+func (r *PostgresIdempotencyCommandRepository) Reserve(ctx context.Context, record domain.IdempotencyRecord) (existing domain.IdempotencyRecord, reserved bool, err error) {
+	query := `INSERT INTO idempotency_key (key, fingerprint, expires_at, locked_until) VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (key) DO UPDATE SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, content_type = '',
+			  body = NULL, created_at = NOW(), expires_at = EXCLUDED.expires_at, locked_until = EXCLUDED.locked_until
+			  WHERE idempotency_key.expires_at <= NOW()
+			  OR (idempotency_key.status_code IS NULL AND idempotency_key.locked_until <= NOW())
+			  RETURNING key`
+
+	var key string
+	err = r.Conn.QueryRowContext(ctx, query, record.Key, record.Fingerprint, record.ExpiresAt, record.LockedUntil).Scan(&key)
+	if err == nil {
+		return domain.IdempotencyRecord{}, true, nil
+	}
+	if err != sql.ErrNoRows {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	var statusCode sql.NullInt64
+	var lockedUntil sql.NullTime
+	err = r.Conn.QueryRowContext(ctx, `SELECT key, fingerprint, status_code, content_type, COALESCE(body, ''), expires_at, locked_until
+		FROM idempotency_key WHERE key = $1`, record.Key).Scan(
+		&existing.Key,
+		&existing.Fingerprint,
+		&statusCode,
+		&existing.ContentType,
+		&existing.Body,
+		&existing.ExpiresAt,
+		&lockedUntil,
+	)
+	if err == sql.ErrNoRows {
+		// Removed between both statements, the client retries
+		return domain.IdempotencyRecord{}, false, domain.ErrConflict
+	}
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	existing.StatusCode = int(statusCode.Int64)
+	existing.LockedUntil = lockedUntil.Time
+
+	return existing, false, nil
+}
+
+// Complete records the response of a reserved request, it is kept until the expiry of the record
+func (r *PostgresIdempotencyCommandRepository) Complete(ctx context.Context, record domain.IdempotencyRecord) error {
+	query := `UPDATE idempotency_key SET status_code = $1, content_type = $2, body = $3, expires_at = $4, locked_until = NULL
+			  WHERE key = $5 AND fingerprint = $6 AND status_code IS NULL`
+
+	_, err := r.Conn.ExecContext(ctx, query, record.StatusCode, record.ContentType, record.Body, record.ExpiresAt, record.Key, record.Fingerprint)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Delete frees a key whose request could not be handled so it can be retried
+func (r *PostgresIdempotencyCommandRepository) Delete(ctx context.Context, key string) error {
+	if _, err := r.Conn.ExecContext(ctx, `DELETE FROM idempotency_key WHERE key = $1`, key); err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes the keys that expired before now and the reservations abandoned before now
+func (r *PostgresIdempotencyCommandRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.Conn.ExecContext(ctx, `DELETE FROM idempotency_key
+		WHERE expires_at <= $1 OR (status_code IS NULL AND locked_until <= $1)`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+)
+
+type PostgresIdentityCommandRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresIdentityCommandRepository(conn *sql.DB) *PostgresIdentityCommandRepository {
+	return &PostgresIdentityCommandRepository{Conn: conn}
+}
+
+// Store links the identity to its user. Returns domain.ErrConflict when the provider
+// account is already linked, or the user already has an identity of that provider.
+func (r *PostgresIdentityCommandRepository) Store(ctx context.Context, identity *domain.UserIdentity) (err error) {
+	query := `INSERT INTO user_identity (user_id, provider, subject, email, created_at)
+			  VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+			  ON CONFLICT DO NOTHING
+			  RETURNING id, created_at`
+
+	err = r.Conn.QueryRowContext(ctx, query, identity.UserID, identity.Provider, identity.Subject, identity.Email).
+		Scan(&identity.ID, &identity.CreatedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store identity: %w", err)
+	}
+
+	return nil
+}
+
+// Delete unlinks the identity of the provider from the user
+func (r *PostgresIdentityCommandRepository) Delete(ctx context.Context, userID int64, provider string) (err error) {
+	query := `DELETE FROM user_identity WHERE user_id = $1 AND provider = $2`
+
+	result, err := r.Conn.ExecContext(ctx, query, userID, provider)
+	if err != nil {
+		return fmt.Errorf("failed to delete identity: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"santapan/domain"
+)
+
+type PostgresInvoiceCommandRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresInvoiceCommandRepository(conn *sql.DB) *PostgresInvoiceCommandRepository {
+	return &PostgresInvoiceCommandRepository{Conn: conn}
+}
+
+// Issue stores the invoice under the next number of the month it is issued in. The
+// number is taken in the same transaction, so numbers have no gaps even when issuing
+// fails. Returns domain.ErrConflict when the order already has an invoice.
+func (r *PostgresInvoiceCommandRepository) Issue(ctx context.Context, invoice *domain.Invoice) (err error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// The row lock on the month makes concurrent invoices wait for their number
+	var sequence int
+	err = tx.QueryRowContext(ctx, `INSERT INTO invoice_sequence (period, last_number) VALUES ($1, 1)
+		ON CONFLICT (period) DO UPDATE SET last_number = invoice_sequence.last_number + 1
+		RETURNING last_number`, domain.InvoicePeriod(invoice.IssuedAt)).Scan(&sequence)
+	if err != nil {
+		return fmt.Errorf("failed to take invoice number: %w", err)
+	}
+	invoice.Number = domain.InvoiceNumber(invoice.IssuedAt, sequence)
+
+	lines, err := json.Marshal(invoice.Lines)
+	if err != nil {
+		return fmt.Errorf("failed to encode invoice lines: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO invoice (number, transaction_id, user_id, customer_name, customer_email,
+			recipient_name, recipient_phone, delivery_address, courier, payment_method, lines, items_total, discount,
+			courier_fee, service_fee, service_fee_rate, tax, tax_rate, total, ordered_at, issued_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+		ON CONFLICT (transaction_id) DO NOTHING
+		RETURNING id`,
+		invoice.Number, invoice.TransactionID, invoice.UserID, invoice.CustomerName, invoice.CustomerEmail,
+		invoice.RecipientName, invoice.RecipientPhone, invoice.DeliveryAddress, invoice.Courier, invoice.PaymentMethod, lines,
+		invoice.ItemsTotal, invoice.Discount, invoice.CourierFee, invoice.ServiceFee, invoice.ServiceFeeRate,
+		invoice.Tax, invoice.TaxRate, invoice.Total, invoice.OrderedAt, invoice.IssuedAt,
+	).Scan(&invoice.ID)
+	if err == sql.ErrNoRows {
+		err = domain.ErrConflict
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create invoice: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
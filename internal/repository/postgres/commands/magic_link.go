@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+	"time"
+)
+
+type PostgresMagicLinkCommandRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresMagicLinkCommandRepository(conn *sql.DB) *PostgresMagicLinkCommandRepository {
+	return &PostgresMagicLinkCommandRepository{Conn: conn}
+}
+
+// Store inserts a new magic link
+func (r *PostgresMagicLinkCommandRepository) Store(ctx context.Context, link *domain.MagicLink) (err error) {
+	query := `INSERT INTO magic_link (user_id, token_hash, expires_at, created_at) VALUES ($1, $2, $3, CURRENT_TIMESTAMP) RETURNING id, created_at`
+
+	stmt, err := r.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, link.UserID, link.TokenHash, link.ExpiresAt).Scan(&link.ID, &link.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to execute statement: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marks an unused, unexpired link as used and returns it. The check and
+// the update happen in one statement so a link can never be used twice.
+func (r *PostgresMagicLinkCommandRepository) Consume(ctx context.Context, tokenHash string) (res domain.MagicLink, err error) {
+	query := `UPDATE magic_link SET used_at = CURRENT_TIMESTAMP
+			  WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+			  RETURNING id, user_id, token_hash, expires_at, used_at, created_at`
+
+	err = r.Conn.QueryRowContext(ctx, query, tokenHash).Scan(
+		&res.ID,
+		&res.UserID,
+		&res.TokenHash,
+		&res.ExpiresAt,
+		&res.UsedAt,
+		&res.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return domain.MagicLink{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.MagicLink{}, fmt.Errorf("failed to consume magic link: %w", err)
+	}
+
+	return res, nil
+}
+
+// InvalidateByUserID marks every unused link of the user as used
+func (r *PostgresMagicLinkCommandRepository) InvalidateByUserID(ctx context.Context, userID int64) (err error) {
+	query := `UPDATE magic_link SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND used_at IS NULL`
+
+	if _, err = r.Conn.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to invalidate magic links: %w", err)
+	}
+
+	return nil
+}
+
+// CountSince counts the links issued to the user since the given time
+func (r *PostgresMagicLinkCommandRepository) CountSince(ctx context.Context, userID int64, since time.Time) (count int64, err error) {
+	query := `SELECT COUNT(*) FROM magic_link WHERE user_id = $1 AND created_at >= $2`
+
+	if err = r.Conn.QueryRowContext(ctx, query, userID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count magic links: %w", err)
+	}
+
+	return count, nil
+}
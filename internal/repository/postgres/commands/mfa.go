@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+)
+
+type PostgresMfaCommandRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresMfaCommandRepository(conn *sql.DB) *PostgresMfaCommandRepository {
+	return &PostgresMfaCommandRepository{Conn: conn}
+}
+
+// StorePending starts a new enrolment, replacing an enrolment that was never activated
+func (r *PostgresMfaCommandRepository) StorePending(ctx context.Context, userID int64, secret string) error {
+	query := `INSERT INTO user_mfa (user_id, secret, created_at, updated_at) VALUES ($1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+			  ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, updated_at = CURRENT_TIMESTAMP
+			  WHERE user_mfa.enabled_at IS NULL`
+
+	if _, err := r.Conn.ExecContext(ctx, query, userID, secret); err != nil {
+		return fmt.Errorf("failed to store mfa enrolment: %w", err)
+	}
+
+	return nil
+}
+
+// Enable activates the enrolment and stores its recovery codes in one transaction
+func (r *PostgresMfaCommandRepository) Enable(ctx context.Context, userID int64, step int64, codeHashes []string) (err error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query := `UPDATE user_mfa SET enabled_at = CURRENT_TIMESTAMP, last_used_step = $1, updated_at = CURRENT_TIMESTAMP
+			  WHERE user_id = $2 AND enabled_at IS NULL`
+
+	res, err := tx.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return fmt.Errorf("failed to enable mfa: %w", err)
+	}
+
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affect != 1 {
+		err = domain.ErrConflict
+		return err
+	}
+
+	if err = replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// UseStep records the time step of an accepted code. It returns false when that
+// step or a later one was already used, i.e. the code is being replayed.
+func (r *PostgresMfaCommandRepository) UseStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	query := `UPDATE user_mfa SET last_used_step = $1, updated_at = CURRENT_TIMESTAMP WHERE user_id = $2 AND last_used_step < $1`
+
+	res, err := r.Conn.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to use mfa step: %w", err)
+	}
+
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affect == 1, nil
+}
+
+// ConsumeRecoveryCode marks an unused recovery code as used, returning false if there is none
+func (r *PostgresMfaCommandRepository) ConsumeRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	query := `UPDATE mfa_recovery_code SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	res, err := r.Conn.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to consume recovery code: %w", err)
+	}
+
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affect > 0, nil
+}
+
+// ReplaceRecoveryCodes discards the previous recovery codes of the user and stores new ones
+func (r *PostgresMfaCommandRepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) (err error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the second factor and the recovery codes of the user
+func (r *PostgresMfaCommandRepository) Delete(ctx context.Context, userID int64) (err error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM mfa_recovery_code WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete mfa: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_code WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, hash := range codeHashes {
+		query := `INSERT INTO mfa_recovery_code (user_id, code_hash, created_at) VALUES ($1, $2, CURRENT_TIMESTAMP)`
+		if _, err := tx.ExecContext(ctx, query, userID, hash); err != nil {
+			return fmt.Errorf("failed to store recovery code: %w", err)
+		}
+	}
+
+	return nil
+}
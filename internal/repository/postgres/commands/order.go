@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+	"strings"
+)
+
+type PostgresOrderCommandRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresOrderCommandRepository(conn *sql.DB) *PostgresOrderCommandRepository {
+	return &PostgresOrderCommandRepository{Conn: conn}
+}
+
+// UpdateStatus moves an order to a new status and records the change in its timeline.
+// The current status is locked while the transition is checked, so concurrent changes
+// are applied one after the other. Returns the previous status, domain.ErrNotFound for
+// an unknown order and domain.ErrInvalidTransition when the change is not allowed.
+// Cancelling expires the unpaid payment of the order in the same transaction, so the
+// hosted payment page can no longer settle it.
+func (r *PostgresOrderCommandRepository) UpdateStatus(ctx context.Context, id int64, status domain.OrderStatus, actor domain.OrderActor, note string) (from domain.OrderStatus, err error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = tx.QueryRowContext(ctx, `SELECT status FROM transaction WHERE id = $1 FOR UPDATE`, id).Scan(&from)
+	if err == sql.ErrNoRows {
+		err = domain.ErrNotFound
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to lock order: %w", err)
+	}
+
+	if !from.CanTransitionTo(status) {
+		err = domain.ErrInvalidTransition
+		return from, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE transaction SET status = $1 WHERE id = $2`, status, id); err != nil {
+		return "", fmt.Errorf("failed to update order status: %w", err)
+	}
+
+	if status == domain.OrderStatusCancelled {
+		_, err = tx.ExecContext(ctx, `UPDATE payment SET status = $1
+			WHERE id = (SELECT payment_id FROM transaction WHERE id = $2) AND status = $3`,
+			domain.PaymentStatusExpired, id, domain.PaymentStatusUnpaid)
+		if err != nil {
+			return "", fmt.Errorf("failed to expire payment: %w", err)
+		}
+	}
+
+	if err = insertOrderStatusHistory(ctx, tx, id, &from, status, actor, note); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return from, nil
+}
+
+// insertOrderStatusHistory adds an entry to the timeline of an order, from is nil when the order is placed
+func insertOrderStatusHistory(ctx context.Context, tx *sql.Tx, transactionID int64, from *domain.OrderStatus, to domain.OrderStatus, actor domain.OrderActor, note string) error {
+	var actorID *int64
+	if actor.ID != 0 {
+		actorID = &actor.ID
+	}
+
+	_, err := tx.ExecContext(ctx, `INSERT INTO order_status_history (transaction_id, from_status, to_status, actor_type, actor_id, note)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		transactionID, from, to, actor.Type, actorID, note)
+	if err != nil {
+		return fmt.Errorf("failed to record order status: %w", err)
+	}
+
+	return nil
+}
+
+// orderStatusPlaceholders returns "$n, $n+1, ..." for the statuses and their arguments
+func orderStatusPlaceholders(start int, statuses []domain.OrderStatus) (string, []interface{}) {
+	placeholders := make([]string, 0, len(statuses))
+	args := make([]interface{}, 0, len(statuses))
+	for i, status := range statuses {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", start+i))
+		args = append(args, status)
+	}
+	return strings.Join(placeholders, ", "), args
+}
@@ -16,72 +16,98 @@ func NewPostgresOtpCommandRepository(Conn *sql.DB) *PostgresOtpCommandRepository
 }
 
 func (r *PostgresOtpCommandRepository) Store(ctx context.Context, otp *domain.Otp) (err error) {
-	query := `INSERT otp SET code=?, device_id=?, user_id=?, created_at=?, updated_at=?`
+	query := `INSERT INTO otp (user_id, phone, code, type, retry, device_id, expires_at, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id, created_at, updated_at`
 	stmt, err := r.Conn.PrepareContext(ctx, query)
-
 	if err != nil {
-		return
+		return fmt.Errorf("failed to prepare query: %w", err)
 	}
+	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, otp.Code, otp.DeviceId, otp.UserId, otp.CreatedAt, otp.UpdatedAt)
-
-	lastID, err := res.LastInsertId()
-
+	err = stmt.QueryRowContext(ctx, otp.UserId, otp.Phone, otp.Code, otp.Type, otp.Retry, otp.DeviceId, otp.ExpiresAt).
+		Scan(&otp.ID, &otp.CreatedAt, &otp.UpdatedAt)
 	if err != nil {
-		return
+		return fmt.Errorf("failed to execute query: %w", err)
 	}
 
-	otp.ID = lastID
-
-	return
+	return nil
 }
 
 func (m *PostgresOtpCommandRepository) Delete(ctx context.Context, id int64) (err error) {
-	query := "DELETE FROM otp WHERE id = ?"
+	query := "DELETE FROM otp WHERE id = $1"
 
 	stmt, err := m.Conn.PrepareContext(ctx, query)
 	if err != nil {
-		return
+		return fmt.Errorf("failed to prepare delete query: %w", err)
 	}
+	defer stmt.Close()
 
 	res, err := stmt.ExecContext(ctx, id)
 	if err != nil {
-		return
+		return fmt.Errorf("failed to execute delete query: %w", err)
 	}
 
-	rowsAfected, err := res.RowsAffected()
+	rowsAffected, err := res.RowsAffected()
 	if err != nil {
-		return
+		return fmt.Errorf("failed to check affected rows: %w", err)
 	}
 
-	if rowsAfected != 1 {
-		err = fmt.Errorf("Weird  Behavior. Total Affected: %d", rowsAfected)
-		return
+	if rowsAffected != 1 {
+		return fmt.Errorf("unexpected number of affected rows: %d", rowsAffected)
 	}
 
-	return
+	return nil
+}
+
+// IncrementRetry counts an attempt against the code and returns the attempts made so far.
+// The check and the increment happen in one statement so concurrent attempts can not
+// exceed maxRetry. Returns domain.ErrNotFound when the code is gone or out of retries.
+func (m *PostgresOtpCommandRepository) IncrementRetry(ctx context.Context, id int64, maxRetry int) (retry int, err error) {
+	query := `UPDATE otp SET retry = retry + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND retry < $2 RETURNING retry`
+
+	err = m.Conn.QueryRowContext(ctx, query, id, maxRetry).Scan(&retry)
+	if err == sql.ErrNoRows {
+		return 0, domain.ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment otp retry: %w", err)
+	}
+
+	return retry, nil
+}
+
+// DeleteByUserID removes every OTP of the user so only the latest code is ever valid
+func (m *PostgresOtpCommandRepository) DeleteByUserID(ctx context.Context, userID int64) (err error) {
+	query := "DELETE FROM otp WHERE user_id = $1"
+
+	if _, err = m.Conn.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	return nil
 }
 
 func (m *PostgresOtpCommandRepository) Update(ctx context.Context, otp *domain.Otp) (err error) {
-	query := `UPDATE otp SET code=? , user_id=? , device_id=?, updated_at=? , created_at=? WHERE ID = ?`
+	query := `UPDATE otp SET code = $1, retry = $2, device_id = $3, expires_at = $4, updated_at = $5 WHERE id = $6`
 
 	stmt, err := m.Conn.PrepareContext(ctx, query)
 	if err != nil {
-		return
+		return fmt.Errorf("failed to prepare update query: %w", err)
 	}
+	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, otp.Code, otp.UserId, otp.DeviceId, otp.UpdatedAt, otp.CreatedAt)
+	res, err := stmt.ExecContext(ctx, otp.Code, otp.Retry, otp.DeviceId, otp.ExpiresAt, otp.UpdatedAt, otp.ID)
 	if err != nil {
-		return
+		return fmt.Errorf("failed to execute update query: %w", err)
 	}
+
 	affect, err := res.RowsAffected()
 	if err != nil {
-		return
+		return fmt.Errorf("failed to check affected rows: %w", err)
 	}
 	if affect != 1 {
-		err = fmt.Errorf("weird  Behavior. Total Affected: %d", affect)
-		return
+		return fmt.Errorf("unexpected number of affected rows: %d", affect)
 	}
 
-	return
+	return nil
 }
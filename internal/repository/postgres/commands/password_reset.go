@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+	"time"
+)
+
+type PostgresPasswordResetCommandRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresPasswordResetCommandRepository(conn *sql.DB) *PostgresPasswordResetCommandRepository {
+	return &PostgresPasswordResetCommandRepository{Conn: conn}
+}
+
+// Store inserts a new password reset token
+func (r *PostgresPasswordResetCommandRepository) Store(ctx context.Context, reset *domain.PasswordReset) (err error) {
+	query := `INSERT INTO password_reset (user_id, token_hash, expires_at, created_at) VALUES ($1, $2, $3, CURRENT_TIMESTAMP) RETURNING id, created_at`
+
+	stmt, err := r.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, reset.UserID, reset.TokenHash, reset.ExpiresAt).Scan(&reset.ID, &reset.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to execute statement: %w", err)
+	}
+
+	return nil
+}
+
+// Consume marks an unused, unexpired token as used and returns it. The check and
+// the update happen in one statement so a token can never be used twice.
+func (r *PostgresPasswordResetCommandRepository) Consume(ctx context.Context, tokenHash string) (res domain.PasswordReset, err error) {
+	query := `UPDATE password_reset SET used_at = CURRENT_TIMESTAMP
+			  WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+			  RETURNING id, user_id, token_hash, expires_at, used_at, created_at`
+
+	err = r.Conn.QueryRowContext(ctx, query, tokenHash).Scan(
+		&res.ID,
+		&res.UserID,
+		&res.TokenHash,
+		&res.ExpiresAt,
+		&res.UsedAt,
+		&res.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return domain.PasswordReset{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.PasswordReset{}, fmt.Errorf("failed to consume password reset: %w", err)
+	}
+
+	return res, nil
+}
+
+// InvalidateByUserID marks every unused token of the user as used
+func (r *PostgresPasswordResetCommandRepository) InvalidateByUserID(ctx context.Context, userID int64) (err error) {
+	query := `UPDATE password_reset SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND used_at IS NULL`
+
+	if _, err = r.Conn.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to invalidate password resets: %w", err)
+	}
+
+	return nil
+}
+
+// CountSince counts the reset tokens issued to the user since the given time
+func (r *PostgresPasswordResetCommandRepository) CountSince(ctx context.Context, userID int64, since time.Time) (count int64, err error) {
+	query := `SELECT COUNT(*) FROM password_reset WHERE user_id = $1 AND created_at >= $2`
+
+	if err = r.Conn.QueryRowContext(ctx, query, userID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count password resets: %w", err)
+	}
+
+	return count, nil
+}
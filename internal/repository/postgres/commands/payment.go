@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+
+	"github.com/lib/pq"
+)
+
+type PostgresPaymentCommandRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresPaymentCommandRepository(conn *sql.DB) *PostgresPaymentCommandRepository {
+	return &PostgresPaymentCommandRepository{Conn: conn}
+}
+
+// UpdateSession stores the provider session and hosted page of an unpaid payment
+func (r *PostgresPaymentCommandRepository) UpdateSession(ctx context.Context, id int64, sessionID string, url string) (err error) {
+	query := `UPDATE payment SET session_id = $1, url = $2 WHERE id = $3 AND status = $4`
+
+	result, err := r.Conn.ExecContext(ctx, query, sessionID, url, id, domain.PaymentStatusUnpaid)
+	if err != nil {
+		return fmt.Errorf("failed to update payment session: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// MarkChecked records that the provider was asked about an unpaid payment. It also moves
+// updated_at, so the payment is not asked about again before the reconcile delay passed.
+func (r *PostgresPaymentCommandRepository) MarkChecked(ctx context.Context, id int64) error {
+	query := `UPDATE payment SET checked_at = CURRENT_TIMESTAMP WHERE id = $1 AND status = $2`
+
+	if _, err := r.Conn.ExecContext(ctx, query, id, domain.PaymentStatusUnpaid); err != nil {
+		return fmt.Errorf("failed to mark payment checked: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateStatus settles a payment and moves the orders it pays for in one transaction.
+// Only unpaid payments change, except that a late "paid" overrides "failed" and
+// "expired" because the money was captured after all. Orders only move when the state
+// machine allows it and every move is recorded in their timeline. Money captured for
+// an order that was cancelled in the meantime, or for a failed order whose voucher
+// reached its limits since, marks the payment for a refund, reported by refund. Returns false when the payment was already settled, which makes repeated
+// webhooks harmless.
+func (r *PostgresPaymentCommandRepository) UpdateStatus(ctx context.Context, id int64, paymentStatus string, orderStatus domain.OrderStatus) (updated bool, refund bool, err error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx, `UPDATE payment SET status = $1
+		WHERE id = $2 AND (status = $3 OR ($1 = $4 AND status IN ($5, $6)))`,
+		paymentStatus, id, domain.PaymentStatusUnpaid, domain.PaymentStatusPaid, domain.PaymentStatusFailed, domain.PaymentStatusExpired)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to update payment status: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		err = tx.Rollback()
+		return false, false, err
+	}
+
+	// A late capture must not take a voucher past its limits, such orders stay failed
+	// and the payment is refunded
+	blocked := make([]int64, 0)
+	if paymentStatus == domain.PaymentStatusPaid {
+		if blocked, err = overVoucherLimit(ctx, tx, id); err != nil {
+			return false, false, err
+		}
+	}
+
+	placeholders, statuses := orderStatusPlaceholders(5, domain.OrderStatusesBefore(orderStatus))
+	if len(statuses) > 0 {
+		args := append([]interface{}{id, orderStatus, domain.OrderActorPaymentProvider, pq.Array(blocked)}, statuses...)
+		_, err = tx.ExecContext(ctx, `WITH previous AS (
+				SELECT id, status FROM transaction WHERE payment_id = $1 AND status IN (`+placeholders+`)
+				AND NOT (id = ANY($4::BIGINT[])) FOR UPDATE
+			), moved AS (
+				UPDATE transaction SET status = $2::VARCHAR FROM previous WHERE transaction.id = previous.id
+				RETURNING transaction.id, previous.status
+			)
+			INSERT INTO order_status_history (transaction_id, from_status, to_status, actor_type)
+			SELECT id, status, $2::VARCHAR, $3 FROM moved`, args...)
+		if err != nil {
+			return false, false, fmt.Errorf("failed to update order status: %w", err)
+		}
+	}
+
+	if paymentStatus == domain.PaymentStatusPaid {
+		err = tx.QueryRowContext(ctx, `UPDATE payment SET refund_required = TRUE
+			WHERE id = $1 AND (EXISTS (SELECT 1 FROM transaction WHERE payment_id = $1 AND status = $2) OR CARDINALITY($3::BIGINT[]) > 0)
+			RETURNING refund_required`, id, domain.OrderStatusCancelled, pq.Array(blocked)).Scan(&refund)
+		if err == sql.ErrNoRows {
+			err = nil
+		}
+		if err != nil {
+			return false, false, fmt.Errorf("failed to flag payment for refund: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, refund, nil
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+)
+
+type PostgresRoleCommandRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresRoleCommandRepository(conn *sql.DB) *PostgresRoleCommandRepository {
+	return &PostgresRoleCommandRepository{Conn: conn}
+}
+
+// AssignToUser grants a role to a user, assigning a role twice is a no-op
+func (r *PostgresRoleCommandRepository) AssignToUser(ctx context.Context, userID int64, roleID int64) (err error) {
+	query := `INSERT INTO user_roles (user_id, role_id, created_at) VALUES ($1, $2, CURRENT_TIMESTAMP)
+			  ON CONFLICT (user_id, role_id) DO NOTHING`
+
+	if _, err = r.Conn.ExecContext(ctx, query, userID, roleID); err != nil {
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeFromUser removes a role from a user
+func (r *PostgresRoleCommandRepository) RevokeFromUser(ctx context.Context, userID int64, roleID int64) (err error) {
+	query := `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`
+
+	if _, err = r.Conn.ExecContext(ctx, query, userID, roleID); err != nil {
+		return fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	return nil
+}
+
+// AssignIfUnheld grants the named role to the user only when nobody holds it yet. The
+// role row stays locked until the grant is stored, so concurrent calls can not both
+// find the role unheld. Reports whether the role was granted.
+func (r *PostgresRoleCommandRepository) AssignIfUnheld(ctx context.Context, userID int64, name string) (granted bool, err error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var roleID int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM roles WHERE name = $1 FOR UPDATE`, name).Scan(&roleID)
+	if err == sql.ErrNoRows {
+		err = domain.ErrNotFound
+		return false, err
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock role: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `INSERT INTO user_roles (user_id, role_id, created_at)
+		SELECT $1, $2, CURRENT_TIMESTAMP
+		WHERE NOT EXISTS (SELECT 1 FROM user_roles WHERE role_id = $2)`, userID, roleID)
+	if err != nil {
+		return false, fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return rows > 0, nil
+}
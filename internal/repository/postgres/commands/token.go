@@ -16,14 +16,14 @@ func NewPostgresTokenCommandRepository(Conn *sql.DB) *PostgresTokenCommandReposi
 }
 
 func (r *PostgresTokenCommandRepository) Store(ctx context.Context, token *domain.Token) (err error) {
-	query := `INSERT INTO token (refresh_token, user_id, created_at, updated_at) VALUES ($1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id`
+	query := `INSERT INTO token (refresh_token, user_id, device_id, family_id, expires_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP) RETURNING id`
 	stmt, err := r.Conn.PrepareContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to prepare query: %w", err)
 	}
 	defer stmt.Close()
 
-	err = stmt.QueryRowContext(ctx, token.RefreshToken, token.UserID).Scan(&token.ID)
+	err = stmt.QueryRowContext(ctx, token.RefreshToken, token.UserID, nullableID(token.DeviceID), token.FamilyID, token.ExpiresAt).Scan(&token.ID)
 	if err != nil {
 		return fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -57,14 +57,14 @@ func (m *PostgresTokenCommandRepository) Delete(ctx context.Context, id int64) (
 }
 
 func (m *PostgresTokenCommandRepository) Update(ctx context.Context, token *domain.Token) (err error) {
-	query := `UPDATE token SET refresh_token = $1, user_id = $2 WHERE id = $3`
+	query := `UPDATE token SET refresh_token = $1, user_id = $2, device_id = $3, family_id = $4, expires_at = $5 WHERE id = $6`
 	stmt, err := m.Conn.PrepareContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to prepare update query: %w", err)
 	}
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, token.RefreshToken, token.UserID, token.ID)
+	res, err := stmt.ExecContext(ctx, token.RefreshToken, token.UserID, nullableID(token.DeviceID), token.FamilyID, token.ExpiresAt, token.ID)
 	if err != nil {
 		return fmt.Errorf("failed to execute update query: %w", err)
 	}
@@ -80,3 +80,77 @@ func (m *PostgresTokenCommandRepository) Update(ctx context.Context, token *doma
 
 	return nil
 }
+
+// Rotate replaces the refresh token of a family only while it still holds previous.
+// Concurrent rotations with the same token can not both succeed, the one that finds
+// the token already replaced gets domain.ErrTokenReused.
+func (m *PostgresTokenCommandRepository) Rotate(ctx context.Context, token *domain.Token, previous string) (err error) {
+	query := `UPDATE token SET refresh_token = $1, user_id = $2, device_id = $3, family_id = $4, expires_at = $5
+			  WHERE id = $6 AND refresh_token = $7`
+
+	res, err := m.Conn.ExecContext(ctx, query, token.RefreshToken, token.UserID, nullableID(token.DeviceID), token.FamilyID, token.ExpiresAt, token.ID, previous)
+	if err != nil {
+		return fmt.Errorf("failed to execute rotate query: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrTokenReused
+	}
+
+	return nil
+}
+
+func (m *PostgresTokenCommandRepository) DeleteByUserID(ctx context.Context, userID int64) (err error) {
+	query := "DELETE FROM token WHERE user_id = $1"
+	stmt, err := m.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare delete query: %w", err)
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.ExecContext(ctx, userID); err != nil {
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	return nil
+}
+
+func (m *PostgresTokenCommandRepository) DeleteByDeviceID(ctx context.Context, deviceID int64) (err error) {
+	query := "DELETE FROM token WHERE device_id = $1"
+	stmt, err := m.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare delete query: %w", err)
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.ExecContext(ctx, deviceID); err != nil {
+		return fmt.Errorf("failed to execute delete query: %w", err)
+	}
+
+	return nil
+}
+
+// PruneDeviceless removes the expired sessions of the user that are not tied to a device
+// and all but the keep most recent ones
+func (m *PostgresTokenCommandRepository) PruneDeviceless(ctx context.Context, userID int64, keep int) (err error) {
+	query := `DELETE FROM token WHERE user_id = $1 AND device_id IS NULL AND (
+				  expires_at <= CURRENT_TIMESTAMP
+				  OR id NOT IN (SELECT id FROM token WHERE user_id = $1 AND device_id IS NULL ORDER BY created_at DESC, id DESC LIMIT $2)
+			  )`
+
+	if _, err = m.Conn.ExecContext(ctx, query, userID, keep); err != nil {
+		return fmt.Errorf("failed to prune sessions without a device: %w", err)
+	}
+
+	return nil
+}
+
+// nullableID stores a zero foreign key as NULL
+func nullableID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
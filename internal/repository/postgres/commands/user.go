@@ -33,8 +33,8 @@ func (r *PostgresUserCommandRepository) Store(ctx context.Context, user *domain.
 	return nil
 }
 
-func (r *PostgresUserCommandRepository) UpdatePhoneVerifiedAt(ctx context.Context, id int64, emailVerifiedAt time.Time) error {
-	query := `UPDATE users SET email_verified_at=$1 WHERE id=$2`
+func (r *PostgresUserCommandRepository) UpdatePhoneVerifiedAt(ctx context.Context, id int64, phone string, phoneVerifiedAt time.Time) error {
+	query := `UPDATE users SET phone=$1, phone_verified_at=$2 WHERE id=$3`
 
 	stmt, err := r.Conn.PrepareContext(ctx, query)
 	if err != nil {
@@ -42,7 +42,7 @@ func (r *PostgresUserCommandRepository) UpdatePhoneVerifiedAt(ctx context.Contex
 	}
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, emailVerifiedAt, id)
+	res, err := stmt.ExecContext(ctx, phone, phoneVerifiedAt, id)
 	if err != nil {
 		return fmt.Errorf("failed to execute statement: %w", err)
 	}
@@ -57,3 +57,103 @@ func (r *PostgresUserCommandRepository) UpdatePhoneVerifiedAt(ctx context.Contex
 
 	return nil
 }
+
+func (r *PostgresUserCommandRepository) UpdateEmailVerifiedAt(ctx context.Context, id int64, emailVerifiedAt time.Time) error {
+	return r.updateTimestamp(ctx, `UPDATE users SET email_verified_at=$1 WHERE id=$2`, id, emailVerifiedAt)
+}
+
+func (r *PostgresUserCommandRepository) UpdateEmailVerificationSentAt(ctx context.Context, id int64, sentAt time.Time) error {
+	return r.updateTimestamp(ctx, `UPDATE users SET email_verification_sent_at=$1 WHERE id=$2`, id, sentAt)
+}
+
+func (r *PostgresUserCommandRepository) UpdatePassword(ctx context.Context, id int64, password string) error {
+	query := `UPDATE users SET password=$1 WHERE id=$2`
+
+	stmt, err := r.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, password, id)
+	if err != nil {
+		return fmt.Errorf("failed to execute statement: %w", err)
+	}
+
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affect != 1 {
+		return fmt.Errorf("unexpected number of affected rows: %d", affect)
+	}
+
+	return nil
+}
+
+// UpdateProfile stores the editable profile fields of the user
+func (r *PostgresUserCommandRepository) UpdateProfile(ctx context.Context, user domain.User) error {
+	query := `UPDATE users SET full_name=$1, phone=NULLIF($2, ''), phone_verified_at=$3, avatar_url=NULLIF($4, '') WHERE id=$5`
+
+	return r.exec(ctx, query, user.FullName, user.Phone, user.PhoneVerifiedAt, user.AvatarURL, user.ID)
+}
+
+// UpdatePendingEmail stores the address an email change waits to verify
+func (r *PostgresUserCommandRepository) UpdatePendingEmail(ctx context.Context, id int64, email string) error {
+	return r.exec(ctx, `UPDATE users SET pending_email=$1 WHERE id=$2`, email, id)
+}
+
+// ConfirmPendingEmail replaces the email with the pending one and marks it verified.
+// It only matches while the pending address is still the one the link was sent to.
+func (r *PostgresUserCommandRepository) ConfirmPendingEmail(ctx context.Context, id int64, email string, verifiedAt time.Time) error {
+	query := `UPDATE users SET email=pending_email, pending_email=NULL, email_verified_at=$1 WHERE id=$2 AND pending_email=$3`
+
+	res, err := r.Conn.ExecContext(ctx, query, verifiedAt, id, email)
+	if err != nil {
+		return fmt.Errorf("failed to execute statement: %w", err)
+	}
+
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affect == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// UpdateDeletionScheduledAt schedules the account deletion, a nil time cancels it
+func (r *PostgresUserCommandRepository) UpdateDeletionScheduledAt(ctx context.Context, id int64, scheduledAt *time.Time) error {
+	return r.exec(ctx, `UPDATE users SET deletion_scheduled_at=$1 WHERE id=$2`, scheduledAt, id)
+}
+
+// exec runs a single-row update
+func (r *PostgresUserCommandRepository) exec(ctx context.Context, query string, args ...interface{}) error {
+	stmt, err := r.Conn.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("failed to execute statement: %w", err)
+	}
+
+	affect, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affect != 1 {
+		return fmt.Errorf("unexpected number of affected rows: %d", affect)
+	}
+
+	return nil
+}
+
+// updateTimestamp runs a single-row update setting one timestamp column of a user
+func (r *PostgresUserCommandRepository) updateTimestamp(ctx context.Context, query string, id int64, value time.Time) error {
+	return r.exec(ctx, query, value, id)
+}
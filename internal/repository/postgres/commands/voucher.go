@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"santapan/domain"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// foreignKeyViolation is the Postgres error code of a reference to a missing row
+const foreignKeyViolation = "23503"
+
+type PostgresVoucherCommandRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresVoucherCommandRepository(conn *sql.DB) *PostgresVoucherCommandRepository {
+	return &PostgresVoucherCommandRepository{Conn: conn}
+}
+
+// Store creates a voucher with its eligibility rules. Returns domain.ErrConflict when
+// the code is taken and domain.ErrBadParamInput for an unknown category or menu.
+func (r *PostgresVoucherCommandRepository) Store(ctx context.Context, voucher *domain.Voucher) (err error) {
+	tx, err := r.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO voucher (code, description, discount_type, discount_amount, discount_percent, min_spend, max_discount,
+			starts_at, ends_at, usage_limit, usage_limit_per_user, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (code) DO NOTHING
+		RETURNING id, created_at, updated_at`,
+		voucher.Code, voucher.Description, voucher.DiscountType, voucher.DiscountAmount, voucher.DiscountPercent, voucher.MinSpend, voucher.MaxDiscount,
+		voucher.StartsAt, voucher.EndsAt, voucher.UsageLimit, voucher.UsageLimitPerUser, voucher.Active,
+	).Scan(&voucher.ID, &voucher.CreatedAt, &voucher.UpdatedAt)
+	if err == sql.ErrNoRows {
+		err = domain.ErrConflict
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create voucher: %w", err)
+	}
+
+	const insertEligibility = `INSERT INTO voucher_eligibility (voucher_id, category_id, menu_id, bundling_type) VALUES ($1, $2, $3, $4)`
+	for _, categoryID := range voucher.CategoryIDs {
+		if _, err = tx.ExecContext(ctx, insertEligibility, voucher.ID, categoryID, nil, nil); err != nil {
+			return eligibilityError(err)
+		}
+	}
+	for _, menuID := range voucher.MenuIDs {
+		if _, err = tx.ExecContext(ctx, insertEligibility, voucher.ID, nil, menuID, nil); err != nil {
+			return eligibilityError(err)
+		}
+	}
+	for _, bundlingType := range voucher.BundlingTypes {
+		if _, err = tx.ExecContext(ctx, insertEligibility, voucher.ID, nil, nil, bundlingType); err != nil {
+			return eligibilityError(err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// eligibilityError reports a rule referring to a missing category or menu as a bad parameter
+func eligibilityError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+		return domain.ErrBadParamInput
+	}
+	return fmt.Errorf("failed to store voucher eligibility: %w", err)
+}
+
+// Deactivate stops a voucher from being redeemed, past redemptions are kept
+func (r *PostgresVoucherCommandRepository) Deactivate(ctx context.Context, id int64) error {
+	result, err := r.Conn.ExecContext(ctx, `UPDATE voucher SET active = FALSE WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate voucher: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// redeemVoucher records the redemption of a voucher on an order. The voucher row is
+// locked so concurrent checkouts can not exceed its usage limits, which are checked
+// again here because they may have been reached since the voucher was quoted.
+func redeemVoucher(ctx context.Context, tx *sql.Tx, redemption *domain.VoucherRedemption, now time.Time) error {
+	var (
+		active                        bool
+		startsAt                      time.Time
+		endsAt                        sql.NullTime
+		usageLimit, usageLimitPerUser sql.NullInt64
+	)
+	err := tx.QueryRowContext(ctx, `SELECT active, starts_at, ends_at, usage_limit, usage_limit_per_user FROM voucher WHERE id = $1 FOR UPDATE`,
+		redemption.VoucherID).Scan(&active, &startsAt, &endsAt, &usageLimit, &usageLimitPerUser)
+	if err == sql.ErrNoRows {
+		return domain.ErrVoucherUnavailable
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock voucher: %w", err)
+	}
+	if !active || now.Before(startsAt) || (endsAt.Valid && !now.Before(endsAt.Time)) {
+		return domain.ErrVoucherUnavailable
+	}
+
+	reached, err := voucherUsageReached(ctx, tx, redemption.VoucherID, redemption.UserID, usageLimit, usageLimitPerUser)
+	if err != nil {
+		return err
+	}
+	if reached {
+		return domain.ErrVoucherLimitReached
+	}
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO voucher_redemption (voucher_id, user_id, transaction_id, discount)
+		VALUES ($1, $2, $3, $4) RETURNING id, created_at`,
+		redemption.VoucherID, redemption.UserID, redemption.TransactionID, redemption.Discount,
+	).Scan(&redemption.ID, &redemption.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to redeem voucher: %w", err)
+	}
+
+	return nil
+}
+
+// voucherUsageReached reports whether the voucher was redeemed as often as its limits
+// allow, overall or by the user. Orders that were cancelled or failed do not count.
+// The voucher row must be locked by the caller.
+func voucherUsageReached(ctx context.Context, tx *sql.Tx, voucherID int64, userID int64, usageLimit, usageLimitPerUser sql.NullInt64) (bool, error) {
+	var total, byUser int64
+	err := tx.QueryRowContext(ctx, `SELECT COUNT(*), COUNT(*) FILTER (WHERE r.user_id = $2)
+		FROM voucher_redemption r JOIN transaction t ON t.id = r.transaction_id
+		WHERE r.voucher_id = $1 AND t.status NOT IN ($3, $4)`,
+		voucherID, userID, domain.OrderStatusCancelled, domain.OrderStatusFailed).Scan(&total, &byUser)
+	if err != nil {
+		return false, fmt.Errorf("failed to count voucher redemptions: %w", err)
+	}
+
+	return (usageLimit.Valid && total >= usageLimit.Int64) || (usageLimitPerUser.Valid && byUser >= usageLimitPerUser.Int64), nil
+}
+
+// overVoucherLimit returns the failed orders of the payment whose voucher reached its
+// usage limits while they were failed, so a late capture can not redeem it once more.
+// The vouchers are locked until the transaction ends.
+func overVoucherLimit(ctx context.Context, tx *sql.Tx, paymentID int64) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT t.id, t.user_id, v.id, v.usage_limit, v.usage_limit_per_user
+		FROM transaction t JOIN voucher v ON v.id = t.voucher_id
+		WHERE t.payment_id = $1 AND t.status = $2
+		ORDER BY v.id FOR UPDATE OF v`, paymentID, domain.OrderStatusFailed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vouchers of failed orders: %w", err)
+	}
+
+	type failedOrder struct {
+		id, voucherID                 int64
+		userID                        sql.NullInt64
+		usageLimit, usageLimitPerUser sql.NullInt64
+	}
+	var orders []failedOrder
+	for rows.Next() {
+		var order failedOrder
+		if err = rows.Scan(&order.id, &order.userID, &order.voucherID, &order.usageLimit, &order.usageLimitPerUser); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan voucher of failed order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get vouchers of failed orders: %w", err)
+	}
+
+	blocked := make([]int64, 0)
+	for _, order := range orders {
+		reached, err := voucherUsageReached(ctx, tx, order.voucherID, order.userID.Int64, order.usageLimit, order.usageLimitPerUser)
+		if err != nil {
+			return nil, err
+		}
+		if reached {
+			blocked = append(blocked, order.id)
+		}
+	}
+
+	return blocked, nil
+}
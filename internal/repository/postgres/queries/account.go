@@ -0,0 +1,100 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// exportSections lists every table holding personal data, each query takes the user ID as $1.
+// Credentials (password hashes, refresh tokens, OTP codes, TOTP secrets, recovery codes, magic link tokens) are deliberately left out.
+var exportSections = []struct {
+	name  string
+	query string
+}{
+	{"profile", `SELECT id, full_name, email, pending_email, email_verified_at, phone, phone_verified_at, avatar_url,
+	             deletion_scheduled_at, created_at, updated_at
+	             FROM users WHERE id = $1`},
+	{"identities", `SELECT provider, email, created_at FROM user_identity WHERE user_id = $1`},
+	{"mfa", `SELECT enabled_at, created_at, updated_at FROM user_mfa WHERE user_id = $1`},
+	{"mfa_recovery_codes", `SELECT id, used_at, created_at FROM mfa_recovery_code WHERE user_id = $1`},
+	{"magic_links", `SELECT id, expires_at, used_at, created_at FROM magic_link WHERE user_id = $1`},
+	{"roles", `SELECT r.name, ur.created_at FROM user_roles ur JOIN roles r ON r.id = ur.role_id WHERE ur.user_id = $1`},
+	{"addresses", `SELECT id, label, address, name, notes, phone, created_at, updated_at FROM address WHERE user_id = $1`},
+	{"health_conditions", `SELECT diabetes, gerd, asam_urat, kolestrol, rendah_karbohidrat, tinggi_protein, vegetarian,
+	                       rendah_gula, rendah_kalori, created_at, updated_at
+	                       FROM user_condition WHERE user_id = $1`},
+	{"devices", `SELECT id, name, brand, model, ip_address, last_seen_at, created_at, updated_at, deleted_at FROM devices WHERE user_id = $1`},
+	{"sessions", `SELECT id, device_id, created_at, updated_at, expires_at FROM token WHERE user_id = $1`},
+	{"carts", `SELECT c.id, c.total_price, c.status, c.created_at, c.updated_at,
+	           COALESCE((SELECT json_agg(ci ORDER BY ci.id) FROM (
+	               SELECT id, menu_id, bundling_id, name, quantity, price, created_at FROM cart_item WHERE cart_id = c.id
+	           ) ci), '[]') AS items
+	           FROM cart c WHERE c.user_id = $1`},
+	{"payments", `SELECT id, reference_id, amount, status, created_at, updated_at FROM payment WHERE user_id = $1`},
+	{"transactions", `SELECT id, cart_id, payment_id, courier_id, address_id, discount, courier_fee, service_fee, tax, amount, status,
+	                  created_at, updated_at FROM transaction WHERE user_id = $1`},
+	{"voucher_redemptions", `SELECT r.id, v.code, r.transaction_id, r.discount, r.created_at
+	                         FROM voucher_redemption r JOIN voucher v ON v.id = r.voucher_id WHERE r.user_id = $1`},
+	{"invoices", `SELECT id, number, transaction_id, customer_name, customer_email, delivery_address, lines, items_total, discount,
+	              courier_fee, service_fee, tax, total, ordered_at, issued_at FROM invoice WHERE user_id = $1`},
+}
+
+type PostgresAccountQueryRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresAccountQueryRepository(conn *sql.DB) *PostgresAccountQueryRepository {
+	return &PostgresAccountQueryRepository{conn}
+}
+
+// GetDueForDeletion retrieves the IDs of accounts whose deletion grace period has ended
+func (m *PostgresAccountQueryRepository) GetDueForDeletion(ctx context.Context, now time.Time) (result []int64, err error) {
+	query := `SELECT id FROM users WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= $1 AND deleted_at IS NULL`
+
+	rows, err := m.Conn.QueryContext(ctx, query, now)
+	if err != nil {
+		logrus.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logrus.Error(errRow)
+		}
+	}()
+
+	result = make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			logrus.Error(err)
+			return nil, err
+		}
+		result = append(result, id)
+	}
+
+	return result, nil
+}
+
+// Export collects everything stored about the user, one JSON array per section
+func (m *PostgresAccountQueryRepository) Export(ctx context.Context, userID int64) (map[string]json.RawMessage, error) {
+	result := make(map[string]json.RawMessage, len(exportSections))
+
+	for _, section := range exportSections {
+		var data []byte
+		query := fmt.Sprintf(`SELECT COALESCE(json_agg(t), '[]') FROM (%s) t`, section.query)
+
+		if err := m.Conn.QueryRowContext(ctx, query, userID).Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", section.name, err)
+		}
+		result[section.name] = data
+	}
+
+	return result, nil
+}
@@ -0,0 +1,92 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+)
+
+type PostgresCartQueryRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresCartQueryRepository(conn *sql.DB) *PostgresCartQueryRepository {
+	return &PostgresCartQueryRepository{conn}
+}
+
+// GetActiveByUserID retrieves the active cart of the user with its items
+func (m *PostgresCartQueryRepository) GetActiveByUserID(ctx context.Context, userID int64) (domain.Cart, error) {
+	query := `SELECT id, user_id, total_price, status, created_at, updated_at FROM cart WHERE user_id = $1 AND status = $2`
+
+	return m.get(ctx, query, userID, domain.CartStatusActive)
+}
+
+// GetByID retrieves a cart with its items
+func (m *PostgresCartQueryRepository) GetByID(ctx context.Context, id int64) (domain.Cart, error) {
+	query := `SELECT id, user_id, total_price, status, created_at, updated_at FROM cart WHERE id = $1`
+
+	return m.get(ctx, query, id)
+}
+
+func (m *PostgresCartQueryRepository) get(ctx context.Context, query string, args ...interface{}) (res domain.Cart, err error) {
+	err = m.Conn.QueryRowContext(ctx, query, args...).Scan(
+		&res.ID,
+		&res.UserID,
+		&res.TotalPrice,
+		&res.Status,
+		&res.CreatedAt,
+		&res.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return domain.Cart{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.Cart{}, fmt.Errorf("failed to get cart: %w", err)
+	}
+
+	res.Items, err = m.fetchItems(ctx, res.ID)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+
+	return res, nil
+}
+
+func (m *PostgresCartQueryRepository) fetchItems(ctx context.Context, cartID int64) (res []domain.CartItem, err error) {
+	query := `SELECT id, cart_id, menu_id, bundling_id, COALESCE(image_url, ''), name, quantity, price, created_at, updated_at
+			  FROM cart_item WHERE cart_id = $1 ORDER BY id`
+
+	rows, err := m.Conn.QueryContext(ctx, query, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch cart items: %w", err)
+	}
+	defer rows.Close()
+
+	res = make([]domain.CartItem, 0)
+	for rows.Next() {
+		var item domain.CartItem
+		if err = rows.Scan(
+			&item.ID,
+			&item.CartID,
+			&item.MenuID,
+			&item.BundlingID,
+			&item.ImageURL,
+			&item.Name,
+			&item.Quantity,
+			&item.Price,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan cart item: %w", err)
+		}
+		item.Subtotal = item.Price * domain.Money(item.Quantity)
+		res = append(res, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch cart items: %w", err)
+	}
+
+	return res, nil
+}
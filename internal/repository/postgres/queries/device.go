@@ -31,15 +31,18 @@ func (m *PostgresDeviceQueryRepository) fetch(ctx context.Context, query string,
 		}
 	}()
 
+	result = make([]domain.Device, 0)
 	for rows.Next() {
 		t := domain.Device{}
 		err = rows.Scan(
 			&t.ID,
 			&t.Name,
 			&t.Brand,
+			&t.Model,
 			&t.UniqueID,
 			&t.UserID,
 			&t.IPAddress,
+			&t.LastSeenAt,
 			&t.CreatedAt,
 			&t.UpdatedAt,
 			&t.DeletedAt,
@@ -55,11 +58,12 @@ func (m *PostgresDeviceQueryRepository) fetch(ctx context.Context, query string,
 	return result, nil
 }
 
-// GetByUserID retrieves a single device associated with a specific user ID
+// GetByUserID retrieves the most recently seen device associated with a specific user ID
 func (m *PostgresDeviceQueryRepository) GetByUserID(ctx context.Context, userID int64) (result domain.Device, err error) {
-	query := `SELECT id, name, brand, unique_id, user_id, ip_address, created_at, updated_at, deleted_at
+	query := `SELECT id, name, brand, model, unique_id, user_id, ip_address, last_seen_at, created_at, updated_at, deleted_at
               FROM devices 
-              WHERE user_id = $1
+              WHERE user_id = $1 AND deleted_at IS NULL
+              ORDER BY last_seen_at DESC
               LIMIT 1`
 
 	devices, err := m.fetch(ctx, query, userID)
@@ -74,3 +78,51 @@ func (m *PostgresDeviceQueryRepository) GetByUserID(ctx context.Context, userID
 
 	return result, nil // No device found, return zero value
 }
+
+// FetchByUserID retrieves every device of a user that still holds a session, most recently seen first
+func (m *PostgresDeviceQueryRepository) FetchByUserID(ctx context.Context, userID int64) ([]domain.Device, error) {
+	query := `SELECT d.id, d.name, d.brand, d.model, d.unique_id, d.user_id, d.ip_address, d.last_seen_at, d.created_at, d.updated_at, d.deleted_at
+              FROM devices d
+              WHERE d.user_id = $1 AND d.deleted_at IS NULL
+                AND EXISTS (SELECT 1 FROM token t WHERE t.device_id = d.id)
+              ORDER BY d.last_seen_at DESC`
+
+	return m.fetch(ctx, query, userID)
+}
+
+// GetByID retrieves a signed in device by its ID
+func (m *PostgresDeviceQueryRepository) GetByID(ctx context.Context, id int64) (domain.Device, error) {
+	query := `SELECT id, name, brand, model, unique_id, user_id, ip_address, last_seen_at, created_at, updated_at, deleted_at
+              FROM devices 
+              WHERE id = $1 AND deleted_at IS NULL`
+
+	devices, err := m.fetch(ctx, query, id)
+	if err != nil {
+		return domain.Device{}, err
+	}
+
+	if len(devices) == 0 {
+		return domain.Device{}, domain.ErrNotFound
+	}
+
+	return devices[0], nil
+}
+
+// GetByUniqueID retrieves a device of the user by the identifier sent in the deviceid header,
+// including devices that were signed out so they can be registered again
+func (m *PostgresDeviceQueryRepository) GetByUniqueID(ctx context.Context, userID int64, uniqueID string) (domain.Device, error) {
+	query := `SELECT id, name, brand, model, unique_id, user_id, ip_address, last_seen_at, created_at, updated_at, deleted_at
+              FROM devices 
+              WHERE user_id = $1 AND unique_id = $2`
+
+	devices, err := m.fetch(ctx, query, userID, uniqueID)
+	if err != nil {
+		return domain.Device{}, err
+	}
+
+	if len(devices) == 0 {
+		return domain.Device{}, domain.ErrNotFound
+	}
+
+	return devices[0], nil
+}
@@ -0,0 +1,71 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+)
+
+type PostgresIdentityQueryRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresIdentityQueryRepository(conn *sql.DB) *PostgresIdentityQueryRepository {
+	return &PostgresIdentityQueryRepository{conn}
+}
+
+// GetByProviderSubject retrieves the identity of a provider account
+func (m *PostgresIdentityQueryRepository) GetByProviderSubject(ctx context.Context, provider string, subject string) (res domain.UserIdentity, err error) {
+	query := `SELECT id, user_id, provider, subject, email, created_at FROM user_identity WHERE provider = $1 AND subject = $2`
+
+	err = m.Conn.QueryRowContext(ctx, query, provider, subject).Scan(
+		&res.ID,
+		&res.UserID,
+		&res.Provider,
+		&res.Subject,
+		&res.Email,
+		&res.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return domain.UserIdentity{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.UserIdentity{}, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	return res, nil
+}
+
+// FetchByUserID retrieves every identity linked to the user
+func (m *PostgresIdentityQueryRepository) FetchByUserID(ctx context.Context, userID int64) (res []domain.UserIdentity, err error) {
+	query := `SELECT id, user_id, provider, subject, email, created_at FROM user_identity WHERE user_id = $1 ORDER BY id`
+
+	rows, err := m.Conn.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch identities: %w", err)
+	}
+	defer rows.Close()
+
+	res = make([]domain.UserIdentity, 0)
+	for rows.Next() {
+		var identity domain.UserIdentity
+		if err = rows.Scan(
+			&identity.ID,
+			&identity.UserID,
+			&identity.Provider,
+			&identity.Subject,
+			&identity.Email,
+			&identity.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan identity: %w", err)
+		}
+		res = append(res, identity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch identities: %w", err)
+	}
+
+	return res, nil
+}
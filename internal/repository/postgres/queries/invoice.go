@@ -0,0 +1,63 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"santapan/domain"
+)
+
+type PostgresInvoiceQueryRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresInvoiceQueryRepository(conn *sql.DB) *PostgresInvoiceQueryRepository {
+	return &PostgresInvoiceQueryRepository{conn}
+}
+
+// GetByTransactionID retrieves the invoice of an order
+func (m *PostgresInvoiceQueryRepository) GetByTransactionID(ctx context.Context, transactionID int64) (res domain.Invoice, err error) {
+	query := `SELECT id, number, COALESCE(transaction_id, 0), COALESCE(user_id, 0), customer_name, customer_email, recipient_name,
+			  recipient_phone, delivery_address, courier, payment_method, lines, items_total, discount, courier_fee, service_fee,
+			  service_fee_rate, tax, tax_rate, total, ordered_at, issued_at
+			  FROM invoice WHERE transaction_id = $1`
+
+	var lines []byte
+	err = m.Conn.QueryRowContext(ctx, query, transactionID).Scan(
+		&res.ID,
+		&res.Number,
+		&res.TransactionID,
+		&res.UserID,
+		&res.CustomerName,
+		&res.CustomerEmail,
+		&res.RecipientName,
+		&res.RecipientPhone,
+		&res.DeliveryAddress,
+		&res.Courier,
+		&res.PaymentMethod,
+		&lines,
+		&res.ItemsTotal,
+		&res.Discount,
+		&res.CourierFee,
+		&res.ServiceFee,
+		&res.ServiceFeeRate,
+		&res.Tax,
+		&res.TaxRate,
+		&res.Total,
+		&res.OrderedAt,
+		&res.IssuedAt,
+	)
+	if err == sql.ErrNoRows {
+		return domain.Invoice{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.Invoice{}, fmt.Errorf("failed to get invoice: %w", err)
+	}
+
+	if err = json.Unmarshal(lines, &res.Lines); err != nil {
+		return domain.Invoice{}, fmt.Errorf("failed to decode invoice lines: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,38 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+)
+
+type PostgresMfaQueryRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresMfaQueryRepository(conn *sql.DB) *PostgresMfaQueryRepository {
+	return &PostgresMfaQueryRepository{conn}
+}
+
+// GetByUserID retrieves the second factor of a user
+func (m *PostgresMfaQueryRepository) GetByUserID(ctx context.Context, userID int64) (res domain.UserMfa, err error) {
+	query := `SELECT user_id, secret, enabled_at, last_used_step, created_at, updated_at FROM user_mfa WHERE user_id = $1`
+
+	err = m.Conn.QueryRowContext(ctx, query, userID).Scan(
+		&res.UserID,
+		&res.Secret,
+		&res.EnabledAt,
+		&res.LastUsedStep,
+		&res.CreatedAt,
+		&res.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return domain.UserMfa{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.UserMfa{}, fmt.Errorf("failed to get mfa: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,180 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+	"santapan/internal/repository"
+	"strings"
+)
+
+type PostgresOrderQueryRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresOrderQueryRepository(conn *sql.DB) *PostgresOrderQueryRepository {
+	return &PostgresOrderQueryRepository{conn}
+}
+
+const orderColumns = `id, COALESCE(user_id, 0), COALESCE(cart_id, 0), COALESCE(payment_id, 0), COALESCE(courier_id, 0),
+	COALESCE(address_id, 0), voucher_id, discount, courier_fee, service_fee, service_fee_rate, tax, tax_rate, amount,
+	status, created_at, updated_at`
+
+// GetByID retrieves an order
+func (m *PostgresOrderQueryRepository) GetByID(ctx context.Context, id int64) (res domain.Transaction, err error) {
+	query := `SELECT ` + orderColumns + ` FROM transaction WHERE id = $1`
+
+	res, err = scanOrder(m.Conn.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return domain.Transaction{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	return res, nil
+}
+
+// Fetch retrieves the orders of a user, newest first, with ID-based pagination
+func (m *PostgresOrderQueryRepository) Fetch(ctx context.Context, userID int64, filter domain.OrderFilter, cursor string, num int64) (res []domain.Transaction, nextCursor string, err error) {
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{userID}
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if cursor != "" {
+		decodedCursor, err := repository.DecodeCursor(cursor)
+		if err != nil {
+			return nil, "", domain.ErrBadParamInput
+		}
+		where("id < $%d", decodedCursor)
+	}
+	if len(filter.Statuses) > 0 {
+		placeholders := make([]string, 0, len(filter.Statuses))
+		for _, status := range filter.Statuses {
+			args = append(args, status)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		conditions = append(conditions, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if !filter.From.IsZero() {
+		where("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at < $%d", filter.To)
+	}
+
+	args = append(args, num)
+	query := fmt.Sprintf(`SELECT `+orderColumns+` FROM transaction WHERE %s ORDER BY id DESC LIMIT $%d`,
+		strings.Join(conditions, " AND "), len(args))
+
+	rows, err := m.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch orders: %w", err)
+	}
+	defer rows.Close()
+
+	res = make([]domain.Transaction, 0)
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to scan order: %w", err)
+		}
+		res = append(res, order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("failed to fetch orders: %w", err)
+	}
+
+	// Set the nextCursor if the result count reaches the limit
+	if len(res) == int(num) {
+		nextCursor, err = repository.EncodeCursor(res[len(res)-1].ID)
+		if err != nil {
+			return res, "", err
+		}
+	}
+
+	return res, nextCursor, nil
+}
+
+// FetchHistory retrieves the status changes of an order, oldest first
+func (m *PostgresOrderQueryRepository) FetchHistory(ctx context.Context, transactionID int64) (res []domain.OrderStatusHistory, err error) {
+	query := `SELECT id, transaction_id, from_status, to_status, actor_type, actor_id, note, created_at
+			  FROM order_status_history WHERE transaction_id = $1 ORDER BY created_at, id`
+
+	rows, err := m.Conn.QueryContext(ctx, query, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch order history: %w", err)
+	}
+	defer rows.Close()
+
+	res = make([]domain.OrderStatusHistory, 0)
+	for rows.Next() {
+		var (
+			entry      domain.OrderStatusHistory
+			fromStatus sql.NullString
+			actorID    sql.NullInt64
+		)
+		if err = rows.Scan(
+			&entry.ID,
+			&entry.TransactionID,
+			&fromStatus,
+			&entry.ToStatus,
+			&entry.ActorType,
+			&actorID,
+			&entry.Note,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan order history: %w", err)
+		}
+		if fromStatus.Valid {
+			status := domain.OrderStatus(fromStatus.String)
+			entry.FromStatus = &status
+		}
+		if actorID.Valid {
+			entry.ActorID = &actorID.Int64
+		}
+		res = append(res, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch order history: %w", err)
+	}
+
+	return res, nil
+}
+
+type orderScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOrder(row orderScanner) (res domain.Transaction, err error) {
+	var voucherID sql.NullInt64
+	err = row.Scan(
+		&res.ID,
+		&res.UserID,
+		&res.CartID,
+		&res.PaymentID,
+		&res.CourierID,
+		&res.AddressID,
+		&voucherID,
+		&res.Discount,
+		&res.CourierFee,
+		&res.ServiceFee,
+		&res.ServiceFeeRate,
+		&res.Tax,
+		&res.TaxRate,
+		&res.Amount,
+		&res.Status,
+		&res.CreatedAt,
+		&res.UpdatedAt,
+	)
+	if voucherID.Valid {
+		res.VoucherID = &voucherID.Int64
+	}
+	return res, err
+}
@@ -35,10 +35,13 @@ func (m *PostgresOtpQueryRepository) fetch(ctx context.Context, query string, ar
 		t := domain.Otp{}
 		err = rows.Scan(
 			&t.ID,
+			&t.UserId,
+			&t.Phone,
 			&t.Code,
+			&t.Type,
 			&t.Retry,
-			&t.UserId,
 			&t.DeviceId,
+			&t.ExpiresAt,
 			&t.UpdatedAt,
 			&t.CreatedAt,
 		)
@@ -54,7 +57,8 @@ func (m *PostgresOtpQueryRepository) fetch(ctx context.Context, query string, ar
 }
 
 func (m *PostgresOtpQueryRepository) GetByID(ctx context.Context, id int64) (res domain.Otp, err error) {
-	query := `SELECT * FROM otp WHERE ID = ?`
+	query := `SELECT id, user_id, phone, code, type, retry, device_id, expires_at, updated_at, created_at
+			  FROM otp WHERE id = $1`
 
 	list, err := m.fetch(ctx, query, id)
 	if err != nil {
@@ -70,8 +74,10 @@ func (m *PostgresOtpQueryRepository) GetByID(ctx context.Context, id int64) (res
 	return
 }
 
+// GetByUserID retrieves the latest OTP issued to the user
 func (m *PostgresOtpQueryRepository) GetByUserID(ctx context.Context, id int64) (res domain.Otp, err error) {
-	query := `SELECT * FROM otp WHERE user_id = ?`
+	query := `SELECT id, user_id, phone, code, type, retry, device_id, expires_at, updated_at, created_at
+			  FROM otp WHERE user_id = $1 ORDER BY id DESC LIMIT 1`
 
 	list, err := m.fetch(ctx, query, id)
 	if err != nil {
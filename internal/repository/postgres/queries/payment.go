@@ -0,0 +1,96 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+	"time"
+)
+
+type PostgresPaymentQueryRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresPaymentQueryRepository(conn *sql.DB) *PostgresPaymentQueryRepository {
+	return &PostgresPaymentQueryRepository{conn}
+}
+
+const paymentColumns = `id, reference_id, session_id, user_id, amount, status, url, refund_required, created_at, updated_at`
+
+// GetByID retrieves a payment
+func (m *PostgresPaymentQueryRepository) GetByID(ctx context.Context, id int64) (domain.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payment WHERE id = $1`
+
+	return m.get(ctx, query, id)
+}
+
+// GetBySessionID retrieves the payment of a provider session
+func (m *PostgresPaymentQueryRepository) GetBySessionID(ctx context.Context, sessionID string) (domain.Payment, error) {
+	query := `SELECT ` + paymentColumns + ` FROM payment WHERE session_id = $1 AND session_id <> ''`
+
+	return m.get(ctx, query, sessionID)
+}
+
+// FetchPending retrieves unpaid payments with a provider session untouched since the given
+// time, the ones never or least recently checked first
+func (m *PostgresPaymentQueryRepository) FetchPending(ctx context.Context, before time.Time, limit int) (res []domain.Payment, err error) {
+	query := `SELECT ` + paymentColumns + ` FROM payment
+			  WHERE status = $1 AND session_id <> '' AND updated_at < $2
+			  ORDER BY checked_at NULLS FIRST, updated_at LIMIT $3`
+
+	rows, err := m.Conn.QueryContext(ctx, query, domain.PaymentStatusUnpaid, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pending payments: %w", err)
+	}
+	defer rows.Close()
+
+	res = make([]domain.Payment, 0)
+	for rows.Next() {
+		payment, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, payment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch pending payments: %w", err)
+	}
+
+	return res, nil
+}
+
+func (m *PostgresPaymentQueryRepository) get(ctx context.Context, query string, args ...interface{}) (domain.Payment, error) {
+	res, err := scanPayment(m.Conn.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return domain.Payment{}, domain.ErrNotFound
+	}
+	return res, err
+}
+
+type paymentScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPayment(row paymentScanner) (res domain.Payment, err error) {
+	err = row.Scan(
+		&res.ID,
+		&res.ReferenceID,
+		&res.SessionID,
+		&res.UserID,
+		&res.Amount,
+		&res.Status,
+		&res.URL,
+		&res.RefundRequired,
+		&res.CreatedAt,
+		&res.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return domain.Payment{}, err
+	}
+	if err != nil {
+		return domain.Payment{}, fmt.Errorf("failed to scan payment: %w", err)
+	}
+	return res, nil
+}
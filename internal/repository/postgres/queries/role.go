@@ -0,0 +1,82 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+
+	"github.com/sirupsen/logrus"
+)
+
+type PostgresRoleQueryRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresRoleQueryRepository(conn *sql.DB) *PostgresRoleQueryRepository {
+	return &PostgresRoleQueryRepository{conn}
+}
+
+// fetchNames is a helper method to execute a query returning a single name column
+func (m *PostgresRoleQueryRepository) fetchNames(ctx context.Context, query string, args ...interface{}) (result []string, err error) {
+	rows, err := m.Conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		logrus.Error(err)
+		return nil, err
+	}
+
+	defer func() {
+		errRow := rows.Close()
+		if errRow != nil {
+			logrus.Error(errRow)
+		}
+	}()
+
+	result = make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			logrus.Error(err)
+			return nil, err
+		}
+		result = append(result, name)
+	}
+
+	return result, nil
+}
+
+// GetByName retrieves a role by its name
+func (m *PostgresRoleQueryRepository) GetByName(ctx context.Context, name string) (res domain.Role, err error) {
+	query := `SELECT id, name, description, created_at FROM roles WHERE name = $1`
+
+	err = m.Conn.QueryRowContext(ctx, query, name).Scan(&res.ID, &res.Name, &res.Description, &res.CreatedAt)
+	if err == sql.ErrNoRows {
+		return domain.Role{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.Role{}, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	return res, nil
+}
+
+// GetNamesByUserID retrieves the names of the roles assigned to a user
+func (m *PostgresRoleQueryRepository) GetNamesByUserID(ctx context.Context, userID int64) ([]string, error) {
+	query := `SELECT r.name FROM roles r
+			  JOIN user_roles ur ON ur.role_id = r.id
+			  WHERE ur.user_id = $1
+			  ORDER BY r.name`
+
+	return m.fetchNames(ctx, query, userID)
+}
+
+// GetPermissionsByUserID retrieves the permissions granted to a user through all of their roles
+func (m *PostgresRoleQueryRepository) GetPermissionsByUserID(ctx context.Context, userID int64) ([]string, error) {
+	query := `SELECT DISTINCT p.name FROM permissions p
+			  JOIN role_permissions rp ON rp.permission_id = p.id
+			  JOIN user_roles ur ON ur.role_id = rp.role_id
+			  WHERE ur.user_id = $1
+			  ORDER BY p.name`
+
+	return m.fetchNames(ctx, query, userID)
+}
@@ -31,16 +31,21 @@ func (m *PostgresTokenQueryRepository) fetch(ctx context.Context, query string,
 	result = make([]domain.Token, 0)
 	for rows.Next() {
 		var t domain.Token
+		var expiresAt sql.NullTime
 		if err = rows.Scan(
 			&t.ID,
 			&t.RefreshToken,
 			&t.UserID,
+			&t.DeviceID,
+			&t.FamilyID,
+			&expiresAt,
 			&t.UpdatedAt,
 			&t.CreatedAt,
 		); err != nil {
 			logrus.Error("Error scanning row: ", err)
 			return nil, err
 		}
+		t.ExpiresAt = expiresAt.Time
 		result = append(result, t)
 	}
 
@@ -53,7 +58,7 @@ func (m *PostgresTokenQueryRepository) fetch(ctx context.Context, query string,
 }
 
 func (m *PostgresTokenQueryRepository) GetByID(ctx context.Context, id int64) (res domain.Token, err error) {
-	query := `SELECT id, refresh_token, user_id, device_id, updated_at, created_at FROM token WHERE id = $1`
+	query := `SELECT id, refresh_token, user_id, COALESCE(device_id, 0), family_id, expires_at, updated_at, created_at FROM token WHERE id = $1`
 	list, err := m.fetch(ctx, query, id)
 	if err != nil {
 		return domain.Token{}, err
@@ -69,7 +74,7 @@ func (m *PostgresTokenQueryRepository) GetByID(ctx context.Context, id int64) (r
 }
 
 func (m *PostgresTokenQueryRepository) GetByUserID(ctx context.Context, id int64) (res domain.Token, err error) {
-	query := `SELECT id, refresh_token, user_id, updated_at, created_at FROM token WHERE user_id = $1`
+	query := `SELECT id, refresh_token, user_id, COALESCE(device_id, 0), family_id, expires_at, updated_at, created_at FROM token WHERE user_id = $1`
 	list, err := m.fetch(ctx, query, id)
 	if err != nil {
 		return domain.Token{}, err
@@ -83,3 +88,35 @@ func (m *PostgresTokenQueryRepository) GetByUserID(ctx context.Context, id int64
 
 	return
 }
+
+func (m *PostgresTokenQueryRepository) GetByFamilyID(ctx context.Context, familyID string) (res domain.Token, err error) {
+	query := `SELECT id, refresh_token, user_id, COALESCE(device_id, 0), family_id, expires_at, updated_at, created_at FROM token WHERE family_id = $1`
+	list, err := m.fetch(ctx, query, familyID)
+	if err != nil {
+		return domain.Token{}, err
+	}
+
+	if len(list) > 0 {
+		res = list[0]
+	} else {
+		return res, domain.ErrNotFound
+	}
+
+	return
+}
+
+func (m *PostgresTokenQueryRepository) GetByDeviceID(ctx context.Context, deviceID int64) (res domain.Token, err error) {
+	query := `SELECT id, refresh_token, user_id, COALESCE(device_id, 0), family_id, expires_at, updated_at, created_at FROM token WHERE device_id = $1`
+	list, err := m.fetch(ctx, query, deviceID)
+	if err != nil {
+		return domain.Token{}, err
+	}
+
+	if len(list) > 0 {
+		res = list[0]
+	} else {
+		return res, domain.ErrNotFound
+	}
+
+	return
+}
@@ -44,6 +44,12 @@ func (m *PostgresUserQueryRepository) fetch(ctx context.Context, query string, a
 			&user.UpdatedAt,
 			&user.DeletedAt,
 			&user.EmailVerifiedAt,
+			&user.EmailVerificationSentAt,
+			&user.Phone,
+			&user.PhoneVerifiedAt,
+			&user.AvatarURL,
+			&user.PendingEmail,
+			&user.DeletionScheduledAt,
 		)
 
 		if err != nil {
@@ -58,7 +64,7 @@ func (m *PostgresUserQueryRepository) fetch(ctx context.Context, query string, a
 
 // GetByEmail retrieves a user by their phone number and country code
 func (m *PostgresUserQueryRepository) GetByEmail(ctx context.Context, email string) (res domain.User, err error) {
-	query := `SELECT id, full_name, email, password, created_at, updated_at, deleted_at, email_verified_at FROM users WHERE email = $1`
+	query := `SELECT id, full_name, email, password, created_at, updated_at, deleted_at, email_verified_at, email_verification_sent_at, COALESCE(phone, ''), phone_verified_at, COALESCE(avatar_url, ''), COALESCE(pending_email, ''), deletion_scheduled_at FROM users WHERE email = $1`
 	list, err := m.fetch(ctx, query, email)
 
 	if err != nil {
@@ -73,3 +79,39 @@ func (m *PostgresUserQueryRepository) GetByEmail(ctx context.Context, email stri
 
 	return
 }
+
+// GetByID retrieves a user by their ID
+func (m *PostgresUserQueryRepository) GetByID(ctx context.Context, id int64) (res domain.User, err error) {
+	query := `SELECT id, full_name, email, password, created_at, updated_at, deleted_at, email_verified_at, email_verification_sent_at, COALESCE(phone, ''), phone_verified_at, COALESCE(avatar_url, ''), COALESCE(pending_email, ''), deletion_scheduled_at FROM users WHERE id = $1`
+	list, err := m.fetch(ctx, query, id)
+
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if len(list) > 0 {
+		res = list[0]
+	} else {
+		return res, domain.ErrNotFound
+	}
+
+	return
+}
+
+// GetByPhone retrieves the user that verified the given E.164 phone number
+func (m *PostgresUserQueryRepository) GetByPhone(ctx context.Context, phone string) (res domain.User, err error) {
+	query := `SELECT id, full_name, email, password, created_at, updated_at, deleted_at, email_verified_at, email_verification_sent_at, COALESCE(phone, ''), phone_verified_at, COALESCE(avatar_url, ''), COALESCE(pending_email, ''), deletion_scheduled_at FROM users WHERE phone = $1 AND phone_verified_at IS NOT NULL`
+	list, err := m.fetch(ctx, query, phone)
+
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if len(list) > 0 {
+		res = list[0]
+	} else {
+		return res, domain.ErrNotFound
+	}
+
+	return
+}
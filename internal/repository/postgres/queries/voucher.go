@@ -0,0 +1,195 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"santapan/domain"
+)
+
+type PostgresVoucherQueryRepository struct {
+	Conn *sql.DB
+}
+
+func NewPostgresVoucherQueryRepository(conn *sql.DB) *PostgresVoucherQueryRepository {
+	return &PostgresVoucherQueryRepository{conn}
+}
+
+const voucherColumns = `id, code, description, discount_type, discount_amount, discount_percent, min_spend, max_discount, starts_at, ends_at,
+	usage_limit, usage_limit_per_user, active, created_at, updated_at`
+
+// GetByCode retrieves a voucher with its eligibility by its upper case code
+func (m *PostgresVoucherQueryRepository) GetByCode(ctx context.Context, code string) (domain.Voucher, error) {
+	return m.get(ctx, `SELECT `+voucherColumns+` FROM voucher WHERE code = $1`, code)
+}
+
+// GetByID retrieves a voucher with its eligibility
+func (m *PostgresVoucherQueryRepository) GetByID(ctx context.Context, id int64) (domain.Voucher, error) {
+	return m.get(ctx, `SELECT `+voucherColumns+` FROM voucher WHERE id = $1`, id)
+}
+
+// Fetch retrieves every voucher, newest first
+func (m *PostgresVoucherQueryRepository) Fetch(ctx context.Context) (res []domain.Voucher, err error) {
+	rows, err := m.Conn.QueryContext(ctx, `SELECT `+voucherColumns+` FROM voucher ORDER BY id DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch vouchers: %w", err)
+	}
+	defer rows.Close()
+
+	res = make([]domain.Voucher, 0)
+	for rows.Next() {
+		voucher, err := scanVoucher(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan voucher: %w", err)
+		}
+		res = append(res, voucher)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch vouchers: %w", err)
+	}
+
+	for i := range res {
+		if err = m.fillEligibility(ctx, &res[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// EligibleSubtotal sums the items of the cart the voucher applies to. Menus match by
+// ID or category, bundlings by type, and a voucher without rules matches every item.
+func (m *PostgresVoucherQueryRepository) EligibleSubtotal(ctx context.Context, voucherID int64, cartID int64) (total domain.Money, err error) {
+	query := `SELECT COALESCE(SUM(ci.price * ci.quantity), 0)
+			  FROM cart_item ci
+			  LEFT JOIN bundling b ON b.id = ci.bundling_id
+			  WHERE ci.cart_id = $2 AND (
+				  NOT EXISTS (SELECT 1 FROM voucher_eligibility e WHERE e.voucher_id = $1)
+				  OR EXISTS (
+					  SELECT 1 FROM voucher_eligibility e
+					  WHERE e.voucher_id = $1 AND (
+						  e.menu_id = ci.menu_id
+						  OR e.bundling_type = b.bundling_type::TEXT
+						  OR e.category_id IN (SELECT cm.category_id FROM category_menu cm WHERE cm.menu_id = ci.menu_id)
+					  )
+				  )
+			  )`
+
+	if err = m.Conn.QueryRowContext(ctx, query, voucherID, cartID).Scan(&total); err != nil {
+		return 0, fmt.Errorf("failed to total eligible items: %w", err)
+	}
+
+	return total, nil
+}
+
+// CountRedemptions counts the redemptions of a voucher overall and by the user. Orders
+// that were cancelled or failed give their redemption back.
+func (m *PostgresVoucherQueryRepository) CountRedemptions(ctx context.Context, voucherID int64, userID int64) (total int, byUser int, err error) {
+	query := `SELECT COUNT(*), COUNT(*) FILTER (WHERE r.user_id = $2)
+			  FROM voucher_redemption r JOIN transaction t ON t.id = r.transaction_id
+			  WHERE r.voucher_id = $1 AND t.status NOT IN ($3, $4)`
+
+	err = m.Conn.QueryRowContext(ctx, query, voucherID, userID, domain.OrderStatusCancelled, domain.OrderStatusFailed).Scan(&total, &byUser)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count voucher redemptions: %w", err)
+	}
+
+	return total, byUser, nil
+}
+
+func (m *PostgresVoucherQueryRepository) get(ctx context.Context, query string, args ...interface{}) (domain.Voucher, error) {
+	res, err := scanVoucher(m.Conn.QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return domain.Voucher{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.Voucher{}, fmt.Errorf("failed to get voucher: %w", err)
+	}
+
+	if err = m.fillEligibility(ctx, &res); err != nil {
+		return domain.Voucher{}, err
+	}
+
+	return res, nil
+}
+
+func (m *PostgresVoucherQueryRepository) fillEligibility(ctx context.Context, voucher *domain.Voucher) error {
+	rows, err := m.Conn.QueryContext(ctx, `SELECT category_id, menu_id, bundling_type FROM voucher_eligibility WHERE voucher_id = $1 ORDER BY id`, voucher.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch voucher eligibility: %w", err)
+	}
+	defer rows.Close()
+
+	voucher.CategoryIDs = make([]int64, 0)
+	voucher.MenuIDs = make([]int64, 0)
+	voucher.BundlingTypes = make([]string, 0)
+	for rows.Next() {
+		var (
+			categoryID, menuID sql.NullInt64
+			bundlingType       sql.NullString
+		)
+		if err = rows.Scan(&categoryID, &menuID, &bundlingType); err != nil {
+			return fmt.Errorf("failed to scan voucher eligibility: %w", err)
+		}
+		switch {
+		case categoryID.Valid:
+			voucher.CategoryIDs = append(voucher.CategoryIDs, categoryID.Int64)
+		case menuID.Valid:
+			voucher.MenuIDs = append(voucher.MenuIDs, menuID.Int64)
+		case bundlingType.Valid:
+			voucher.BundlingTypes = append(voucher.BundlingTypes, bundlingType.String)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to fetch voucher eligibility: %w", err)
+	}
+
+	return nil
+}
+
+type voucherScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanVoucher(row voucherScanner) (res domain.Voucher, err error) {
+	var (
+		endsAt                        sql.NullTime
+		usageLimit, usageLimitPerUser sql.NullInt64
+	)
+
+	err = row.Scan(
+		&res.ID,
+		&res.Code,
+		&res.Description,
+		&res.DiscountType,
+		&res.DiscountAmount,
+		&res.DiscountPercent,
+		&res.MinSpend,
+		&res.MaxDiscount,
+		&res.StartsAt,
+		&endsAt,
+		&usageLimit,
+		&usageLimitPerUser,
+		&res.Active,
+		&res.CreatedAt,
+		&res.UpdatedAt,
+	)
+	if err != nil {
+		return domain.Voucher{}, err
+	}
+
+	if endsAt.Valid {
+		res.EndsAt = &endsAt.Time
+	}
+	if usageLimit.Valid {
+		limit := int(usageLimit.Int64)
+		res.UsageLimit = &limit
+	}
+	if usageLimitPerUser.Valid {
+		limit := int(usageLimitPerUser.Int64)
+		res.UsageLimitPerUser = &limit
+	}
+
+	return res, nil
+}
@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"santapan/domain"
+	"time"
+
+	goRedis "github.com/redis/go-redis/v9"
+)
+
+const idempotencyPrefix = "santapan:idempotency:"
+
+// IdempotencyRepository keeps Idempotency-Key records in Redis, expired keys are removed by Redis
+type IdempotencyRepository struct {
+	Client *goRedis.Client
+}
+
+// NewIdempotencyRepository creates a Redis backed idempotency store
+func NewIdempotencyRepository(client *goRedis.Client) *IdempotencyRepository {
+	return &IdempotencyRepository{Client: client}
+}
+
+// Reserve stores the record unless a record exists for its key, which is returned instead.
+// The reservation expires at its LockedUntil, Complete extends it to its ExpiresAt.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, record domain.IdempotencyRecord) (domain.IdempotencyRecord, bool, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("failed to encode idempotency key: %w", err)
+	}
+
+	reserved, err := r.Client.SetNX(ctx, idempotencyPrefix+record.Key, data, time.Until(record.LockedUntil)).Result()
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if reserved {
+		return domain.IdempotencyRecord{}, true, nil
+	}
+
+	stored, err := r.Client.Get(ctx, idempotencyPrefix+record.Key).Bytes()
+	if err == goRedis.Nil {
+		// Expired between both commands, the client retries
+		return domain.IdempotencyRecord{}, false, domain.ErrConflict
+	}
+	if err != nil {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+
+	var existing domain.IdempotencyRecord
+	if err = json.Unmarshal(stored, &existing); err != nil {
+		return domain.IdempotencyRecord{}, false, fmt.Errorf("failed to decode idempotency key: %w", err)
+	}
+
+	return existing, false, nil
+}
+
+// Complete records the response of a reserved request until the expiry of the record. A
+// reservation whose lease already ran out is not recorded, a retry is handled again.
+func (r *IdempotencyRepository) Complete(ctx context.Context, record domain.IdempotencyRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode idempotency key: %w", err)
+	}
+
+	err = r.Client.SetArgs(ctx, idempotencyPrefix+record.Key, data, goRedis.SetArgs{Mode: "XX", ExpireAt: record.ExpiresAt}).Err()
+	if err != nil && err != goRedis.Nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+
+	return nil
+}
+
+// Delete frees a key whose request could not be handled so it can be retried
+func (r *IdempotencyRepository) Delete(ctx context.Context, key string) error {
+	if err := r.Client.Del(ctx, idempotencyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired is a no-op, Redis expires the keys itself
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	goRedis "github.com/redis/go-redis/v9"
+)
+
+const loginAttemptPrefix = "santapan:login:"
+
+// LoginAttemptRepository keeps failed login attempts in Redis so every API instance shares them
+type LoginAttemptRepository struct {
+	Client *goRedis.Client
+}
+
+// NewLoginAttemptRepository creates a Redis backed attempt store
+func NewLoginAttemptRepository(client *goRedis.Client) *LoginAttemptRepository {
+	return &LoginAttemptRepository{Client: client}
+}
+
+// RecordFailure stores failures in a sorted set scored by time, trimming entries outside the window
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int64, error) {
+	redisKey := loginAttemptPrefix + "failures:" + key
+	member := strconv.FormatInt(now.UnixNano(), 10)
+
+	pipe := r.Client.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, redisKey, "-inf", strconv.FormatInt(now.Add(-window).UnixNano(), 10))
+	pipe.ZAdd(ctx, redisKey, goRedis.Z{Score: float64(now.UnixNano()), Member: member})
+	count := pipe.ZCard(ctx, redisKey)
+	pipe.Expire(ctx, redisKey, window)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	return count.Val(), nil
+}
+
+func (r *LoginAttemptRepository) ResetFailures(ctx context.Context, key string) error {
+	if err := r.Client.Del(ctx, loginAttemptPrefix+"failures:"+key).Err(); err != nil {
+		return fmt.Errorf("failed to reset login failures: %w", err)
+	}
+	return nil
+}
+
+func (r *LoginAttemptRepository) IncrementStrikes(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	redisKey := loginAttemptPrefix + "strikes:" + key
+
+	pipe := r.Client.TxPipeline()
+	count := pipe.Incr(ctx, redisKey)
+	pipe.Expire(ctx, redisKey, ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to increment login strikes: %w", err)
+	}
+
+	return count.Val(), nil
+}
+
+func (r *LoginAttemptRepository) SetLock(ctx context.Context, key string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+
+	err := r.Client.Set(ctx, loginAttemptPrefix+"lock:"+key, until.Unix(), ttl).Err()
+	if err != nil {
+		return fmt.Errorf("failed to lock login: %w", err)
+	}
+	return nil
+}
+
+func (r *LoginAttemptRepository) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	until, err := r.Client.Get(ctx, loginAttemptPrefix+"lock:"+key).Int64()
+	if err == goRedis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read login lock: %w", err)
+	}
+	return time.Unix(until, 0), nil
+}
@@ -0,0 +1,157 @@
+package rest
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	stdjson "encoding/json"
+	"fmt"
+	"net/http"
+	"santapan/account"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+	"sort"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// AccountService exports the personal data of a user
+type AccountService interface {
+	Export(ctx context.Context, userID int64) (map[string]stdjson.RawMessage, error)
+}
+
+// AccountHandler represent the httphandler for account deletion and data export
+type AccountHandler struct {
+	AccountService AccountService
+	UserService    UserService
+	TokenService   TokenService
+	Reauth         Reauthenticator
+	Validator      *validator.Validate
+}
+
+// NewAccountHandler will initialize the me/ account resources endpoint
+func NewAccountHandler(e *echo.Echo, accountService AccountService, userService UserService, tokenService TokenService, reauth Reauthenticator) {
+	handler := &AccountHandler{
+		AccountService: accountService,
+		UserService:    userService,
+		TokenService:   tokenService,
+		Reauth:         reauth,
+		Validator:      validator.New(),
+	}
+
+	e.DELETE("/me", handler.Delete, middleware.AuthMiddleware)
+	e.GET("/me/export", handler.Export, middleware.AuthMiddleware)
+}
+
+// Delete schedules the account for deletion and signs it out everywhere. Signing in
+// again within the grace period restores the account, afterwards it is purged.
+// Accounts without a password confirm with a magic link or an ID token instead.
+func (ah *AccountHandler) Delete(c echo.Context) (err error) {
+	var body domain.DeleteAccountBody
+	ctx := c.Request().Context()
+	userID := c.Get("userID").(int64)
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = ah.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	user, err := ah.UserService.GetByID(ctx, userID)
+	if err != nil {
+		return json.Response(c, http.StatusNotFound, false, "User not found", nil)
+	}
+
+	if err = ah.Reauth.Check(ctx, user, body.ReauthBody); err != nil {
+		return reauthFailed(c, body.ReauthBody, err)
+	}
+
+	scheduledAt := time.Now().Add(account.DeletionGracePeriod)
+	if err = ah.UserService.ScheduleDeletion(ctx, userID, scheduledAt); err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to delete account", nil)
+	}
+
+	if err = ah.TokenService.DeleteByUserID(ctx, userID); err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to revoke sessions", nil)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":        "account_deletion_requested",
+		"user_id":      userID,
+		"scheduled_at": scheduledAt,
+	}).Info("Account deletion requested")
+
+	return json.Response(c, http.StatusOK, true, "Account scheduled for deletion, sign in again before it is deleted to restore it", map[string]interface{}{
+		"deletion_scheduled_at": scheduledAt,
+	})
+}
+
+// Export returns everything stored about the user as JSON, or as a ZIP archive
+// with one JSON file per section when ?format=zip is given
+func (ah *AccountHandler) Export(c echo.Context) error {
+	userID := c.Get("userID").(int64)
+
+	data, err := ah.AccountService.Export(c.Request().Context(), userID)
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to export data", nil)
+	}
+
+	switch c.QueryParam("format") {
+	case "", "json":
+		return json.Response(c, http.StatusOK, true, "Successfully Export Data!", data)
+	case "zip":
+		archive, err := exportArchive(data)
+		if err != nil {
+			logrus.Error(err)
+			return json.Response(c, http.StatusInternalServerError, false, "Failed to export data", nil)
+		}
+
+		filename := fmt.Sprintf("santapan-export-%d-%s.zip", userID, time.Now().Format("20060102"))
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+		return c.Blob(http.StatusOK, "application/zip", archive)
+	default:
+		return json.Response(c, http.StatusBadRequest, false, "Unsupported format, use json or zip", nil)
+	}
+}
+
+// exportArchive writes each export section to its own JSON file in a ZIP archive
+func exportArchive(data map[string]stdjson.RawMessage) ([]byte, error) {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for _, name := range names {
+		var pretty bytes.Buffer
+		if err := stdjson.Indent(&pretty, data[name], "", "  "); err != nil {
+			return nil, fmt.Errorf("failed to format %s: %w", name, err)
+		}
+
+		file, err := archive.Create(name + ".json")
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", name, err)
+		}
+		if _, err = file.Write(pretty.Bytes()); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -16,10 +16,10 @@ import (
 // PostgresRepositoryQueries defines the methods for querying the category repository.
 type AddressService interface {
 	GetByUserID(ctx context.Context, userID int64) ([]domain.Address, error)
-	GetByID(ctx context.Context, id int64) (domain.Address, error)
+	GetByID(ctx context.Context, userID int64, id int64) (domain.Address, error)
 	Create(ctx context.Context, a domain.Address) (domain.Address, error)
 	Update(ctx context.Context, a domain.Address) (domain.Address, error)
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, userID int64, id int64) error
 }
 
 type AddressHandler struct {
@@ -42,6 +42,7 @@ func NewAddressHandler(e *echo.Echo, addressService AddressService) {
 	e.GET("/address/:id", handler.GetByID, middleware.AuthMiddleware)
 	e.POST("/address", handler.Create, middleware.AuthMiddleware)
 	e.PUT("/address", handler.Update, middleware.AuthMiddleware)
+	e.DELETE("/address/:id", handler.Delete, middleware.AuthMiddleware)
 }
 
 // GetByUserID retrieves an address by user ID.
@@ -62,7 +63,12 @@ func (ah *AddressHandler) GetByID(c echo.Context) error {
 		return json.Response(c, http.StatusBadRequest, false, "", "Invalid ID")
 	}
 
-	res, err := ah.AddressService.GetByID(c.Request().Context(), int64(id))
+	userID := c.Get("userID").(int64)
+
+	res, err := ah.AddressService.GetByID(c.Request().Context(), userID, int64(id))
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusNotFound, false, "", "Address not found")
+	}
 	if err != nil {
 		return json.Response(c, http.StatusInternalServerError, false, "", err.Error())
 	}
@@ -107,6 +113,9 @@ func (ah *AddressHandler) Update(c echo.Context) error {
 	a.UserID = userID
 
 	res, err := ah.AddressService.Update(c.Request().Context(), a)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusNotFound, false, "", "Address not found")
+	}
 	if err != nil {
 		return json.Response(c, http.StatusInternalServerError, false, "", err.Error())
 	}
@@ -121,7 +130,12 @@ func (ah *AddressHandler) Delete(c echo.Context) error {
 		return json.Response(c, http.StatusBadRequest, false, "", "Invalid ID")
 	}
 
-	err = ah.AddressService.Delete(c.Request().Context(), int64(id))
+	userID := c.Get("userID").(int64)
+
+	err = ah.AddressService.Delete(c.Request().Context(), userID, int64(id))
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusNotFound, false, "", "Address not found")
+	}
 	if err != nil {
 		return json.Response(c, http.StatusInternalServerError, false, "", err.Error())
 	}
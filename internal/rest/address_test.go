@@ -0,0 +1,132 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"santapan/address"
+	"santapan/domain"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// addressRepository keeps the addresses of the handler tests in memory
+type addressRepository struct {
+	addresses map[int64]domain.Address
+}
+
+func (r *addressRepository) GetByUserID(ctx context.Context, userID int64) ([]domain.Address, error) {
+	res := make([]domain.Address, 0)
+	for _, a := range r.addresses {
+		if a.UserID == userID {
+			res = append(res, a)
+		}
+	}
+	return res, nil
+}
+
+func (r *addressRepository) GetByID(ctx context.Context, id int64) (domain.Address, error) {
+	a, ok := r.addresses[id]
+	if !ok {
+		return domain.Address{}, domain.ErrNotFound
+	}
+	return a, nil
+}
+
+func (r *addressRepository) Create(ctx context.Context, a domain.Address) (domain.Address, error) {
+	r.addresses[a.ID] = a
+	return a, nil
+}
+
+func (r *addressRepository) Update(ctx context.Context, a domain.Address) (domain.Address, error) {
+	r.addresses[a.ID] = a
+	return a, nil
+}
+
+func (r *addressRepository) Delete(ctx context.Context, userID int64, id int64) error {
+	delete(r.addresses, id)
+	return nil
+}
+
+func newAddressTest() (*AddressHandler, *addressRepository) {
+	repo := &addressRepository{addresses: map[int64]domain.Address{
+		10: {ID: 10, UserID: 1, Label: "Home", Address: "Jl. Melati 1"},
+	}}
+	handler := &AddressHandler{AddressService: address.NewService(repo, repo), Validator: validator.New()}
+	return handler, repo
+}
+
+// serveAddress calls the handler as the user, the way AuthMiddleware leaves the context
+func serveAddress(userID int64, method, body, id string, handle func(echo.Context) error) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/address", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	c := echo.New().NewContext(req, rec)
+	c.Set("userID", userID)
+	if id != "" {
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+	}
+
+	if err := handle(c); err != nil {
+		rec.Code = http.StatusInternalServerError
+	}
+	return rec
+}
+
+func TestAddressHandlerGetByID(t *testing.T) {
+	handler, _ := newAddressTest()
+
+	if rec := serveAddress(1, http.MethodGet, "", "10", handler.GetByID); rec.Code != http.StatusOK {
+		t.Fatalf("owner: got status %d, want 200", rec.Code)
+	}
+
+	rec := serveAddress(2, http.MethodGet, "", "10", handler.GetByID)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("other user: got status %d, want 404", rec.Code)
+	}
+	if strings.Contains(rec.Body.String(), "Melati") {
+		t.Fatalf("other user: response leaks the address: %s", rec.Body.String())
+	}
+}
+
+func TestAddressHandlerUpdate(t *testing.T) {
+	handler, repo := newAddressTest()
+
+	body := `{"id": 10, "label": "Office", "address": "Jl. Mawar 2"}`
+	if rec := serveAddress(2, http.MethodPut, body, "", handler.Update); rec.Code != http.StatusNotFound {
+		t.Fatalf("other user: got status %d, want 404", rec.Code)
+	}
+	if a := repo.addresses[10]; a.Address != "Jl. Melati 1" || a.UserID != 1 {
+		t.Fatalf("other user changed the address: %+v", a)
+	}
+
+	if rec := serveAddress(1, http.MethodPut, body, "", handler.Update); rec.Code != http.StatusOK {
+		t.Fatalf("owner: got status %d, want 200", rec.Code)
+	}
+	if a := repo.addresses[10]; a.Address != "Jl. Mawar 2" {
+		t.Fatalf("owner: address was not updated: %+v", a)
+	}
+}
+
+func TestAddressHandlerDelete(t *testing.T) {
+	handler, repo := newAddressTest()
+
+	if rec := serveAddress(2, http.MethodDelete, "", "10", handler.Delete); rec.Code != http.StatusNotFound {
+		t.Fatalf("other user: got status %d, want 404", rec.Code)
+	}
+	if _, ok := repo.addresses[10]; !ok {
+		t.Fatal("other user deleted the address")
+	}
+
+	if rec := serveAddress(1, http.MethodDelete, "", "10", handler.Delete); rec.Code != http.StatusOK {
+		t.Fatalf("owner: got status %d, want 200", rec.Code)
+	}
+	if _, ok := repo.addresses[10]; ok {
+		t.Fatal("owner: address was not deleted")
+	}
+}
@@ -2,16 +2,22 @@ package rest
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"net/http"
 	"santapan/domain"
+	"santapan/internal/rest/middleware"
 	"santapan/pkg/json"
+	"santapan/pkg/jwtkey"
+	"santapan/pkg/mailer"
+	"santapan/pkg/password"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/go-playground/validator.v9"
 )
 
@@ -20,44 +26,125 @@ type TokenService interface {
 	Update(ctx context.Context, ar *domain.Token) error
 	Store(context.Context, *domain.Token) error
 	Delete(ctx context.Context, id int64) error
+	DeleteByUserID(ctx context.Context, userID int64) error
+	DeleteByDeviceID(ctx context.Context, deviceID int64) error
 	GetByUserID(ctx context.Context, id int64) (domain.Token, error)
+	GetByFamilyID(ctx context.Context, familyID string) (domain.Token, error)
+	GetByDeviceID(ctx context.Context, deviceID int64) (domain.Token, error)
+	Rotate(ctx context.Context, userID int64, familyID string, presented string, next *domain.Token) error
+	PruneDeviceless(ctx context.Context, userID int64) error
 }
 
 type DeviceService interface {
-	Store(context.Context, *domain.Device) error
-	Update(ctx context.Context, device *domain.Device) error
-	GetByUserID(ctx context.Context, id int64) (domain.Device, error)
+	Register(ctx context.Context, userID int64, info domain.DeviceHeaderInformation) (domain.Device, error)
+	Touch(ctx context.Context, id int64, ipAddress string) error
+	FetchByUserID(ctx context.Context, userID int64) ([]domain.Device, error)
+	GetByID(ctx context.Context, id int64) (domain.Device, error)
+	Delete(ctx context.Context, id int64) error
 }
 
 type UserService interface {
 	Store(ctx context.Context, user *domain.User) error
 	GetByEmail(ctx context.Context, email string) (domain.User, error)
+	GetByID(ctx context.Context, id int64) (domain.User, error)
+	GetByPhone(ctx context.Context, phone string) (domain.User, error)
+	UpdateEmailVerifiedAt(ctx context.Context, id int64, verifiedAt time.Time) error
+	UpdateEmailVerificationSentAt(ctx context.Context, id int64, sentAt time.Time) error
+	HashPassword(password string) (string, error)
+	UpdatePassword(ctx context.Context, id int64, password string) error
+	VerifyPassword(ctx context.Context, user domain.User, password string) bool
+	UpdatePhoneVerifiedAt(ctx context.Context, id int64, phone string, verifiedAt time.Time) error
+	UpdateProfile(ctx context.Context, id int64, fullName *string, phone *string, avatarURL *string) (domain.User, error)
+	RequestEmailChange(ctx context.Context, id int64, email string) error
+	ConfirmEmailChange(ctx context.Context, id int64, email string, verifiedAt time.Time) error
+	ScheduleDeletion(ctx context.Context, id int64, at time.Time) error
+	CancelDeletion(ctx context.Context, id int64) error
+}
+
+// RoleService supplies the roles and permissions carried in access tokens
+type RoleService interface {
+	GetAccess(ctx context.Context, userID int64) (domain.Access, error)
+	Bootstrap(ctx context.Context, user domain.User) error
+}
+
+// MfaVerifier checks the second factor of users who enabled it
+type MfaVerifier interface {
+	IsEnabled(ctx context.Context, userID int64) (bool, error)
+	Verify(ctx context.Context, userID int64, code string) error
+}
+
+// MagicLinkService issues and consumes single-use passwordless sign-in tokens
+type MagicLinkService interface {
+	Create(ctx context.Context, userID int64) (string, error)
+	Consume(ctx context.Context, token string) (int64, error)
+}
+
+// LoginAttemptService throttles repeated failed logins per account and per IP address
+type LoginAttemptService interface {
+	Check(ctx context.Context, email string, ip string) (time.Duration, error)
+	Fail(ctx context.Context, email string, ip string) error
+	Succeed(ctx context.Context, email string) error
 }
 
 // ArticleHandler  represent the httphandler for article
 type AuthHandler struct {
-	TokenService TokenService
-	UserService  UserService
-	Validator    *validator.Validate
+	TokenService  TokenService
+	UserService   UserService
+	DeviceService DeviceService
+	RoleService   RoleService
+	LoginAttempts LoginAttemptService
+	MfaService    MfaVerifier
+	MagicLinks    MagicLinkService
+	OtpService    OtpService
+	Identities    IdentityService
+	KeyProvider   jwtkey.Provider
+	Mailer        mailer.Mailer
+	Validator     *validator.Validate
 }
 
 // NewArticleHandler will initialize the articles/ resources endpoint
-func NewAuthHandler(e *echo.Echo, tokenService TokenService, userService UserService) {
-	validator := validator.New()
-	// Register the custom validation function
-	validator.RegisterValidation("date", validateDate)
+func NewAuthHandler(e *echo.Echo, tokenService TokenService, userService UserService, deviceService DeviceService, roleService RoleService, loginAttempts LoginAttemptService, mfaService MfaVerifier, magicLinks MagicLinkService, otpService OtpService, identities IdentityService, keyProvider jwtkey.Provider, mailer mailer.Mailer) {
+	validator := newValidator()
 
 	handler := &AuthHandler{
-		TokenService: tokenService,
-		UserService:  userService,
-		Validator:    validator,
+		TokenService:  tokenService,
+		UserService:   userService,
+		DeviceService: deviceService,
+		RoleService:   roleService,
+		LoginAttempts: loginAttempts,
+		MfaService:    mfaService,
+		MagicLinks:    magicLinks,
+		OtpService:    otpService,
+		Identities:    identities,
+		KeyProvider:   keyProvider,
+		Mailer:        mailer,
+		Validator:     validator,
 	}
 	e.POST("/login", handler.Login)
+	e.POST("/login/mfa", handler.LoginMfa)
+	e.POST("/login/magic-link", handler.RequestMagicLink)
+	e.POST("/login/magic-link/verify", handler.LoginMagicLink)
+	// OTP login is only available when codes can be sent
+	if otpService != nil {
+		e.POST("/login/otp", handler.RequestLoginOtp)
+		e.POST("/login/otp/verify", handler.LoginOtp)
+	}
+	e.POST("/login/oidc", handler.LoginOidc)
 	e.POST("/register", handler.Register)
+	e.POST("/token/refresh", handler.Refresh)
+	e.POST("/logout", handler.Logout, middleware.AuthMiddleware)
+	e.POST("/logout-all", handler.LogoutAll, middleware.AuthMiddleware)
 	e.GET("/", handler.HelloWorld) // Register the HelloWorld endpoint
 }
 
-var jwtSecret = []byte("SANTAPANSECRET") // Replace with your secret key
+const (
+	accessTokenTTL  = time.Hour * 24
+	refreshTokenTTL = time.Hour * 24 * 90
+
+	// mfaPendingTokenType marks a token proving the password step of a two-step login
+	mfaPendingTokenType = "mfa_pending"
+	mfaPendingTTL       = time.Minute * 5
+)
 
 // HelloWorld handles a simple GET request to return "Hello, World!"
 func (th *AuthHandler) HelloWorld(c echo.Context) error {
@@ -78,58 +165,146 @@ func (th *AuthHandler) Login(c echo.Context) (err error) {
 		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
 	}
 
+	ip := c.RealIP()
+
+	// Refuse locked accounts and IP addresses before touching the password
+	if retryAfter, err := th.LoginAttempts.Check(ctx, loginBody.Email, ip); err != nil {
+		if err == domain.ErrTooManyAttempts {
+			return tooManyLoginAttempts(c, retryAfter)
+		}
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to check login attempts", nil)
+	}
+
 	user, err := th.UserService.GetByEmail(ctx, loginBody.Email)
 
 	if err != nil || &user == nil {
-		return json.Response(c, http.StatusBadRequest, false, "Email or password is incorrect", nil)
+		return th.loginFailed(c, loginBody.Email, ip, "Email or password is incorrect")
 	}
 
-	// Password Check Password Bcrypt
-	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginBody.Password)) != nil {
-		return json.Response(c, http.StatusBadRequest, false, "Email or password is incorrect", nil)
+	// Check the password, upgrading its hash when the hashing parameters changed
+	if !th.UserService.VerifyPassword(ctx, user, loginBody.Password) {
+		return th.loginFailed(c, loginBody.Email, ip, "Email or password is incorrect")
 	}
 
-	accessToken, err := th.generateToken(user.ID, time.Now().Add(time.Hour*24).Unix())
+	return th.startSession(c, user)
+}
+
+// startSession continues a login once the first factor has been checked. Accounts
+// with a second factor get a short-lived token to present with their code, every
+// other account is signed in right away.
+func (th *AuthHandler) startSession(c echo.Context, user domain.User) (err error) {
+	mfaEnabled, err := th.MfaService.IsEnabled(c.Request().Context(), user.ID)
 	if err != nil {
-		return json.Response(c, http.StatusInternalServerError, false, "Failed to generate access token", nil)
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to check two-factor authentication", nil)
 	}
 
-	refreshToken, err := th.generateToken(user.ID, time.Now().Add(time.Hour*24*90).Unix())
-	if err != nil {
-		return json.Response(c, http.StatusInternalServerError, false, "Failed to generate refresh token", nil)
+	if mfaEnabled {
+		mfaToken, err := signToken(th.KeyProvider, user.ID, time.Now().Add(mfaPendingTTL).Unix(), jwt.MapClaims{
+			"typ": mfaPendingTokenType,
+			"jti": newTokenID(),
+		})
+		if err != nil {
+			logrus.Error(err)
+			return json.Response(c, http.StatusInternalServerError, false, "Failed to generate tokens", nil)
+		}
+
+		return json.Response(c, http.StatusOK, true, "Enter the code from your authenticator app", map[string]interface{}{
+			"mfaRequired": true,
+			"mfaToken":    mfaToken,
+		})
+	}
+
+	return th.completeLogin(c, user)
+}
+
+// LoginMfa finishes a two-step login with the mfa token from Login and a TOTP or recovery code
+func (th *AuthHandler) LoginMfa(c echo.Context) (err error) {
+	var body domain.LoginMfaBody
+	ctx := c.Request().Context()
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
 	}
 
-	// Check if a refresh token already exists
-	existingToken, err := th.TokenService.GetByUserID(ctx, user.ID)
+	if err = th.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
 
-	if err != nil && err != domain.ErrNotFound {
-		return json.Response(c, http.StatusInternalServerError, false, "Failed to get refresh token", nil)
+	claims, err := middleware.ParseToken(body.MfaToken)
+	if err != nil {
+		return json.Response(c, http.StatusUnauthorized, false, "Invalid or expired mfa token, please login again", nil)
 	}
 
-	refreshData := domain.Token{
-		RefreshToken: refreshToken,
-		UserID:       user.ID,
-		UpdatedAt:    time.Now(),
-		CreatedAt:    time.Now(),
+	tokenType, _ := claims["typ"].(string)
+	userID, ok := claims["sub"].(float64)
+	if tokenType != mfaPendingTokenType || !ok {
+		return json.Response(c, http.StatusUnauthorized, false, "Invalid or expired mfa token, please login again", nil)
 	}
 
-	if existingToken != (domain.Token{}) {
-		// Token exists, update it
-		refreshData.ID = existingToken.ID
-		refreshData.CreatedAt = existingToken.CreatedAt
-		if err = th.TokenService.Update(ctx, &refreshData); err != nil {
-			logrus.Info(err)
-			return json.Response(c, http.StatusInternalServerError, false, "Failed to update refresh token", nil)
+	user, err := th.UserService.GetByID(ctx, int64(userID))
+	if err != nil {
+		return json.Response(c, http.StatusUnauthorized, false, "Invalid or expired mfa token, please login again", nil)
+	}
+
+	// Wrong codes count towards the same lockout as wrong passwords
+	ip := c.RealIP()
+	if retryAfter, err := th.LoginAttempts.Check(ctx, user.Email, ip); err != nil {
+		if err == domain.ErrTooManyAttempts {
+			return tooManyLoginAttempts(c, retryAfter)
 		}
-	} else {
-		// Token does not exist, insert it
-		if err = th.TokenService.Store(ctx, &refreshData); err != nil {
-			return json.Response(c, http.StatusInternalServerError, false, fmt.Sprintf("Failed to store refresh token: %v", err), nil)
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to check login attempts", nil)
+	}
+
+	err = th.MfaService.Verify(ctx, user.ID, body.Code)
+	switch err {
+	case nil:
+	case domain.ErrInvalidOtp:
+		return th.loginFailed(c, user.Email, ip, "Invalid authentication code")
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to verify authentication code", nil)
+	}
+
+	return th.completeLogin(c, user)
+}
+
+// completeLogin signs in a fully authenticated user and issues their tokens
+func (th *AuthHandler) completeLogin(c echo.Context, user domain.User) (err error) {
+	ctx := c.Request().Context()
+
+	if err = th.LoginAttempts.Succeed(ctx, user.Email); err != nil {
+		logrus.Error(err)
+	}
+
+	if err = th.RoleService.Bootstrap(ctx, user); err != nil {
+		logrus.Error(err)
+	}
+
+	// Signing in during the grace period restores an account scheduled for deletion
+	if user.DeletionScheduledAt != nil {
+		if err = th.UserService.CancelDeletion(ctx, user.ID); err != nil {
+			logrus.Error(err)
+			return json.Response(c, http.StatusInternalServerError, false, "Failed to restore account", nil)
 		}
+		user.DeletionScheduledAt = nil
+
+		logrus.WithFields(logrus.Fields{
+			"event":   "account_deletion_cancelled",
+			"user_id": user.ID,
+		}).Info("Account deletion cancelled by signing in")
+	}
+
+	accessToken, refreshToken, err := th.issueTokens(c, user.ID)
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to generate tokens", nil)
 	}
 
 	userData := map[string]interface{}{
-		"user":         user,
+		"user":         user.Response(),
 		"refreshToken": refreshToken,
 		"accessToken":  accessToken,
 	}
@@ -137,6 +312,33 @@ func (th *AuthHandler) Login(c echo.Context) (err error) {
 	return json.Response(c, http.StatusOK, true, "Login successfully!", userData)
 }
 
+// loginFailed records the failed attempt and answers with the given message, which
+// is the same for an unknown email and a wrong password
+func (th *AuthHandler) loginFailed(c echo.Context, email string, ip string, message string) error {
+	ctx := c.Request().Context()
+
+	if err := th.LoginAttempts.Fail(ctx, email, ip); err != nil {
+		logrus.Error(err)
+	}
+
+	// The failure may have just locked the account
+	if retryAfter, err := th.LoginAttempts.Check(ctx, email, ip); err == domain.ErrTooManyAttempts {
+		return tooManyLoginAttempts(c, retryAfter)
+	}
+
+	return json.Response(c, http.StatusBadRequest, false, message, nil)
+}
+
+func tooManyLoginAttempts(c echo.Context, retryAfter time.Duration) error {
+	seconds := int64(retryAfter.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	c.Response().Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	return json.Response(c, http.StatusTooManyRequests, false, "Too many failed login attempts, please try again later", nil)
+}
+
 func (th *AuthHandler) Register(c echo.Context) (err error) {
 	var registerBody domain.RegisterBody
 
@@ -146,44 +348,183 @@ func (th *AuthHandler) Register(c echo.Context) (err error) {
 		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
 	}
 
+	if err = th.Validator.Struct(registerBody); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, validationMessage(err), nil)
+	}
+
 	checkUserEmail, err := th.UserService.GetByEmail(ctx, registerBody.Email)
 
 	if checkUserEmail != (domain.User{}) {
 		return json.Response(c, http.StatusBadRequest, false, "Email has already taken", nil)
 	}
 
-	hashPassword, err := bcrypt.GenerateFromPassword([]byte(registerBody.Password), bcrypt.DefaultCost)
+	hashPassword, err := th.UserService.HashPassword(registerBody.Password)
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to store user", nil)
+	}
+
 	// Insert User
 	user := domain.User{
 		FullName: registerBody.FullName,
 		Email:    registerBody.Email,
-		Password: string(hashPassword),
+		Password: hashPassword,
 	}
 
 	if err = th.UserService.Store(ctx, &user); err != nil {
 		return json.Response(c, http.StatusInternalServerError, false, "Failed to store user", nil)
 	}
 
-	accessToken, err := th.generateToken(user.ID, time.Now().Add(time.Hour*24).Unix())
+	// The account is usable right away, the email can be verified later
+	if err = sendVerificationEmail(ctx, th.KeyProvider, th.Mailer, th.UserService, user); err != nil {
+		logrus.Error(err)
+	}
+
+	accessToken, refreshToken, err := th.issueTokens(c, user.ID)
 	if err != nil {
-		return json.Response(c, http.StatusInternalServerError, false, "Failed to generate access token", nil)
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to generate tokens", nil)
 	}
 
-	refreshToken, err := th.generateToken(user.ID, time.Now().Add(time.Hour*24*90).Unix())
+	userData := map[string]interface{}{
+		"user":         user.Response(),
+		"refreshToken": refreshToken,
+		"accessToken":  accessToken,
+	}
+
+	return json.Response(c, http.StatusOK, true, "Register successfully!", userData)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token pair.
+// Every refresh rotates the refresh token; replaying an old one revokes the family.
+func (th *AuthHandler) Refresh(c echo.Context) (err error) {
+	var refreshBody domain.RefreshTokenBody
+	ctx := c.Request().Context()
+
+	if err = c.Bind(&refreshBody); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = th.Validator.Struct(refreshBody); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	claims, err := middleware.ParseToken(refreshBody.RefreshToken)
+	if err != nil {
+		return json.Response(c, http.StatusUnauthorized, false, "Invalid or expired refresh token", nil)
+	}
+
+	tokenType, _ := claims["typ"].(string)
+	familyID, _ := claims["fid"].(string)
+	userID, ok := claims["sub"].(float64)
+	if tokenType != middleware.RefreshTokenType || familyID == "" || !ok {
+		return json.Response(c, http.StatusUnauthorized, false, "Invalid or expired refresh token", nil)
+	}
+
+	accessToken, refreshToken, refreshExpiresAt, err := th.generateTokenPair(ctx, int64(userID), familyID)
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to generate tokens", nil)
+	}
+
+	refreshData := domain.Token{
+		RefreshToken: refreshToken,
+		ExpiresAt:    refreshExpiresAt,
+	}
+
+	err = th.TokenService.Rotate(ctx, int64(userID), familyID, refreshBody.RefreshToken, &refreshData)
+	switch err {
+	case nil:
+	case domain.ErrTokenReused:
+		return json.Response(c, http.StatusUnauthorized, false, "Refresh token has already been used, please login again", nil)
+	case domain.ErrInvalidToken:
+		return json.Response(c, http.StatusUnauthorized, false, "Invalid or expired refresh token", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to rotate refresh token", nil)
+	}
+
+	if refreshData.DeviceID != 0 {
+		deviceInfo, _ := c.Get("deviceInfo").(domain.DeviceHeaderInformation)
+		if err = th.DeviceService.Touch(ctx, refreshData.DeviceID, deviceInfo.IPAddress); err != nil {
+			logrus.Error(err)
+		}
+	}
+
+	tokenData := map[string]interface{}{
+		"refreshToken": refreshToken,
+		"accessToken":  accessToken,
+	}
+
+	return json.Response(c, http.StatusOK, true, "Token refreshed successfully!", tokenData)
+}
+
+// Logout revokes the session the access token belongs to
+func (th *AuthHandler) Logout(c echo.Context) (err error) {
+	ctx := c.Request().Context()
+	sessionID, _ := c.Get("sessionID").(string)
+
+	if sessionID != "" {
+		existingToken, err := th.TokenService.GetByFamilyID(ctx, sessionID)
+		if err != nil && err != domain.ErrNotFound {
+			return json.Response(c, http.StatusInternalServerError, false, "Failed to get refresh token", nil)
+		}
+
+		if err == nil {
+			if err = th.TokenService.Delete(ctx, existingToken.ID); err != nil {
+				logrus.Error(err)
+				return json.Response(c, http.StatusInternalServerError, false, "Failed to revoke session", nil)
+			}
+		}
+	}
+
+	return json.Response(c, http.StatusOK, true, "Logout successfully!", nil)
+}
+
+// LogoutAll revokes every session of the authenticated user
+func (th *AuthHandler) LogoutAll(c echo.Context) (err error) {
+	userID := c.Get("userID").(int64)
+
+	if err = th.TokenService.DeleteByUserID(c.Request().Context(), userID); err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to revoke sessions", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Logout from all sessions successfully!", nil)
+}
+
+// issueTokens mints an access token and a refresh token that starts a new token family.
+// The family is bound to the device in the request headers, replacing the refresh
+// token previously issued to that device while other devices stay signed in.
+func (th *AuthHandler) issueTokens(c echo.Context, userID int64) (accessToken string, refreshToken string, err error) {
+	ctx := c.Request().Context()
+	familyID := newTokenID()
+
+	deviceID, err := th.registerDevice(c, userID)
 	if err != nil {
-		return json.Response(c, http.StatusInternalServerError, false, "Failed to generate refresh token", nil)
+		return "", "", err
 	}
 
-	// Check if a refresh token already exists
-	existingToken, err := th.TokenService.GetByUserID(ctx, user.ID)
+	accessToken, refreshToken, refreshExpiresAt, err := th.generateTokenPair(ctx, userID, familyID)
+	if err != nil {
+		return "", "", err
+	}
 
-	if err != nil && err != domain.ErrNotFound {
-		return json.Response(c, http.StatusInternalServerError, false, "Failed to get refresh token", nil)
+	// Check if a refresh token already exists for the device
+	var existingToken domain.Token
+	if deviceID != 0 {
+		existingToken, err = th.TokenService.GetByDeviceID(ctx, deviceID)
+		if err != nil && err != domain.ErrNotFound {
+			return "", "", fmt.Errorf("failed to get refresh token: %w", err)
+		}
 	}
 
 	refreshData := domain.Token{
 		RefreshToken: refreshToken,
-		UserID:       user.ID,
+		UserID:       userID,
+		DeviceID:     deviceID,
+		FamilyID:     familyID,
+		ExpiresAt:    refreshExpiresAt,
 		UpdatedAt:    time.Now(),
 		CreatedAt:    time.Now(),
 	}
@@ -193,34 +534,131 @@ func (th *AuthHandler) Register(c echo.Context) (err error) {
 		refreshData.ID = existingToken.ID
 		refreshData.CreatedAt = existingToken.CreatedAt
 		if err = th.TokenService.Update(ctx, &refreshData); err != nil {
-			return json.Response(c, http.StatusInternalServerError, false, "Failed to update refresh token", nil)
+			return "", "", fmt.Errorf("failed to update refresh token: %w", err)
 		}
 	} else {
 		// Token does not exist, insert it
 		if err = th.TokenService.Store(ctx, &refreshData); err != nil {
-			return json.Response(c, http.StatusInternalServerError, false, fmt.Sprintf("Failed to store refresh token: %v", err), nil)
+			return "", "", fmt.Errorf("failed to store refresh token: %w", err)
 		}
 	}
 
-	userData := map[string]interface{}{
-		"user":         user,
-		"refreshToken": refreshToken,
-		"accessToken":  accessToken,
+	// Clients without a device ID get a new session on every login, only the latest are kept
+	if deviceID == 0 {
+		if err = th.TokenService.PruneDeviceless(ctx, userID); err != nil {
+			return "", "", err
+		}
 	}
 
-	return json.Response(c, http.StatusOK, true, "Register successfully!", userData)
+	return accessToken, refreshToken, nil
 }
 
-// Helper function to generate a new access token
-func (th *AuthHandler) generateToken(userId int64, exp int64) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+// registerDevice records the device from the request headers and returns its ID,
+// or 0 when the client did not identify its device
+func (th *AuthHandler) registerDevice(c echo.Context, userID int64) (int64, error) {
+	deviceInfo, _ := c.Get("deviceInfo").(domain.DeviceHeaderInformation)
+	if deviceInfo.DeviceID == "" {
+		return 0, nil
+	}
+
+	device, err := th.DeviceService.Register(c.Request().Context(), userID, deviceInfo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to register device: %w", err)
+	}
+
+	return device.ID, nil
+}
+
+// generateTokenPair signs an access token and a refresh token belonging to the given token family.
+// The access token carries the current roles and permissions of the user.
+func (th *AuthHandler) generateTokenPair(ctx context.Context, userID int64, familyID string) (accessToken string, refreshToken string, refreshExpiresAt time.Time, err error) {
+	access, err := th.RoleService.GetAccess(ctx, userID)
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to get roles: %w", err)
+	}
+
+	accessToken, err = th.generateToken(userID, time.Now().Add(accessTokenTTL).Unix(), jwt.MapClaims{
+		"typ":   middleware.AccessTokenType,
+		"sid":   familyID,
+		"jti":   newTokenID(),
+		"roles": access.Roles,
+		"perms": access.Permissions,
+	})
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	refreshExpiresAt = time.Now().Add(refreshTokenTTL)
+	refreshToken, err = th.generateToken(userID, refreshExpiresAt.Unix(), jwt.MapClaims{
+		"typ": middleware.RefreshTokenType,
+		"fid": familyID,
+		"jti": newTokenID(),
+	})
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return accessToken, refreshToken, refreshExpiresAt, nil
+}
+
+// Helper function to generate a new signed token with the given extra claims
+func (th *AuthHandler) generateToken(userId int64, exp int64, extraClaims jwt.MapClaims) (string, error) {
+	return signToken(th.KeyProvider, userId, exp, extraClaims)
+}
+
+// signToken signs a token for the user with the active key of the provider
+func signToken(keyProvider jwtkey.Provider, userId int64, exp int64, extraClaims jwt.MapClaims) (string, error) {
+	claims := jwt.MapClaims{
 		"iss": "auth-service",
 		"sub": userId,
 		"iat": time.Now().Unix(),
 		"exp": exp,
-	})
+	}
+	for key, value := range extraClaims {
+		claims[key] = value
+	}
+
+	return jwtkey.Sign(keyProvider, claims)
+}
+
+// newTokenID returns a random identifier for token families and token IDs
+func newTokenID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// newValidator creates a validator with the custom "date" and "password" tags registered
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterValidation("date", validateDate)
+	v.RegisterValidation("password", validatePassword)
+	return v
+}
+
+// validationMessage describes a validation failure, spelling out the password policy
+// rather than the generic tag failure when the password was rejected
+func validationMessage(err error) string {
+	if fieldErrors, ok := err.(validator.ValidationErrors); ok {
+		for _, fieldError := range fieldErrors {
+			if fieldError.Tag() != "password" {
+				continue
+			}
+			if value, ok := fieldError.Value().(string); ok {
+				if policyErr := password.CheckPolicy(value); policyErr != nil {
+					return policyErr.Error()
+				}
+			}
+		}
+	}
+	return "Validation failed: " + err.Error()
+}
 
-	return token.SignedString(jwtSecret)
+// validatePassword enforces the password policy
+func validatePassword(fl validator.FieldLevel) bool {
+	return password.CheckPolicy(fl.Field().String()) == nil
 }
 
 // Custom date validation function
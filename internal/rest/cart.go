@@ -0,0 +1,130 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type CartService interface {
+	GetActive(ctx context.Context, userID int64) (domain.Cart, error)
+	AddItem(ctx context.Context, userID int64, body domain.AddCartItemBody) (domain.Cart, error)
+	UpdateItem(ctx context.Context, userID int64, itemID int64, quantity int) (domain.Cart, error)
+	RemoveItem(ctx context.Context, userID int64, itemID int64) (domain.Cart, error)
+}
+
+// CartHandler represent the httphandler for the cart of the authenticated user
+type CartHandler struct {
+	CartService CartService
+	Validator   *validator.Validate
+}
+
+// NewCartHandler will initialize the cart/ resources endpoint
+func NewCartHandler(e *echo.Echo, cartService CartService) {
+	handler := &CartHandler{
+		CartService: cartService,
+		Validator:   validator.New(),
+	}
+
+	e.GET("/cart", handler.Get, middleware.AuthMiddleware)
+	e.POST("/cart/items", handler.AddItem, middleware.AuthMiddleware)
+	e.PATCH("/cart/items/:id", handler.UpdateItem, middleware.AuthMiddleware)
+	e.DELETE("/cart/items/:id", handler.RemoveItem, middleware.AuthMiddleware)
+}
+
+// Get returns the active cart of the authenticated user
+func (ch *CartHandler) Get(c echo.Context) error {
+	userID := c.Get("userID").(int64)
+
+	cart, err := ch.CartService.GetActive(c.Request().Context(), userID)
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to get cart", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Successfully Get Cart!", cart)
+}
+
+// AddItem adds a menu or a bundling to the cart
+func (ch *CartHandler) AddItem(c echo.Context) (err error) {
+	var body domain.AddCartItemBody
+	userID := c.Get("userID").(int64)
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = ch.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	cart, err := ch.CartService.AddItem(c.Request().Context(), userID, body)
+	switch err {
+	case nil:
+	case domain.ErrBadParamInput:
+		return json.Response(c, http.StatusBadRequest, false, "Set either menuId or bundlingId", nil)
+	case domain.ErrNotFound:
+		return json.Response(c, http.StatusNotFound, false, "Menu or bundling not found", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to add item", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Item added to cart!", cart)
+}
+
+// UpdateItem changes the quantity of an item in the cart
+func (ch *CartHandler) UpdateItem(c echo.Context) (err error) {
+	var body domain.UpdateCartItemBody
+	userID := c.Get("userID").(int64)
+
+	itemID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid ID", nil)
+	}
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = ch.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	cart, err := ch.CartService.UpdateItem(c.Request().Context(), userID, itemID, body.Quantity)
+	return ch.itemResponse(c, cart, err, "Cart item updated!")
+}
+
+// RemoveItem removes an item from the cart
+func (ch *CartHandler) RemoveItem(c echo.Context) error {
+	userID := c.Get("userID").(int64)
+
+	itemID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid ID", nil)
+	}
+
+	cart, err := ch.CartService.RemoveItem(c.Request().Context(), userID, itemID)
+	return ch.itemResponse(c, cart, err, "Cart item removed!")
+}
+
+func (ch *CartHandler) itemResponse(c echo.Context, cart domain.Cart, err error, message string) error {
+	switch err {
+	case nil:
+		return json.Response(c, http.StatusOK, true, message, cart)
+	case domain.ErrNotFound:
+		return json.Response(c, http.StatusNotFound, false, "Cart item not found", nil)
+	case domain.ErrBadParamInput:
+		return json.Response(c, http.StatusBadRequest, false, "Invalid quantity", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to update cart", nil)
+	}
+}
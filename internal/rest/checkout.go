@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type CheckoutService interface {
+	Checkout(ctx context.Context, userID int64, body domain.CheckoutBody) (domain.Checkout, error)
+}
+
+// CheckoutHandler represent the httphandler for placing orders
+type CheckoutHandler struct {
+	CheckoutService CheckoutService
+	Validator       *validator.Validate
+}
+
+// NewCheckoutHandler will initialize the checkout/ resources endpoint. Only users
+// with a verified email address can place orders, retries carrying the same
+// Idempotency-Key get the first response instead of placing a second order.
+func NewCheckoutHandler(e *echo.Echo, checkoutService CheckoutService, emailChecker middleware.EmailVerificationChecker, idempotency middleware.IdempotencyService) {
+	handler := &CheckoutHandler{
+		CheckoutService: checkoutService,
+		Validator:       validator.New(),
+	}
+
+	e.POST("/checkout", handler.Checkout, middleware.AuthMiddleware, middleware.RequireVerifiedEmail(emailChecker), middleware.Idempotency(idempotency))
+}
+
+// Checkout places an order for the active cart of the authenticated user
+func (ch *CheckoutHandler) Checkout(c echo.Context) (err error) {
+	var body domain.CheckoutBody
+	userID := c.Get("userID").(int64)
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = ch.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	res, err := ch.CheckoutService.Checkout(c.Request().Context(), userID, body)
+	switch err {
+	case nil:
+	case domain.ErrBadParamInput:
+		return json.Response(c, http.StatusBadRequest, false, "Your cart is empty", nil)
+	case domain.ErrNotFound:
+		return json.Response(c, http.StatusNotFound, false, "Address or courier not found", nil)
+	case domain.ErrConflict:
+		return json.Response(c, http.StatusConflict, false, "Your cart changed while checking out, please review it and try again", nil)
+	case domain.ErrVoucherUnavailable, domain.ErrVoucherLimitReached, domain.ErrVoucherMinSpend, domain.ErrVoucherNotEligible:
+		return voucherError(c, err)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to checkout", nil)
+	}
+
+	return json.Response(c, http.StatusCreated, true, "Checkout successfully!", res)
+}
@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"net/http"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// DeviceHandler represent the httphandler for the devices a user is signed in on
+type DeviceHandler struct {
+	DeviceService DeviceService
+	TokenService  TokenService
+}
+
+// NewDeviceHandler will initialize the me/devices resources endpoint
+func NewDeviceHandler(e *echo.Echo, deviceService DeviceService, tokenService TokenService) {
+	handler := &DeviceHandler{
+		DeviceService: deviceService,
+		TokenService:  tokenService,
+	}
+
+	e.GET("/me/devices", handler.Fetch, middleware.AuthMiddleware)
+	e.DELETE("/me/devices/:id", handler.Delete, middleware.AuthMiddleware)
+}
+
+// Fetch lists the devices the user is signed in on, marking the current one
+func (dh *DeviceHandler) Fetch(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := c.Get("userID").(int64)
+
+	devices, err := dh.DeviceService.FetchByUserID(ctx, userID)
+	if err != nil {
+		return json.Response(c, http.StatusInternalServerError, false, "", err.Error())
+	}
+
+	if sessionID, _ := c.Get("sessionID").(string); sessionID != "" {
+		if current, err := dh.TokenService.GetByFamilyID(ctx, sessionID); err == nil {
+			for i := range devices {
+				devices[i].Current = devices[i].ID == current.DeviceID
+			}
+		}
+	}
+
+	return json.Response(c, http.StatusOK, true, "Successfully Get Devices!", devices)
+}
+
+// Delete signs the device out remotely by revoking its refresh token family
+func (dh *DeviceHandler) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := c.Get("userID").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "", "Invalid ID")
+	}
+
+	device, err := dh.DeviceService.GetByID(ctx, id)
+	if err == domain.ErrNotFound || (err == nil && device.UserID != userID) {
+		return json.Response(c, http.StatusNotFound, false, "", "Device not found")
+	}
+	if err != nil {
+		return json.Response(c, http.StatusInternalServerError, false, "", err.Error())
+	}
+
+	if err = dh.TokenService.DeleteByDeviceID(ctx, device.ID); err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "", "Failed to revoke device session")
+	}
+
+	if err = dh.DeviceService.Delete(ctx, device.ID); err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "", "Failed to sign out device")
+	}
+
+	return json.Response(c, http.StatusOK, true, "Successfully Sign Out Device!", nil)
+}
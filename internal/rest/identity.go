@@ -0,0 +1,144 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// IdentityService signs in with and links accounts of external identity providers
+type IdentityService interface {
+	SignIn(ctx context.Context, provider string, idToken string) (domain.User, error)
+	Link(ctx context.Context, userID int64, provider string, idToken string) (domain.UserIdentity, error)
+	FetchByUserID(ctx context.Context, userID int64) ([]domain.UserIdentity, error)
+	Unlink(ctx context.Context, user domain.User, provider string) error
+}
+
+// IdentityHandler represent the httphandler for the linked identities of the authenticated user
+type IdentityHandler struct {
+	IdentityService IdentityService
+	UserService     UserService
+	Validator       *validator.Validate
+}
+
+// NewIdentityHandler will initialize the me/identities resources endpoint
+func NewIdentityHandler(e *echo.Echo, identityService IdentityService, userService UserService) {
+	handler := &IdentityHandler{
+		IdentityService: identityService,
+		UserService:     userService,
+		Validator:       validator.New(),
+	}
+
+	e.GET("/me/identities", handler.Fetch, middleware.AuthMiddleware)
+	e.POST("/me/identities", handler.Link, middleware.AuthMiddleware)
+	e.DELETE("/me/identities/:provider", handler.Unlink, middleware.AuthMiddleware)
+}
+
+// LoginOidc signs in with the ID token of a social identity provider, creating or
+// linking the account on first use. A second factor is still asked when enabled.
+func (th *AuthHandler) LoginOidc(c echo.Context) (err error) {
+	var body domain.IdentityTokenBody
+	ctx := c.Request().Context()
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = th.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	user, err := th.Identities.SignIn(ctx, body.Provider, body.IDToken)
+	switch err {
+	case nil:
+	case domain.ErrInvalidToken:
+		return json.Response(c, http.StatusUnauthorized, false, "Invalid or expired ID token", nil)
+	case domain.ErrBadParamInput:
+		return json.Response(c, http.StatusBadRequest, false, "Unsupported provider or the ID token has no email", nil)
+	case domain.ErrConflict:
+		return json.Response(c, http.StatusConflict, false, "An account with this email already exists, sign in and link the provider from your profile", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to sign in", nil)
+	}
+
+	return th.startSession(c, user)
+}
+
+// Fetch lists the identity providers linked to the authenticated user
+func (ih *IdentityHandler) Fetch(c echo.Context) error {
+	userID := c.Get("userID").(int64)
+
+	identities, err := ih.IdentityService.FetchByUserID(c.Request().Context(), userID)
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to get identities", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Successfully Get Identities!", identities)
+}
+
+// Link adds the provider account of the ID token to the authenticated user
+func (ih *IdentityHandler) Link(c echo.Context) (err error) {
+	var body domain.IdentityTokenBody
+	userID := c.Get("userID").(int64)
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = ih.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	identity, err := ih.IdentityService.Link(c.Request().Context(), userID, body.Provider, body.IDToken)
+	switch err {
+	case nil:
+	case domain.ErrInvalidToken:
+		return json.Response(c, http.StatusUnauthorized, false, "Invalid or expired ID token", nil)
+	case domain.ErrBadParamInput:
+		return json.Response(c, http.StatusBadRequest, false, "Unsupported provider", nil)
+	case domain.ErrConflict:
+		return json.Response(c, http.StatusConflict, false, "This provider account is linked to another user, or another account of the provider is already linked", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to link identity", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Identity linked successfully!", identity)
+}
+
+// Unlink removes a provider from the authenticated user
+func (ih *IdentityHandler) Unlink(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := c.Get("userID").(int64)
+
+	user, err := ih.UserService.GetByID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusNotFound, false, "User not found", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to unlink identity", nil)
+	}
+
+	err = ih.IdentityService.Unlink(ctx, user, c.Param("provider"))
+	switch err {
+	case nil:
+	case domain.ErrNotFound:
+		return json.Response(c, http.StatusNotFound, false, "Identity not found", nil)
+	case domain.ErrConflict:
+		return json.Response(c, http.StatusConflict, false, "Set a password before removing your only way to sign in", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to unlink identity", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Identity unlinked successfully!", nil)
+}
@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+type InvoiceService interface {
+	Get(ctx context.Context, userID int64, orderID int64) (domain.Invoice, error)
+	PDF(invoice domain.Invoice) []byte
+}
+
+// InvoiceHandler represent the httphandler for invoices
+type InvoiceHandler struct {
+	InvoiceService InvoiceService
+}
+
+// NewInvoiceHandler will initialize the orders/:id/invoice resource endpoint
+func NewInvoiceHandler(e *echo.Echo, invoiceService InvoiceService) {
+	handler := &InvoiceHandler{
+		InvoiceService: invoiceService,
+	}
+
+	e.GET("/orders/:id/invoice", handler.GetByOrderID, middleware.AuthMiddleware)
+}
+
+// GetByOrderID returns the invoice of a paid order of the authenticated user, issuing
+// it on the first request. The invoice is JSON by default and a PDF when ?format=pdf
+// is given or the client only accepts application/pdf.
+func (ih *InvoiceHandler) GetByOrderID(c echo.Context) error {
+	userID := c.Get("userID").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid ID", nil)
+	}
+
+	format := c.QueryParam("format")
+	if format == "" && strings.HasPrefix(c.Request().Header.Get(echo.HeaderAccept), "application/pdf") {
+		format = "pdf"
+	}
+	if format != "" && format != "json" && format != "pdf" {
+		return json.Response(c, http.StatusBadRequest, false, "Unsupported format, use json or pdf", nil)
+	}
+
+	invoice, err := ih.InvoiceService.Get(c.Request().Context(), userID, id)
+	switch err {
+	case nil:
+	case domain.ErrNotFound:
+		return json.Response(c, http.StatusNotFound, false, "Order not found", nil)
+	case domain.ErrInvoiceUnavailable:
+		return json.Response(c, http.StatusConflict, false, "Invoices are issued once the order is paid", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to get invoice", nil)
+	}
+
+	if format != "pdf" {
+		return json.Response(c, http.StatusOK, true, "Successfully Get Invoice!", invoice)
+	}
+
+	filename := strings.ReplaceAll(invoice.Number, "/", "-") + ".pdf"
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", filename))
+	return c.Blob(http.StatusOK, "application/pdf", ih.InvoiceService.PDF(invoice))
+}
@@ -0,0 +1,28 @@
+package rest
+
+import (
+	"net/http"
+	"santapan/pkg/jwtkey"
+
+	"github.com/labstack/echo/v4"
+)
+
+// JWKSHandler publishes the public keys Santapan tokens are signed with
+type JWKSHandler struct {
+	KeyProvider jwtkey.Provider
+}
+
+// NewJWKSHandler will initialize the /.well-known/jwks.json endpoint
+func NewJWKSHandler(e *echo.Echo, keyProvider jwtkey.Provider) {
+	handler := &JWKSHandler{
+		KeyProvider: keyProvider,
+	}
+
+	e.GET("/.well-known/jwks.json", handler.Fetch)
+}
+
+// Fetch returns the key set in the standard JWKS format so other services can verify tokens
+func (jh *JWKSHandler) Fetch(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, jwtkey.PublicJWKS(jh.KeyProvider))
+}
@@ -0,0 +1,188 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// MfaService manages the TOTP second factor of users
+type MfaService interface {
+	IsEnabled(ctx context.Context, userID int64) (bool, error)
+	Enroll(ctx context.Context, userID int64, account string) (domain.MfaEnrollment, error)
+	Activate(ctx context.Context, userID int64, code string) ([]string, error)
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error)
+	Disable(ctx context.Context, userID int64, code string) error
+}
+
+// MfaHandler represent the httphandler for two-factor authentication
+type MfaHandler struct {
+	MfaService  MfaService
+	UserService UserService
+	Validator   *validator.Validate
+}
+
+// NewMfaHandler will initialize the mfa/ resources endpoint
+func NewMfaHandler(e *echo.Echo, mfaService MfaService, userService UserService) {
+	handler := &MfaHandler{
+		MfaService:  mfaService,
+		UserService: userService,
+		Validator:   validator.New(),
+	}
+
+	e.GET("/mfa", handler.Status, middleware.AuthMiddleware)
+	e.POST("/mfa/totp/enroll", handler.Enroll, middleware.AuthMiddleware)
+	e.POST("/mfa/totp/activate", handler.Activate, middleware.AuthMiddleware)
+	e.POST("/mfa/totp/disable", handler.Disable, middleware.AuthMiddleware)
+	e.POST("/mfa/recovery-codes", handler.RegenerateRecoveryCodes, middleware.AuthMiddleware)
+}
+
+// Status reports whether two-factor authentication is enabled
+func (mh *MfaHandler) Status(c echo.Context) error {
+	userID := c.Get("userID").(int64)
+
+	enabled, err := mh.MfaService.IsEnabled(c.Request().Context(), userID)
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to get two-factor authentication", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Successfully Get Two-Factor Authentication!", map[string]interface{}{
+		"enabled": enabled,
+	})
+}
+
+// Enroll creates a TOTP secret and its otpauth URI. It must be activated with a code before it is used.
+func (mh *MfaHandler) Enroll(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := c.Get("userID").(int64)
+
+	user, err := mh.UserService.GetByID(ctx, userID)
+	if err != nil {
+		return json.Response(c, http.StatusNotFound, false, "User not found", nil)
+	}
+
+	enrollment, err := mh.MfaService.Enroll(ctx, userID, user.Email)
+	switch err {
+	case nil:
+	case domain.ErrConflict:
+		return json.Response(c, http.StatusConflict, false, "Two-factor authentication is already enabled", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to enroll two-factor authentication", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Scan the code with your authenticator app, then activate it", enrollment)
+}
+
+// Activate enables two-factor authentication and returns the recovery codes
+func (mh *MfaHandler) Activate(c echo.Context) (err error) {
+	var body domain.MfaCodeBody
+	userID := c.Get("userID").(int64)
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = mh.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	codes, err := mh.MfaService.Activate(c.Request().Context(), userID, body.Code)
+	switch err {
+	case nil:
+	case domain.ErrNotFound:
+		return json.Response(c, http.StatusBadRequest, false, "Enroll two-factor authentication first", nil)
+	case domain.ErrConflict:
+		return json.Response(c, http.StatusConflict, false, "Two-factor authentication is already enabled", nil)
+	case domain.ErrInvalidOtp:
+		return json.Response(c, http.StatusBadRequest, false, "Invalid authentication code", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to activate two-factor authentication", nil)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":   "mfa_enabled",
+		"user_id": userID,
+	}).Info("Two-factor authentication enabled")
+
+	return json.Response(c, http.StatusOK, true, "Two-factor authentication enabled, store the recovery codes somewhere safe", map[string]interface{}{
+		"recovery_codes": codes,
+	})
+}
+
+// Disable turns two-factor authentication off after checking the password and a code
+func (mh *MfaHandler) Disable(c echo.Context) (err error) {
+	var body domain.DisableMfaBody
+	ctx := c.Request().Context()
+	userID := c.Get("userID").(int64)
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = mh.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	user, err := mh.UserService.GetByID(ctx, userID)
+	if err != nil {
+		return json.Response(c, http.StatusNotFound, false, "User not found", nil)
+	}
+
+	if !mh.UserService.VerifyPassword(ctx, user, body.Password) {
+		return json.Response(c, http.StatusBadRequest, false, "Password is incorrect", nil)
+	}
+
+	err = mh.MfaService.Disable(ctx, userID, body.Code)
+	switch err {
+	case nil:
+	case domain.ErrInvalidOtp:
+		return json.Response(c, http.StatusBadRequest, false, "Invalid authentication code", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to disable two-factor authentication", nil)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":   "mfa_disabled",
+		"user_id": userID,
+	}).Warn("Two-factor authentication disabled")
+
+	return json.Response(c, http.StatusOK, true, "Two-factor authentication disabled", nil)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes, the old ones stop working
+func (mh *MfaHandler) RegenerateRecoveryCodes(c echo.Context) (err error) {
+	var body domain.MfaCodeBody
+	userID := c.Get("userID").(int64)
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = mh.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	codes, err := mh.MfaService.RegenerateRecoveryCodes(c.Request().Context(), userID, body.Code)
+	switch err {
+	case nil:
+	case domain.ErrInvalidOtp:
+		return json.Response(c, http.StatusBadRequest, false, "Invalid authentication code", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to generate recovery codes", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Recovery codes generated, store them somewhere safe", map[string]interface{}{
+		"recovery_codes": codes,
+	})
+}
@@ -1,16 +1,50 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"santapan/pkg/json"
+	"santapan/pkg/jwtkey"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
-var jwtSecret = []byte("SANTAPANSECRET") // Replace with your secret key
+var keyProvider jwtkey.Provider
+
+// SetKeyProvider sets the keys tokens are verified against, shared with the token issuer
+func SetKeyProvider(provider jwtkey.Provider) {
+	keyProvider = provider
+}
+
+// Token types carried in the "typ" claim
+const (
+	AccessTokenType  = "access"
+	RefreshTokenType = "refresh"
+)
+
+// SessionChecker reports whether the session (refresh token family) an access token belongs to is still active
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, userID int64, sessionID string) (bool, error)
+}
+
+var sessionChecker SessionChecker
+
+// SetSessionChecker enables the server-side revocation check performed by AuthMiddleware
+func SetSessionChecker(checker SessionChecker) {
+	sessionChecker = checker
+}
+
+// ParseToken validates the signature and expiry of a token and returns its claims
+func ParseToken(tokenString string) (jwt.MapClaims, error) {
+	if keyProvider == nil {
+		return nil, fmt.Errorf("no key provider configured")
+	}
+
+	return jwtkey.Parse(keyProvider, tokenString)
+}
 
 // JWTMiddleware is a middleware for validating JWT tokens
 func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
@@ -27,21 +61,14 @@ func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 		}
 
 		// Parse and validate the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, err := ParseToken(tokenString)
+		if err != nil {
 			return json.Response(c, http.StatusUnauthorized, false, "Invalid or expired token", nil)
 		}
 
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return json.Response(c, http.StatusUnauthorized, false, "Invalid token claims", nil)
+		// Refresh tokens may only be exchanged at /token/refresh
+		if tokenType, _ := claims["typ"].(string); tokenType != "" && tokenType != AccessTokenType {
+			return json.Response(c, http.StatusUnauthorized, false, "Invalid token type", nil)
 		}
 
 		// Extract the user ID (sub) from the claims
@@ -50,8 +77,27 @@ func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 			return json.Response(c, http.StatusUnauthorized, false, "Invalid token claims", nil)
 		}
 
-		// Store user ID in the context
+		// Reject tokens whose session was logged out or revoked
+		sessionID, _ := claims["sid"].(string)
+		if sessionChecker != nil {
+			if sessionID == "" {
+				return json.Response(c, http.StatusUnauthorized, false, "Invalid or expired token", nil)
+			}
+
+			active, err := sessionChecker.IsSessionActive(c.Request().Context(), int64(userID), sessionID)
+			if err != nil {
+				return json.Response(c, http.StatusInternalServerError, false, "Failed to verify session", nil)
+			}
+			if !active {
+				return json.Response(c, http.StatusUnauthorized, false, "Session has been revoked", nil)
+			}
+		}
+
+		// Store user ID, session ID and the granted roles in the context
 		c.Set("userID", int64(userID))
+		c.Set("sessionID", sessionID)
+		c.Set("roles", claimStrings(claims["roles"]))
+		c.Set("permissions", claimStrings(claims["perms"]))
 
 		return next(c) // Call the next handler
 	}
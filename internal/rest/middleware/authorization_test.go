@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"santapan/pkg/jwtkey"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+func TestAuthMiddlewareTokenType(t *testing.T) {
+	keys, err := jwtkey.NewKeySet("test", jwtkey.NewHMACKey("test", []byte("secret")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetKeyProvider(keys)
+	SetSessionChecker(nil)
+	t.Cleanup(func() { SetKeyProvider(nil) })
+
+	tests := []struct {
+		name       string
+		typ        string
+		wantStatus int
+	}{
+		{"access token", AccessTokenType, http.StatusOK},
+		{"token from before typ existed", "", http.StatusOK},
+		{"refresh token", RefreshTokenType, http.StatusUnauthorized},
+		{"mfa pending token", "mfa_pending", http.StatusUnauthorized},
+		{"email verification token", "email_verification", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := jwt.MapClaims{"sub": float64(7), "exp": time.Now().Add(time.Minute).Unix()}
+			if tt.typ != "" {
+				claims["typ"] = tt.typ
+			}
+			token, err := jwtkey.Sign(keys, claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			err = AuthMiddleware(func(c echo.Context) error {
+				if got := c.Get("userID"); got != int64(7) {
+					t.Fatalf("got userID %v, want 7", got)
+				}
+				return c.NoContent(http.StatusOK)
+			})(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"santapan/domain"
+	"santapan/pkg/json"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client generated key of a retryable request
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed for a retried request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	maxIdempotentBody       = 1 << 20
+)
+
+// IdempotencyService remembers the responses of requests sent with an Idempotency-Key
+type IdempotencyService interface {
+	Begin(ctx context.Context, key string, fingerprint string) (domain.IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, fingerprint string, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, key string) error
+}
+
+// Idempotency makes retried requests safe. A request sent again with the same
+// Idempotency-Key gets the recorded response instead of being handled twice, the
+// key sent with a different request is rejected. Responses with a server error are
+// not recorded so the client can retry them. Requests without the header are handled
+// as usual. It must be placed after AuthMiddleware, keys are scoped to the user.
+func Idempotency(service IdempotencyService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			clientKey := c.Request().Header.Get(IdempotencyKeyHeader)
+			if clientKey == "" {
+				return next(c)
+			}
+			if len(clientKey) > maxIdempotencyKeyLength {
+				return json.Response(c, http.StatusBadRequest, false, "Idempotency-Key is too long", nil)
+			}
+
+			body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxIdempotentBody+1))
+			if err != nil {
+				return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+			}
+			if len(body) > maxIdempotentBody {
+				return json.Response(c, http.StatusRequestEntityTooLarge, false, "Request body is too large", nil)
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			userID, _ := c.Get("userID").(int64)
+			key := fmt.Sprintf("%d:%s", userID, clientKey)
+			fingerprint := requestFingerprint(c.Request(), body)
+			ctx := c.Request().Context()
+
+			record, err := service.Begin(ctx, key, fingerprint)
+			switch err {
+			case nil:
+			case domain.ErrIdempotencyKeyReused:
+				return json.Response(c, http.StatusUnprocessableEntity, false, "Idempotency-Key was already used for a different request", nil)
+			case domain.ErrConflict:
+				return json.Response(c, http.StatusConflict, false, "A request with this Idempotency-Key is still being processed", nil)
+			default:
+				logrus.Error(err)
+				return json.Response(c, http.StatusInternalServerError, false, "Failed to check Idempotency-Key", nil)
+			}
+
+			if record.Completed() {
+				c.Response().Header().Set(IdempotentReplayedHeader, "true")
+				return c.Blob(record.StatusCode, record.ContentType, record.Body)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			completed := false
+			defer func() {
+				c.Response().Writer = recorder.ResponseWriter
+				if !completed {
+					// The handler failed or panicked, free the key for a retry
+					if err := service.Release(context.WithoutCancel(ctx), key); err != nil {
+						logrus.Error(err)
+					}
+				}
+			}()
+
+			if err = next(c); err != nil {
+				return err
+			}
+
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				return nil
+			}
+
+			completed = true
+			contentType := c.Response().Header().Get(echo.HeaderContentType)
+			if err := service.Complete(context.WithoutCancel(ctx), key, fingerprint, status, contentType, recorder.body.Bytes()); err != nil {
+				// The response was already sent, a retry is rejected as in progress until the lease runs out
+				logrus.Error(err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// requestFingerprint identifies a request by its method, path and body
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder copies the response body while it is written to the client
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"santapan/pkg/json"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitByIP allows every client IP address, as resolved by the IP extractor, limit
+// requests per period with bursts of up to burst requests. Counters are kept in memory
+// per instance.
+func RateLimitByIP(limit int, period time.Duration, burst int) echo.MiddlewareFunc {
+	return echoMiddleware.RateLimiterWithConfig(echoMiddleware.RateLimiterConfig{
+		Store: echoMiddleware.NewRateLimiterMemoryStoreWithConfig(echoMiddleware.RateLimiterMemoryStoreConfig{
+			Rate:      rate.Limit(float64(limit) / period.Seconds()),
+			Burst:     burst,
+			ExpiresIn: period,
+		}),
+		DenyHandler: func(c echo.Context, identifier string, err error) error {
+			return json.Response(c, http.StatusTooManyRequests, false, "Too many requests, please try again later", nil)
+		},
+		ErrorHandler: func(c echo.Context, err error) error {
+			return json.Response(c, http.StatusForbidden, false, "Unable to identify the client", nil)
+		},
+	})
+}
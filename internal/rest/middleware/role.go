@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"santapan/pkg/json"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireRole rejects users holding none of the given roles.
+// It must be placed after AuthMiddleware.
+func RequireRole(roles ...string) echo.MiddlewareFunc {
+	return requireAny("roles", roles)
+}
+
+// RequirePermission rejects users granted none of the given permissions.
+// It must be placed after AuthMiddleware.
+func RequirePermission(permissions ...string) echo.MiddlewareFunc {
+	return requireAny("permissions", permissions)
+}
+
+// HasPermission reports whether the authenticated user was granted the permission
+func HasPermission(c echo.Context, permission string) bool {
+	granted, _ := c.Get("permissions").([]string)
+	for _, have := range granted {
+		if have == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func requireAny(contextKey string, allowed []string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := c.Get("userID").(int64); !ok {
+				return json.Response(c, http.StatusUnauthorized, false, "Unauthorized", nil)
+			}
+
+			granted, _ := c.Get(contextKey).([]string)
+			for _, have := range granted {
+				for _, want := range allowed {
+					if have == want {
+						return next(c)
+					}
+				}
+			}
+
+			return json.Response(c, http.StatusForbidden, false, "You do not have access to this resource", nil)
+		}
+	}
+}
+
+// claimStrings reads a claim holding a list of strings
+func claimStrings(value interface{}) []string {
+	list, _ := value.([]interface{})
+
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"santapan/pkg/json"
+
+	"github.com/labstack/echo/v4"
+)
+
+// EmailVerificationChecker reports whether a user confirmed their email address
+type EmailVerificationChecker interface {
+	IsEmailVerified(ctx context.Context, userID int64) (bool, error)
+}
+
+// RequireVerifiedEmail rejects users who have not verified their email address.
+// It must be placed after AuthMiddleware.
+func RequireVerifiedEmail(checker EmailVerificationChecker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, ok := c.Get("userID").(int64)
+			if !ok {
+				return json.Response(c, http.StatusUnauthorized, false, "Unauthorized", nil)
+			}
+
+			verified, err := checker.IsEmailVerified(c.Request().Context(), userID)
+			if err != nil {
+				return json.Response(c, http.StatusInternalServerError, false, "Failed to check email verification", nil)
+			}
+			if !verified {
+				return json.Response(c, http.StatusForbidden, false, "Please verify your email address first", nil)
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// Page size of the order history
+const (
+	defaultOrderPageSize = 10
+	maxOrderPageSize     = 50
+)
+
+type OrderService interface {
+	Fetch(ctx context.Context, userID int64, filter domain.OrderFilter, cursor string, num int64) ([]domain.Transaction, string, error)
+	GetDetail(ctx context.Context, userID int64, id int64) (domain.OrderDetail, error)
+	Timeline(ctx context.Context, userID int64, id int64) ([]domain.OrderStatusHistory, error)
+	Cancel(ctx context.Context, userID int64, id int64, note string) (domain.Transaction, error)
+	UpdateStatus(ctx context.Context, actor domain.OrderActor, id int64, status domain.OrderStatus, note string) (domain.Transaction, error)
+}
+
+// OrderHandler represent the httphandler for orders
+type OrderHandler struct {
+	OrderService OrderService
+	Validator    *validator.Validate
+}
+
+// NewOrderHandler will initialize the orders/ resources endpoint. Kitchen and courier
+// staff move orders through their lifecycle under /admin/orders.
+func NewOrderHandler(e *echo.Echo, orderService OrderService, idempotency middleware.IdempotencyService) {
+	handler := &OrderHandler{
+		OrderService: orderService,
+		Validator:    validator.New(),
+	}
+
+	e.GET("/orders", handler.Fetch, middleware.AuthMiddleware)
+	e.GET("/orders/:id", handler.GetByID, middleware.AuthMiddleware)
+	e.GET("/orders/:id/timeline", handler.Timeline, middleware.AuthMiddleware)
+	e.POST("/orders/:id/cancel", handler.Cancel, middleware.AuthMiddleware, middleware.Idempotency(idempotency))
+
+	admin := e.Group("/admin", middleware.AuthMiddleware, middleware.RequirePermission(domain.PermissionOrdersManage, domain.PermissionDeliveriesManage))
+	admin.PATCH("/orders/:id/status", handler.UpdateStatus)
+}
+
+// Fetch lists the orders of the authenticated user, newest first. The optional
+// status query parameter takes a comma separated list, from and to are dates
+// (YYYY-MM-DD) and both days are included.
+func (oh *OrderHandler) Fetch(c echo.Context) error {
+	userID := c.Get("userID").(int64)
+
+	num := int64(defaultOrderPageSize)
+	if value := c.QueryParam("num"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 1 {
+			return json.Response(c, http.StatusBadRequest, false, "Invalid Num", nil)
+		}
+		num = parsed
+	}
+	if num > maxOrderPageSize {
+		num = maxOrderPageSize
+	}
+
+	filter, err := orderFilter(c)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, err.Error(), nil)
+	}
+
+	orders, nextCursor, err := oh.OrderService.Fetch(c.Request().Context(), userID, filter, c.QueryParam("cursor"), num)
+	if err == domain.ErrBadParamInput {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid cursor", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to get orders", nil)
+	}
+
+	responseData := map[string]interface{}{
+		"orders":     orders,
+		"nextCursor": nextCursor,
+	}
+
+	return json.Response(c, http.StatusOK, true, "Successfully Get Orders!", responseData)
+}
+
+// GetByID returns an order of the authenticated user with its items, address,
+// courier, payment and timeline
+func (oh *OrderHandler) GetByID(c echo.Context) error {
+	userID := c.Get("userID").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid ID", nil)
+	}
+
+	order, err := oh.OrderService.GetDetail(c.Request().Context(), userID, id)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusNotFound, false, "Order not found", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to get order", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Successfully Get Order!", order)
+}
+
+// Timeline returns the status changes of an order of the authenticated user
+func (oh *OrderHandler) Timeline(c echo.Context) error {
+	userID := c.Get("userID").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid ID", nil)
+	}
+
+	timeline, err := oh.OrderService.Timeline(c.Request().Context(), userID, id)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusNotFound, false, "Order not found", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to get order timeline", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Successfully Get Order Timeline!", timeline)
+}
+
+// Cancel cancels an order of the authenticated user that has not been paid yet
+func (oh *OrderHandler) Cancel(c echo.Context) (err error) {
+	var body domain.CancelOrderBody
+	userID := c.Get("userID").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid ID", nil)
+	}
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = oh.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	order, err := oh.OrderService.Cancel(c.Request().Context(), userID, id, body.Note)
+	switch err {
+	case nil:
+	case domain.ErrNotFound:
+		return json.Response(c, http.StatusNotFound, false, "Order not found", nil)
+	case domain.ErrInvalidTransition:
+		return json.Response(c, http.StatusConflict, false, "Only orders that are not paid yet can be cancelled", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to cancel order", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Order cancelled!", order)
+}
+
+// UpdateStatus moves an order to the next status of its lifecycle. Couriers may only
+// hand orders over, the other changes need the orders:manage permission.
+func (oh *OrderHandler) UpdateStatus(c echo.Context) (err error) {
+	var body domain.UpdateOrderStatusBody
+	userID := c.Get("userID").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid ID", nil)
+	}
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = oh.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	if !middleware.HasPermission(c, domain.OrderStatusPermission(body.Status)) {
+		return json.Response(c, http.StatusForbidden, false, "You do not have access to this resource", nil)
+	}
+
+	actor := domain.OrderActor{Type: domain.OrderActorStaff, ID: userID}
+	order, err := oh.OrderService.UpdateStatus(c.Request().Context(), actor, id, body.Status, body.Note)
+	switch err {
+	case nil:
+	case domain.ErrBadParamInput:
+		return json.Response(c, http.StatusBadRequest, false, "Unknown order status", nil)
+	case domain.ErrNotFound:
+		return json.Response(c, http.StatusNotFound, false, "Order not found", nil)
+	case domain.ErrInvalidTransition:
+		return json.Response(c, http.StatusConflict, false, "Order can not move to status "+string(body.Status), nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to update order status", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Order status updated!", order)
+}
+
+// orderFilter reads the status and date range filters of the order history
+func orderFilter(c echo.Context) (filter domain.OrderFilter, err error) {
+	if value := c.QueryParam("status"); value != "" {
+		for _, name := range strings.Split(value, ",") {
+			status := domain.OrderStatus(strings.TrimSpace(name))
+			if !status.Valid() {
+				return filter, fmt.Errorf("Unknown order status %q", name)
+			}
+			filter.Statuses = append(filter.Statuses, status)
+		}
+	}
+
+	if value := c.QueryParam("from"); value != "" {
+		if filter.From, err = time.Parse(time.DateOnly, value); err != nil {
+			return filter, fmt.Errorf("Invalid from date, use YYYY-MM-DD")
+		}
+	}
+
+	if value := c.QueryParam("to"); value != "" {
+		to, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return filter, fmt.Errorf("Invalid to date, use YYYY-MM-DD")
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, fmt.Errorf("The from date must not be after the to date")
+	}
+
+	return filter, nil
+}
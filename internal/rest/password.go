@@ -0,0 +1,185 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+	"santapan/pkg/mailer"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// An IP address may ask for this many reset links per hour, the per-account limit is
+// enforced by the password reset service
+const (
+	forgotPasswordIPLimit = 20
+	forgotPasswordIPBurst = 5
+)
+
+type PasswordResetService interface {
+	Create(ctx context.Context, userID int64) (string, error)
+	Consume(ctx context.Context, token string) (int64, error)
+}
+
+// PasswordHandler represent the httphandler for password recovery and change
+type PasswordHandler struct {
+	UserService          UserService
+	TokenService         TokenService
+	PasswordResetService PasswordResetService
+	Mailer               mailer.Mailer
+	Validator            *validator.Validate
+}
+
+// NewPasswordHandler will initialize the password/ resources endpoint
+func NewPasswordHandler(e *echo.Echo, userService UserService, tokenService TokenService, passwordResetService PasswordResetService, mailer mailer.Mailer) {
+	handler := &PasswordHandler{
+		UserService:          userService,
+		TokenService:         tokenService,
+		PasswordResetService: passwordResetService,
+		Mailer:               mailer,
+		Validator:            newValidator(),
+	}
+
+	e.POST("/password/forgot", handler.Forgot, middleware.RateLimitByIP(forgotPasswordIPLimit, time.Hour, forgotPasswordIPBurst))
+	e.POST("/password/reset", handler.Reset)
+	e.POST("/password/change", handler.Change, middleware.AuthMiddleware)
+}
+
+// Forgot mails a password reset link. The response is the same whether or not
+// the email is registered so the endpoint can not be used to discover accounts,
+// failures for a registered email are only logged. Links are throttled per account
+// and per IP address. Sessions are revoked once the reset is used, not when it is
+// requested, or anyone knowing an email could sign its owner out.
+func (ph *PasswordHandler) Forgot(c echo.Context) (err error) {
+	var forgotBody domain.ForgotPasswordBody
+	ctx := c.Request().Context()
+
+	if err = c.Bind(&forgotBody); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = ph.Validator.Struct(forgotBody); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	const message = "If the email is registered, a password reset link has been sent"
+
+	user, err := ph.UserService.GetByEmail(ctx, forgotBody.Email)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusOK, true, message, nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to process request", nil)
+	}
+
+	token, err := ph.PasswordResetService.Create(ctx, user.ID)
+	if err == domain.ErrTooManyAttempts {
+		// A link was sent moments ago, answering differently would reveal the account
+		return json.Response(c, http.StatusOK, true, message, nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusOK, true, message, nil)
+	}
+
+	link := fmt.Sprintf("%s?token=%s", passwordResetURL(), url.QueryEscape(token))
+	err = ph.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Santapan password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link expires in 1 hour and can be used once. If you did not request this, you can ignore this email.\n",
+			user.FullName, link),
+	})
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusOK, true, message, nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, message, nil)
+}
+
+// Reset consumes a reset token, sets the new password and signs out every session
+func (ph *PasswordHandler) Reset(c echo.Context) (err error) {
+	var resetBody domain.ResetPasswordBody
+	ctx := c.Request().Context()
+
+	if err = c.Bind(&resetBody); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = ph.Validator.Struct(resetBody); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, validationMessage(err), nil)
+	}
+
+	userID, err := ph.PasswordResetService.Consume(ctx, resetBody.Token)
+	if err == domain.ErrInvalidToken {
+		return json.Response(c, http.StatusBadRequest, false, "Reset token is invalid or has expired", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to reset password", nil)
+	}
+
+	if err = ph.setPassword(ctx, userID, resetBody.Password); err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to reset password", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Password reset successfully, please login again", nil)
+}
+
+// Change sets a new password after checking the current one and signs out every session
+func (ph *PasswordHandler) Change(c echo.Context) (err error) {
+	var changeBody domain.ChangePasswordBody
+	ctx := c.Request().Context()
+	userID := c.Get("userID").(int64)
+
+	if err = c.Bind(&changeBody); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = ph.Validator.Struct(changeBody); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, validationMessage(err), nil)
+	}
+
+	user, err := ph.UserService.GetByID(ctx, userID)
+	if err != nil {
+		return json.Response(c, http.StatusNotFound, false, "User not found", nil)
+	}
+
+	if !ph.UserService.VerifyPassword(ctx, user, changeBody.CurrentPassword) {
+		return json.Response(c, http.StatusBadRequest, false, "Current password is incorrect", nil)
+	}
+
+	if err = ph.setPassword(ctx, userID, changeBody.Password); err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to change password", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Password changed successfully, please login again", nil)
+}
+
+// setPassword hashes and stores the password, then revokes every refresh token of the user
+func (ph *PasswordHandler) setPassword(ctx context.Context, userID int64, password string) error {
+	if err := ph.UserService.UpdatePassword(ctx, userID, password); err != nil {
+		return err
+	}
+
+	return ph.TokenService.DeleteByUserID(ctx, userID)
+}
+
+// passwordResetURL returns the page the reset link points to
+func passwordResetURL() string {
+	if u := os.Getenv("PASSWORD_RESET_URL"); u != "" {
+		return u
+	}
+	return AppURL() + "/password/reset"
+}
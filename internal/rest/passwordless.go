@@ -0,0 +1,217 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"santapan/domain"
+	"santapan/magiclink"
+	"santapan/pkg/json"
+	"santapan/pkg/mailer"
+	"santapan/pkg/phone"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// RequestMagicLink mails a single-use sign-in link. The response is the same whether
+// or not the email is registered so the endpoint can not be used to discover accounts.
+func (th *AuthHandler) RequestMagicLink(c echo.Context) (err error) {
+	var body domain.MagicLinkBody
+	ctx := c.Request().Context()
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = th.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	const message = "If the email is registered, a sign-in link has been sent"
+
+	user, err := th.UserService.GetByEmail(ctx, body.Email)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusOK, true, message, nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to process request", nil)
+	}
+
+	token, err := th.MagicLinks.Create(ctx, user.ID)
+	if err == domain.ErrTooManyAttempts {
+		// A link was sent moments ago, answering differently would reveal the account
+		return json.Response(c, http.StatusOK, true, message, nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to create sign-in link", nil)
+	}
+
+	link := fmt.Sprintf("%s?token=%s", magicLinkURL(), url.QueryEscape(token))
+	err = th.Mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Sign in to Santapan",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen the link below to sign in to your Santapan account:\n\n%s\n\nThe link expires in %d minutes and can be used once. If you did not request this, you can ignore this email.\n",
+			user.FullName, link, int(magiclink.TokenTTL.Minutes())),
+	})
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to send sign-in email", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, message, nil)
+}
+
+// LoginMagicLink consumes a sign-in link token and continues the login. Opening the
+// link proves control of the mailbox, so an unverified email becomes verified.
+func (th *AuthHandler) LoginMagicLink(c echo.Context) (err error) {
+	var body domain.MagicLinkLoginBody
+	ctx := c.Request().Context()
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = th.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	userID, err := th.MagicLinks.Consume(ctx, body.Token)
+	if err == domain.ErrInvalidToken {
+		return json.Response(c, http.StatusBadRequest, false, "Sign-in link is invalid or has expired", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to sign in", nil)
+	}
+
+	user, err := th.UserService.GetByID(ctx, userID)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Sign-in link is invalid or has expired", nil)
+	}
+
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		if err = th.UserService.UpdateEmailVerifiedAt(ctx, user.ID, now); err != nil {
+			logrus.Error(err)
+			return json.Response(c, http.StatusInternalServerError, false, "Failed to sign in", nil)
+		}
+		user.EmailVerifiedAt = &now
+	}
+
+	return th.startSession(c, user)
+}
+
+// RequestLoginOtp sends a sign-in code to a verified phone number. The response is
+// the same whether or not the number belongs to an account.
+func (th *AuthHandler) RequestLoginOtp(c echo.Context) (err error) {
+	var body domain.VerifyPhoneBody
+	ctx := c.Request().Context()
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = th.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	phoneNumber, err := phone.Normalize(body.CountryCode, body.Phone)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid phone number", nil)
+	}
+
+	const message = "If the phone number is registered, a sign-in code has been sent"
+
+	user, err := th.UserService.GetByPhone(ctx, phoneNumber)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusOK, true, message, nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to process request", nil)
+	}
+
+	err = th.OtpService.Request(ctx, user.ID, phoneNumber, body.Type, c.Request().Header.Get("deviceid"))
+	switch err {
+	case nil, domain.ErrTooManyAttempts:
+		// A code sent moments ago stays valid, answering differently would reveal the account
+	case domain.ErrBadParamInput:
+		return json.Response(c, http.StatusBadRequest, false, "Unsupported OTP channel", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to send OTP", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, message, nil)
+}
+
+// LoginOtp signs in with a phone number and the code sent to it. Wrong codes count
+// towards the same lockout as wrong passwords.
+func (th *AuthHandler) LoginOtp(c echo.Context) (err error) {
+	var body domain.VerifyOtpBody
+	ctx := c.Request().Context()
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = th.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	phoneNumber, err := phone.Normalize(body.CountryCode, body.Phone)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid phone number", nil)
+	}
+
+	ip := c.RealIP()
+
+	// Unknown numbers are throttled under the number itself, known ones under the account
+	user, err := th.UserService.GetByPhone(ctx, phoneNumber)
+	if err != nil && err != domain.ErrNotFound {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to sign in", nil)
+	}
+	subject := phoneNumber
+	if err == nil {
+		subject = user.Email
+	}
+
+	if retryAfter, err := th.LoginAttempts.Check(ctx, subject, ip); err != nil {
+		if err == domain.ErrTooManyAttempts {
+			return tooManyLoginAttempts(c, retryAfter)
+		}
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to check login attempts", nil)
+	}
+
+	if user.ID == 0 {
+		return th.loginFailed(c, subject, ip, "OTP is invalid or has expired")
+	}
+
+	err = th.OtpService.Verify(ctx, user.ID, phoneNumber, body.Code)
+	switch err {
+	case nil:
+	case domain.ErrInvalidOtp:
+		return th.loginFailed(c, subject, ip, "OTP is invalid or has expired")
+	case domain.ErrTooManyAttempts:
+		return th.loginFailed(c, subject, ip, "Too many wrong codes, please request a new OTP")
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to verify OTP", nil)
+	}
+
+	return th.startSession(c, user)
+}
+
+func magicLinkURL() string {
+	if u := os.Getenv("MAGIC_LINK_URL"); u != "" {
+		return u
+	}
+	return AppURL() + "/login/magic-link"
+}
@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// maxWebhookBody limits the size of a payment webhook callback
+const maxWebhookBody = 1 << 20
+
+type PaymentService interface {
+	GetByID(ctx context.Context, userID int64, id int64) (domain.Payment, error)
+	StartSession(ctx context.Context, payment *domain.Payment) error
+	HandleWebhook(ctx context.Context, header http.Header, body []byte) error
+}
+
+// PaymentHandler represent the httphandler for payments and payment provider callbacks
+type PaymentHandler struct {
+	PaymentService PaymentService
+}
+
+// NewPaymentHandler will initialize the payments/ resources endpoint. A provider that
+// serves its own hosted pages, like the fake provider, is mounted under /payments/fake.
+// The fake provider only exists in development, see paymentgateway.NewFromEnv.
+func NewPaymentHandler(e *echo.Echo, paymentService PaymentService, provider interface{}, idempotency middleware.IdempotencyService) {
+	handler := &PaymentHandler{
+		PaymentService: paymentService,
+	}
+
+	e.POST("/payments/webhook", handler.Webhook)
+	e.GET("/payments/:id", handler.GetByID, middleware.AuthMiddleware)
+	e.POST("/payments/:id/session", handler.StartSession, middleware.AuthMiddleware, middleware.Idempotency(idempotency))
+
+	if pages, ok := provider.(http.Handler); ok {
+		e.Any("/payments/fake/*", echo.WrapHandler(http.StripPrefix("/payments/fake", pages)))
+	}
+}
+
+// GetByID returns a payment of the authenticated user
+func (ph *PaymentHandler) GetByID(c echo.Context) error {
+	userID := c.Get("userID").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid ID", nil)
+	}
+
+	payment, err := ph.PaymentService.GetByID(c.Request().Context(), userID, id)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusNotFound, false, "Payment not found", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to get payment", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Successfully Get Payment!", payment)
+}
+
+// StartSession creates the hosted payment page of an unpaid payment whose session
+// could not be created at checkout. An existing session is returned as is so a
+// payment can never be paid twice.
+func (ph *PaymentHandler) StartSession(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := c.Get("userID").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid ID", nil)
+	}
+
+	payment, err := ph.PaymentService.GetByID(ctx, userID, id)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusNotFound, false, "Payment not found", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to get payment", nil)
+	}
+
+	if payment.SessionID != "" {
+		return json.Response(c, http.StatusOK, true, "Payment session already exists", payment)
+	}
+
+	err = ph.PaymentService.StartSession(ctx, &payment)
+	switch err {
+	case nil:
+	case domain.ErrConflict, domain.ErrNotFound:
+		return json.Response(c, http.StatusConflict, false, "Payment is already settled", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusBadGateway, false, "Failed to create payment session", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Payment session created!", payment)
+}
+
+// Webhook receives status callbacks of the payment provider. The signature is checked
+// by the provider before anything is changed.
+func (ph *PaymentHandler) Webhook(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxWebhookBody))
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	err = ph.PaymentService.HandleWebhook(c.Request().Context(), c.Request().Header, body)
+	switch err {
+	case nil:
+	case domain.ErrInvalidToken:
+		return json.Response(c, http.StatusUnauthorized, false, "Invalid signature", nil)
+	case domain.ErrNotFound:
+		return json.Response(c, http.StatusNotFound, false, "Payment not found", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to process webhook", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Webhook processed", nil)
+}
@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+	"santapan/pkg/phone"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type OtpService interface {
+	Request(ctx context.Context, userID int64, phone string, channel domain.OtpType, deviceID string) error
+	Verify(ctx context.Context, userID int64, phone string, code string) error
+}
+
+// PhoneHandler represent the httphandler for phone verification
+type PhoneHandler struct {
+	OtpService  OtpService
+	UserService UserService
+	Validator   *validator.Validate
+}
+
+// NewPhoneHandler will initialize the phone/ resources endpoint
+func NewPhoneHandler(e *echo.Echo, otpService OtpService, userService UserService) {
+	handler := &PhoneHandler{
+		OtpService:  otpService,
+		UserService: userService,
+		Validator:   validator.New(),
+	}
+
+	e.POST("/phone/otp", handler.RequestOtp, middleware.AuthMiddleware)
+	e.POST("/phone/verify", handler.VerifyOtp, middleware.AuthMiddleware)
+}
+
+// RequestOtp sends a verification code to the phone number over SMS or WhatsApp
+func (ph *PhoneHandler) RequestOtp(c echo.Context) (err error) {
+	var verifyPhoneBody domain.VerifyPhoneBody
+	ctx := c.Request().Context()
+	userID := c.Get("userID").(int64)
+
+	if err = c.Bind(&verifyPhoneBody); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = ph.Validator.Struct(verifyPhoneBody); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	phoneNumber, err := phone.Normalize(verifyPhoneBody.CountryCode, verifyPhoneBody.Phone)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid phone number", nil)
+	}
+
+	err = ph.OtpService.Request(ctx, userID, phoneNumber, verifyPhoneBody.Type, c.Request().Header.Get("deviceid"))
+	switch err {
+	case nil:
+	case domain.ErrTooManyAttempts:
+		return json.Response(c, http.StatusTooManyRequests, false, "Please wait before requesting another code", nil)
+	case domain.ErrBadParamInput:
+		return json.Response(c, http.StatusBadRequest, false, "Unsupported OTP channel", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to send OTP", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "OTP sent successfully!", nil)
+}
+
+// VerifyOtp checks the code and marks the phone number as verified
+func (ph *PhoneHandler) VerifyOtp(c echo.Context) (err error) {
+	var verifyOtpBody domain.VerifyOtpBody
+	ctx := c.Request().Context()
+	userID := c.Get("userID").(int64)
+
+	if err = c.Bind(&verifyOtpBody); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = ph.Validator.Struct(verifyOtpBody); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	phoneNumber, err := phone.Normalize(verifyOtpBody.CountryCode, verifyOtpBody.Phone)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid phone number", nil)
+	}
+
+	err = ph.OtpService.Verify(ctx, userID, phoneNumber, verifyOtpBody.Code)
+	switch err {
+	case nil:
+	case domain.ErrInvalidOtp:
+		return json.Response(c, http.StatusBadRequest, false, "OTP is invalid or has expired", nil)
+	case domain.ErrTooManyAttempts:
+		return json.Response(c, http.StatusTooManyRequests, false, "Too many wrong codes, please request a new OTP", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to verify OTP", nil)
+	}
+
+	err = ph.UserService.UpdatePhoneVerifiedAt(ctx, userID, phoneNumber, time.Now())
+	if err == domain.ErrConflict {
+		return json.Response(c, http.StatusConflict, false, "Phone number is already used by another account", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to verify phone number", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Phone number verified successfully!", nil)
+}
@@ -0,0 +1,149 @@
+package rest
+
+import (
+	"net/http"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+	"santapan/pkg/jwtkey"
+	"santapan/pkg/mailer"
+	"santapan/pkg/phone"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// ProfileHandler represent the httphandler for the profile of the authenticated user
+type ProfileHandler struct {
+	UserService UserService
+	KeyProvider jwtkey.Provider
+	Mailer      mailer.Mailer
+	Reauth      Reauthenticator
+	Validator   *validator.Validate
+}
+
+// NewProfileHandler will initialize the me/ resources endpoint
+func NewProfileHandler(e *echo.Echo, userService UserService, keyProvider jwtkey.Provider, mailer mailer.Mailer, reauth Reauthenticator) {
+	handler := &ProfileHandler{
+		UserService: userService,
+		Reauth:      reauth,
+		KeyProvider: keyProvider,
+		Mailer:      mailer,
+		Validator:   validator.New(),
+	}
+
+	e.GET("/me", handler.Get, middleware.AuthMiddleware)
+	e.PATCH("/me", handler.Update, middleware.AuthMiddleware)
+	e.POST("/me/email", handler.ChangeEmail, middleware.AuthMiddleware)
+}
+
+// Get returns the profile of the authenticated user
+func (ph *ProfileHandler) Get(c echo.Context) error {
+	userID := c.Get("userID").(int64)
+
+	user, err := ph.UserService.GetByID(c.Request().Context(), userID)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusNotFound, false, "User not found", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to get profile", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Successfully Get Profile!", user.Response())
+}
+
+// Update changes the name, phone number or avatar of the authenticated user
+func (ph *ProfileHandler) Update(c echo.Context) (err error) {
+	var body domain.UpdateProfileBody
+	userID := c.Get("userID").(int64)
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = ph.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	var fullName *string
+	if body.FullName != nil {
+		trimmed := strings.TrimSpace(*body.FullName)
+		if trimmed == "" {
+			return json.Response(c, http.StatusBadRequest, false, "Full name can not be empty", nil)
+		}
+		fullName = &trimmed
+	}
+
+	var phoneNumber *string
+	if body.Phone != nil || body.CountryCode != nil {
+		if body.Phone == nil || body.CountryCode == nil {
+			return json.Response(c, http.StatusBadRequest, false, "Phone and countryCode must be sent together", nil)
+		}
+
+		normalized, err := phone.Normalize(*body.CountryCode, *body.Phone)
+		if err != nil {
+			return json.Response(c, http.StatusBadRequest, false, "Invalid phone number", nil)
+		}
+		phoneNumber = &normalized
+	}
+
+	user, err := ph.UserService.UpdateProfile(c.Request().Context(), userID, fullName, phoneNumber, body.AvatarURL)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusNotFound, false, "User not found", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to update profile", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Profile updated successfully!", user.Response())
+}
+
+// ChangeEmail starts an email change. The current address stays in use until
+// the link sent to the new address is opened.
+func (ph *ProfileHandler) ChangeEmail(c echo.Context) (err error) {
+	var body domain.ChangeEmailBody
+	ctx := c.Request().Context()
+	userID := c.Get("userID").(int64)
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = ph.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	user, err := ph.UserService.GetByID(ctx, userID)
+	if err != nil {
+		return json.Response(c, http.StatusNotFound, false, "User not found", nil)
+	}
+
+	if err = ph.Reauth.Check(ctx, user, body.ReauthBody); err != nil {
+		return reauthFailed(c, body.ReauthBody, err)
+	}
+
+	if body.Email == user.Email {
+		return json.Response(c, http.StatusBadRequest, false, "This is already your email address", nil)
+	}
+
+	err = ph.UserService.RequestEmailChange(ctx, userID, body.Email)
+	switch err {
+	case nil:
+	case domain.ErrConflict:
+		return json.Response(c, http.StatusConflict, false, "Email has already taken", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to change email", nil)
+	}
+
+	if err = sendVerificationLink(ctx, ph.KeyProvider, ph.Mailer, ph.UserService, user, body.Email); err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to send verification email", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Verification email sent to the new address!", nil)
+}
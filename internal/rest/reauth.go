@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"santapan/domain"
+	"santapan/pkg/json"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// ReauthIdentityService checks ID tokens of the providers linked to a user
+type ReauthIdentityService interface {
+	Reauthenticate(ctx context.Context, userID int64, provider string, idToken string) error
+}
+
+// Reauthenticator confirms the signed in user is present before a sensitive change.
+// Accounts created through an identity provider or magic links have no password, they
+// confirm with a fresh magic link or an ID token of a linked provider instead.
+type Reauthenticator struct {
+	UserService UserService
+	Identities  ReauthIdentityService
+	MagicLinks  MagicLinkService
+}
+
+// Check verifies the proof for the user. Returns domain.ErrBadParamInput when no proof
+// is given and domain.ErrInvalidToken when it does not hold.
+func (r Reauthenticator) Check(ctx context.Context, user domain.User, proof domain.ReauthBody) error {
+	switch {
+	case proof.Password != "":
+		if !r.UserService.VerifyPassword(ctx, user, proof.Password) {
+			return domain.ErrInvalidToken
+		}
+		return nil
+	case proof.MagicLinkToken != "":
+		userID, err := r.MagicLinks.Consume(ctx, proof.MagicLinkToken)
+		if err != nil {
+			return err
+		}
+		if userID != user.ID {
+			return domain.ErrInvalidToken
+		}
+		return nil
+	case proof.Provider != "" && proof.IDToken != "":
+		return r.Identities.Reauthenticate(ctx, user.ID, proof.Provider, proof.IDToken)
+	default:
+		return domain.ErrBadParamInput
+	}
+}
+
+// reauthFailed answers a failed Check, the message names the proof that was given
+func reauthFailed(c echo.Context, proof domain.ReauthBody, err error) error {
+	switch {
+	case err == domain.ErrBadParamInput:
+		return json.Response(c, http.StatusBadRequest, false, "Confirm with your password, a sign-in link token or an ID token of a linked provider", nil)
+	case err == domain.ErrInvalidToken && proof.Password != "":
+		return json.Response(c, http.StatusBadRequest, false, "Password is incorrect", nil)
+	case err == domain.ErrInvalidToken:
+		return json.Response(c, http.StatusBadRequest, false, "Invalid or expired confirmation", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to confirm your identity", nil)
+	}
+}
@@ -0,0 +1,156 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// RoleManagementService manages the roles assigned to users
+type RoleManagementService interface {
+	GetAccess(ctx context.Context, userID int64) (domain.Access, error)
+	Assign(ctx context.Context, userID int64, name string) error
+	Revoke(ctx context.Context, userID int64, name string) error
+}
+
+// RoleHandler represent the httphandler for managing user roles
+type RoleHandler struct {
+	RoleService  RoleManagementService
+	UserService  UserService
+	TokenService TokenService
+	Validator    *validator.Validate
+}
+
+// NewRoleHandler will initialize the admin/users/:id/roles resources endpoint
+func NewRoleHandler(e *echo.Echo, roleService RoleManagementService, userService UserService, tokenService TokenService) {
+	handler := &RoleHandler{
+		RoleService:  roleService,
+		UserService:  userService,
+		TokenService: tokenService,
+		Validator:    validator.New(),
+	}
+
+	admin := e.Group("/admin", middleware.AuthMiddleware, middleware.RequirePermission(domain.PermissionUsersManage))
+	admin.GET("/users/:id/roles", handler.Fetch)
+	admin.POST("/users/:id/roles", handler.Assign)
+	admin.DELETE("/users/:id/roles/:role", handler.Revoke)
+}
+
+// Fetch lists the roles and permissions of a user
+func (rh *RoleHandler) Fetch(c echo.Context) error {
+	userID, err := rh.targetUser(c)
+	if err != nil {
+		return targetUserError(c, err)
+	}
+
+	access, err := rh.RoleService.GetAccess(c.Request().Context(), userID)
+	if err != nil {
+		return json.Response(c, http.StatusInternalServerError, false, "", err.Error())
+	}
+
+	return json.Response(c, http.StatusOK, true, "Successfully Get Roles!", access)
+}
+
+// Assign grants a role to a user. It takes effect on the user's next token refresh.
+func (rh *RoleHandler) Assign(c echo.Context) error {
+	var body domain.AssignRoleBody
+
+	userID, err := rh.targetUser(c)
+	if err != nil {
+		return targetUserError(c, err)
+	}
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = rh.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	err = rh.RoleService.Assign(c.Request().Context(), userID, body.Role)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusNotFound, false, "Role not found", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to assign role", nil)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":    "role_assigned",
+		"actor_id": c.Get("userID"),
+		"user_id":  userID,
+		"role":     body.Role,
+	}).Info("Role assigned")
+
+	return json.Response(c, http.StatusOK, true, "Role assigned successfully!", nil)
+}
+
+// Revoke removes a role from a user and signs them out everywhere, so access
+// tokens still carrying the role stop working immediately
+func (rh *RoleHandler) Revoke(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userID, err := rh.targetUser(c)
+	if err != nil {
+		return targetUserError(c, err)
+	}
+
+	role := c.Param("role")
+	err = rh.RoleService.Revoke(ctx, userID, role)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusNotFound, false, "Role not found", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to revoke role", nil)
+	}
+
+	if err = rh.TokenService.DeleteByUserID(ctx, userID); err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to revoke sessions", nil)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":    "role_revoked",
+		"actor_id": c.Get("userID"),
+		"user_id":  userID,
+		"role":     role,
+	}).Info("Role revoked")
+
+	return json.Response(c, http.StatusOK, true, "Role revoked successfully!", nil)
+}
+
+// targetUser parses the :id parameter and checks the user exists
+func (rh *RoleHandler) targetUser(c echo.Context) (int64, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, domain.ErrBadParamInput
+	}
+
+	if _, err = rh.UserService.GetByID(c.Request().Context(), id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func targetUserError(c echo.Context, err error) error {
+	switch err {
+	case domain.ErrBadParamInput:
+		return json.Response(c, http.StatusBadRequest, false, "", "Invalid ID")
+	case domain.ErrNotFound:
+		return json.Response(c, http.StatusNotFound, false, "User not found", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to get user", nil)
+	}
+}
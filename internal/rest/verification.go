@@ -0,0 +1,162 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+	"santapan/pkg/jwtkey"
+	"santapan/pkg/mailer"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	emailVerificationTokenType      = "email_verification"
+	emailVerificationTTL            = time.Hour * 24
+	emailVerificationResendInterval = time.Minute * 2
+	defaultAppURL                   = "http://localhost:9090"
+)
+
+// VerificationHandler represent the httphandler for email verification
+type VerificationHandler struct {
+	UserService UserService
+	KeyProvider jwtkey.Provider
+	Mailer      mailer.Mailer
+}
+
+// NewVerificationHandler will initialize the verify-email/ resources endpoint
+func NewVerificationHandler(e *echo.Echo, userService UserService, keyProvider jwtkey.Provider, mailer mailer.Mailer) {
+	handler := &VerificationHandler{
+		UserService: userService,
+		KeyProvider: keyProvider,
+		Mailer:      mailer,
+	}
+
+	e.GET("/verify-email", handler.VerifyEmail)
+	e.POST("/verify-email/resend", handler.Resend, middleware.AuthMiddleware)
+}
+
+// VerifyEmail confirms the email address from a signed verification link
+func (vh *VerificationHandler) VerifyEmail(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	claims, err := middleware.ParseToken(c.QueryParam("token"))
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Verification link is invalid or has expired", nil)
+	}
+
+	tokenType, _ := claims["typ"].(string)
+	email, _ := claims["email"].(string)
+	userID, ok := claims["sub"].(float64)
+	if tokenType != emailVerificationTokenType || !ok {
+		return json.Response(c, http.StatusBadRequest, false, "Verification link is invalid or has expired", nil)
+	}
+
+	user, err := vh.UserService.GetByID(ctx, int64(userID))
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Verification link is invalid or has expired", nil)
+	}
+
+	// A link sent to the new address of an email change switches the account over to it
+	if email != "" && email == user.PendingEmail {
+		err = vh.UserService.ConfirmEmailChange(ctx, user.ID, email, time.Now())
+		switch err {
+		case nil:
+			return json.Response(c, http.StatusOK, true, "Email changed successfully!", nil)
+		case domain.ErrConflict:
+			return json.Response(c, http.StatusConflict, false, "Email has already taken", nil)
+		default:
+			logrus.Error(err)
+			return json.Response(c, http.StatusInternalServerError, false, "Failed to change email", nil)
+		}
+	}
+
+	// A link sent to a previous address must not verify the current one
+	if user.Email != email {
+		return json.Response(c, http.StatusBadRequest, false, "Verification link is invalid or has expired", nil)
+	}
+
+	if user.EmailVerifiedAt == nil {
+		if err = vh.UserService.UpdateEmailVerifiedAt(ctx, user.ID, time.Now()); err != nil {
+			logrus.Error(err)
+			return json.Response(c, http.StatusInternalServerError, false, "Failed to verify email", nil)
+		}
+	}
+
+	return json.Response(c, http.StatusOK, true, "Email verified successfully!", nil)
+}
+
+// Resend sends a new verification link, at most once per resend interval
+func (vh *VerificationHandler) Resend(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID := c.Get("userID").(int64)
+
+	user, err := vh.UserService.GetByID(ctx, userID)
+	if err != nil {
+		return json.Response(c, http.StatusNotFound, false, "User not found", nil)
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Email has already been verified", nil)
+	}
+
+	if user.EmailVerificationSentAt != nil {
+		if wait := time.Until(user.EmailVerificationSentAt.Add(emailVerificationResendInterval)); wait > 0 {
+			c.Response().Header().Set("Retry-After", fmt.Sprintf("%.0f", wait.Seconds()))
+			return json.Response(c, http.StatusTooManyRequests, false, "Please wait before requesting another verification email", nil)
+		}
+	}
+
+	if err = sendVerificationEmail(ctx, vh.KeyProvider, vh.Mailer, vh.UserService, user); err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to send verification email", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Verification email sent!", nil)
+}
+
+// sendVerificationEmail mails a signed, expiring verification link to the user
+func sendVerificationEmail(ctx context.Context, keyProvider jwtkey.Provider, m mailer.Mailer, userService UserService, user domain.User) error {
+	return sendVerificationLink(ctx, keyProvider, m, userService, user, user.Email)
+}
+
+// sendVerificationLink mails a verification link for the given address, which is
+// either the current email of the user or the pending one of an email change
+func sendVerificationLink(ctx context.Context, keyProvider jwtkey.Provider, m mailer.Mailer, userService UserService, user domain.User, email string) error {
+	token, err := signToken(keyProvider, user.ID, time.Now().Add(emailVerificationTTL).Unix(), jwt.MapClaims{
+		"typ":   emailVerificationTokenType,
+		"email": email,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sign verification token: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", AppURL(), url.QueryEscape(token))
+	err = m.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Verify your Santapan email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %d hours.\n",
+			user.FullName, link, int(emailVerificationTTL.Hours())),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	return userService.UpdateEmailVerificationSentAt(ctx, user.ID, time.Now())
+}
+
+// AppURL returns the public base URL used in links sent to users
+func AppURL() string {
+	if u := os.Getenv("APP_URL"); u != "" {
+		return u
+	}
+	return defaultAppURL
+}
@@ -0,0 +1,150 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"santapan/domain"
+	"santapan/internal/rest/middleware"
+	"santapan/pkg/json"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type VoucherService interface {
+	Preview(ctx context.Context, userID int64, code string) (domain.VoucherQuote, error)
+	Fetch(ctx context.Context) ([]domain.Voucher, error)
+	Create(ctx context.Context, actorID int64, body domain.CreateVoucherBody) (domain.Voucher, error)
+	Deactivate(ctx context.Context, actorID int64, id int64) error
+}
+
+// VoucherHandler represent the httphandler for vouchers
+type VoucherHandler struct {
+	VoucherService VoucherService
+	Validator      *validator.Validate
+}
+
+// NewVoucherHandler will initialize the voucher endpoints. Customers preview a voucher
+// on their cart, it is redeemed when they check out with its code.
+func NewVoucherHandler(e *echo.Echo, voucherService VoucherService) {
+	handler := &VoucherHandler{
+		VoucherService: voucherService,
+		Validator:      validator.New(),
+	}
+
+	e.POST("/cart/apply-voucher", handler.Apply, middleware.AuthMiddleware)
+
+	admin := e.Group("/admin", middleware.AuthMiddleware, middleware.RequirePermission(domain.PermissionPromotionsManage))
+	admin.GET("/vouchers", handler.Fetch)
+	admin.POST("/vouchers", handler.Create)
+	admin.DELETE("/vouchers/:id", handler.Deactivate)
+}
+
+// Apply previews the discount of a voucher on the active cart of the authenticated user
+func (vh *VoucherHandler) Apply(c echo.Context) (err error) {
+	var body domain.ApplyVoucherBody
+	userID := c.Get("userID").(int64)
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = vh.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	quote, err := vh.VoucherService.Preview(c.Request().Context(), userID, body.Code)
+	switch err {
+	case nil:
+	case domain.ErrBadParamInput:
+		return json.Response(c, http.StatusBadRequest, false, "Your cart is empty", nil)
+	case domain.ErrNotFound:
+		return json.Response(c, http.StatusNotFound, false, "Voucher not found", nil)
+	case domain.ErrVoucherUnavailable, domain.ErrVoucherLimitReached, domain.ErrVoucherMinSpend, domain.ErrVoucherNotEligible:
+		return voucherError(c, err)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to apply voucher", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Voucher applied!", quote)
+}
+
+// Fetch lists every voucher
+func (vh *VoucherHandler) Fetch(c echo.Context) error {
+	vouchers, err := vh.VoucherService.Fetch(c.Request().Context())
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to get vouchers", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Successfully Get Vouchers!", vouchers)
+}
+
+// Create starts a new campaign code
+func (vh *VoucherHandler) Create(c echo.Context) (err error) {
+	var body domain.CreateVoucherBody
+	actorID := c.Get("userID").(int64)
+
+	if err = c.Bind(&body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid request body", nil)
+	}
+
+	if err = vh.Validator.Struct(body); err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Validation failed: "+err.Error(), nil)
+	}
+
+	voucher, err := vh.VoucherService.Create(c.Request().Context(), actorID, body)
+	switch err {
+	case nil:
+	case domain.ErrBadParamInput:
+		return json.Response(c, http.StatusBadRequest, false, "Invalid discount, validity window, category or menu", nil)
+	case domain.ErrConflict:
+		return json.Response(c, http.StatusConflict, false, "Voucher code already exists", nil)
+	default:
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to create voucher", nil)
+	}
+
+	return json.Response(c, http.StatusCreated, true, "Voucher created!", voucher)
+}
+
+// Deactivate ends a campaign, orders that already used the voucher keep their discount
+func (vh *VoucherHandler) Deactivate(c echo.Context) error {
+	actorID := c.Get("userID").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return json.Response(c, http.StatusBadRequest, false, "Invalid ID", nil)
+	}
+
+	err = vh.VoucherService.Deactivate(c.Request().Context(), actorID, id)
+	if err == domain.ErrNotFound {
+		return json.Response(c, http.StatusNotFound, false, "Voucher not found", nil)
+	}
+	if err != nil {
+		logrus.Error(err)
+		return json.Response(c, http.StatusInternalServerError, false, "Failed to deactivate voucher", nil)
+	}
+
+	return json.Response(c, http.StatusOK, true, "Voucher deactivated!", nil)
+}
+
+// voucherError explains why a voucher can not be used on the cart
+func voucherError(c echo.Context, err error) error {
+	message := "Voucher can not be used"
+	switch err {
+	case domain.ErrVoucherUnavailable:
+		message = "Voucher is not valid at this time"
+	case domain.ErrVoucherLimitReached:
+		message = "Voucher has reached its usage limit"
+	case domain.ErrVoucherMinSpend:
+		message = "Your cart does not reach the minimum spend of the voucher"
+	case domain.ErrVoucherNotEligible:
+		message = "No item in your cart is eligible for the voucher"
+	}
+
+	return json.Response(c, http.StatusUnprocessableEntity, false, message, nil)
+}
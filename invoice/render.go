@@ -0,0 +1,169 @@
+package invoice
+
+import (
+	"fmt"
+	"santapan/domain"
+	"santapan/pkg/pdf"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Indonesian names of the months and the payment methods shown on invoices
+var (
+	months = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli",
+		"Agustus", "September", "Oktober", "November", "Desember"}
+
+	paymentMethods = map[string]string{
+		"CC": "Kartu Kredit",
+		"BT": "Transfer Bank",
+		"EW": "Dompet Digital",
+	}
+)
+
+// Layout of the PDF in points
+const (
+	marginLeft   = 50.0
+	marginRight  = pdf.PageWidth - 50
+	pageBottom   = pdf.PageHeight - 70
+	quantityX    = 340.0 // Right edges of the columns
+	unitPriceX   = 445.0
+	descriptionW = 270.0
+	lineHeight   = 14.0
+	fontSize     = 9.5
+)
+
+// withCharges adds the breakdown of the total below the items
+func withCharges(invoice domain.Invoice) domain.Invoice {
+	charges := []domain.InvoiceCharge{{Label: "Subtotal", Amount: invoice.ItemsTotal}}
+	if invoice.Discount > 0 {
+		charges = append(charges, domain.InvoiceCharge{Label: "Diskon", Amount: -invoice.Discount})
+	}
+	charges = append(charges, domain.InvoiceCharge{Label: "Ongkos Kirim", Amount: invoice.CourierFee})
+	if invoice.ServiceFee > 0 || invoice.ServiceFeeRate > 0 {
+		charges = append(charges, domain.InvoiceCharge{Label: "Biaya Layanan (" + formatRate(invoice.ServiceFeeRate) + ")", Amount: invoice.ServiceFee})
+	}
+	charges = append(charges,
+		domain.InvoiceCharge{Label: "PPN (" + formatRate(invoice.TaxRate) + ")", Amount: invoice.Tax},
+		domain.InvoiceCharge{Label: "Total", Amount: invoice.Total},
+	)
+
+	invoice.Charges = charges
+	return invoice
+}
+
+// PDF renders the invoice as an A4 document in Indonesian
+func (s *Service) PDF(invoice domain.Invoice) []byte {
+	invoice = withCharges(invoice)
+
+	doc := pdf.New("Faktur " + invoice.Number)
+	page := doc.AddPage()
+
+	page.Text(marginLeft, 70, 20, true, "FAKTUR")
+	page.TextRight(marginRight, 70, 14, true, "Santapan")
+	page.Line(marginLeft, 82, marginRight, 82, 1)
+
+	y := 105.0
+	for _, field := range [][2]string{
+		{"Nomor Faktur", invoice.Number},
+		{"Tanggal Terbit", formatDate(invoice.IssuedAt)},
+		{"Nomor Pesanan", fmt.Sprintf("#%d", invoice.TransactionID)},
+		{"Tanggal Pesanan", formatDate(invoice.OrderedAt)},
+		{"Metode Pembayaran", paymentMethod(invoice.PaymentMethod)},
+		{"Kurir", invoice.Courier},
+	} {
+		page.Text(marginLeft, y, fontSize, true, field[0])
+		page.Text(marginLeft+100, y, fontSize, false, ": "+field[1])
+		y += lineHeight
+	}
+
+	y += 12
+	page.Text(marginLeft, y, fontSize, true, "Ditagihkan kepada")
+	page.Text(300, y, fontSize, true, "Dikirim kepada")
+	billTo := []string{invoice.CustomerName, invoice.CustomerEmail}
+	shipTo := []string{invoice.RecipientName, invoice.RecipientPhone}
+	shipTo = append(shipTo, pdf.Wrap(invoice.DeliveryAddress, fontSize, false, marginRight-300)...)
+	for i := 0; i < len(billTo) || i < len(shipTo); i++ {
+		y += lineHeight
+		if i < len(billTo) {
+			page.Text(marginLeft, y, fontSize, false, billTo[i])
+		}
+		if i < len(shipTo) {
+			page.Text(300, y, fontSize, false, shipTo[i])
+		}
+	}
+
+	y += 24
+	y = tableHeader(page, y)
+	for _, line := range invoice.Lines {
+		description := pdf.Wrap(line.Description, fontSize, false, descriptionW)
+		if y+float64(len(description))*lineHeight > pageBottom {
+			page = doc.AddPage()
+			y = tableHeader(page, 60)
+		}
+
+		page.TextRight(quantityX, y, fontSize, false, strconv.Itoa(line.Quantity))
+		page.TextRight(unitPriceX, y, fontSize, false, line.UnitPrice.String())
+		page.TextRight(marginRight, y, fontSize, false, line.Amount.String())
+		for _, text := range description {
+			page.Text(marginLeft+4, y, fontSize, false, text)
+			y += lineHeight
+		}
+		y += 2
+	}
+	page.Line(marginLeft, y-8, marginRight, y-8, 0.5)
+
+	if y+float64(len(invoice.Charges)+3)*lineHeight > pageBottom {
+		page = doc.AddPage()
+		y = 60
+	}
+	y += 6
+	for i, charge := range invoice.Charges {
+		total := i == len(invoice.Charges)-1
+		if total {
+			page.Line(unitPriceX-100, y-10, marginRight, y-10, 0.5)
+			y += 4
+		}
+		page.Text(unitPriceX-100, y, fontSize, total, charge.Label)
+		page.TextRight(marginRight, y, fontSize, total, charge.Amount.String())
+		y += lineHeight
+	}
+
+	y += 24
+	for _, text := range pdf.Wrap("Seluruh harga dalam Rupiah. Faktur ini diterbitkan secara elektronik dan sah tanpa tanda tangan.",
+		8, false, marginRight-marginLeft) {
+		page.Text(marginLeft, y, 8, false, text)
+		y += 11
+	}
+
+	return doc.Bytes()
+}
+
+// tableHeader draws the header of the item table and returns the baseline of the first row
+func tableHeader(page *pdf.Page, y float64) float64 {
+	page.Box(marginLeft, y-12, marginRight-marginLeft, 18, 0.9)
+	page.Text(marginLeft+4, y, fontSize, true, "Item")
+	page.TextRight(quantityX, y, fontSize, true, "Jml")
+	page.TextRight(unitPriceX, y, fontSize, true, "Harga Satuan")
+	page.TextRight(marginRight, y, fontSize, true, "Jumlah")
+	return y + 20
+}
+
+// formatDate formats a moment the Indonesian way in WIB, e.g. "18 Oktober 2026, 14:05 WIB"
+func formatDate(t time.Time) string {
+	t = t.In(domain.InvoiceTimeZone)
+	return fmt.Sprintf("%d %s %d, %s WIB", t.Day(), months[t.Month()-1], t.Year(), t.Format("15:04"))
+}
+
+// formatRate formats a percentage with a decimal comma, e.g. "12,5%"
+func formatRate(rate float64) string {
+	return strings.Replace(strconv.FormatFloat(rate, 'f', -1, 64), ".", ",", 1) + "%"
+}
+
+// paymentMethod returns the Indonesian name of a payment method
+func paymentMethod(code string) string {
+	if name, ok := paymentMethods[code]; ok {
+		return name
+	}
+	return code
+}
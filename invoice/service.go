@@ -0,0 +1,133 @@
+package invoice
+
+import (
+	"context"
+	"santapan/domain"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type PostgresRepositoryQueries interface {
+	GetByTransactionID(ctx context.Context, transactionID int64) (domain.Invoice, error)
+}
+
+type PostgresRepositoryCommand interface {
+	Issue(ctx context.Context, invoice *domain.Invoice) error
+}
+
+// OrderService reads an order of a user with the items, address, courier and payment to bill
+type OrderService interface {
+	GetDetail(ctx context.Context, userID int64, id int64) (domain.OrderDetail, error)
+}
+
+// UserRepository reads the customer the invoice is addressed to
+type UserRepository interface {
+	GetByID(ctx context.Context, id int64) (domain.User, error)
+}
+
+type Service struct {
+	postgresRepoQuery   PostgresRepositoryQueries
+	postgresRepoCommand PostgresRepositoryCommand
+	orderService        OrderService
+	userRepo            UserRepository
+}
+
+// NewService will create a new invoice service object
+func NewService(pq PostgresRepositoryQueries, pc PostgresRepositoryCommand, orderService OrderService, userRepo UserRepository) *Service {
+	return &Service{
+		postgresRepoQuery:   pq,
+		postgresRepoCommand: pc,
+		orderService:        orderService,
+		userRepo:            userRepo,
+	}
+}
+
+// Get returns the invoice of an order of the user, issuing it the first time it is
+// requested. Issued invoices are returned as they were issued, also after a refund.
+// Returns domain.ErrNotFound for an unknown order or one of another user and
+// domain.ErrInvoiceUnavailable when the order has no invoice and is not paid.
+func (s *Service) Get(ctx context.Context, userID int64, orderID int64) (domain.Invoice, error) {
+	order, err := s.orderService.GetDetail(ctx, userID, orderID)
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+
+	invoice, err := s.postgresRepoQuery.GetByTransactionID(ctx, order.ID)
+	if err == nil {
+		return withCharges(invoice), nil
+	}
+	if err != domain.ErrNotFound {
+		return domain.Invoice{}, err
+	}
+
+	if !order.Status.Invoiceable() {
+		return domain.Invoice{}, domain.ErrInvoiceUnavailable
+	}
+
+	customer, err := s.userRepo.GetByID(ctx, order.UserID)
+	if err != nil && err != domain.ErrNotFound {
+		return domain.Invoice{}, err
+	}
+
+	invoice = newInvoice(order, customer, time.Now())
+	err = s.postgresRepoCommand.Issue(ctx, &invoice)
+	if err == domain.ErrConflict {
+		// Issued by a concurrent request in the meantime
+		invoice, err = s.postgresRepoQuery.GetByTransactionID(ctx, order.ID)
+		if err != nil {
+			return domain.Invoice{}, err
+		}
+		return withCharges(invoice), nil
+	}
+	if err != nil {
+		return domain.Invoice{}, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":          "invoice_issued",
+		"user_id":        userID,
+		"transaction_id": order.ID,
+		"invoice_number": invoice.Number,
+		"total":          invoice.Total,
+	}).Info("Invoice issued")
+
+	return withCharges(invoice), nil
+}
+
+// newInvoice takes the snapshot of an order billed to the customer
+func newInvoice(order domain.OrderDetail, customer domain.User, issuedAt time.Time) domain.Invoice {
+	invoice := domain.Invoice{
+		TransactionID:   order.ID,
+		UserID:          order.UserID,
+		CustomerName:    customer.FullName,
+		CustomerEmail:   customer.Email,
+		RecipientName:   order.Address.Name,
+		RecipientPhone:  order.Address.Phone,
+		DeliveryAddress: order.Address.Address,
+		Courier:         order.Courier.Name,
+		PaymentMethod:   order.Payment.ReferenceID,
+		Lines:           make([]domain.InvoiceLine, 0, len(order.Items)),
+		Discount:        order.Discount,
+		CourierFee:      order.CourierFee,
+		ServiceFee:      order.ServiceFee,
+		ServiceFeeRate:  order.ServiceFeeRate,
+		Tax:             order.Tax,
+		TaxRate:         order.TaxRate,
+		Total:           order.Amount,
+		OrderedAt:       order.CreatedAt,
+		IssuedAt:        issuedAt,
+	}
+
+	for _, item := range order.Items {
+		invoice.Lines = append(invoice.Lines, domain.InvoiceLine{
+			Description: item.Name,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.Price,
+			Amount:      item.Subtotal,
+		})
+		invoice.ItemsTotal += item.Subtotal
+	}
+
+	return invoice
+}
@@ -0,0 +1,146 @@
+package loginattempt
+
+import (
+	"context"
+	"math"
+	"santapan/domain"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Window is the sliding window failed attempts are counted in
+	Window = time.Minute * 15
+	// MaxEmailFailures is the number of failures within Window that locks an account
+	MaxEmailFailures = 5
+	// MaxIPFailures is the number of failures within Window that locks an IP address
+	MaxIPFailures = 20
+	// BaseLockout is the first lockout duration, doubled on every further lockout
+	BaseLockout = time.Minute
+	// MaxLockout caps the exponential backoff
+	MaxLockout = time.Hour * 24
+	// StrikeMemory is how long earlier lockouts count towards the backoff
+	StrikeMemory = time.Hour * 24
+)
+
+// Store keeps failed attempts and lockouts per key
+type Store interface {
+	// RecordFailure adds a failure at now and returns the failures within window
+	RecordFailure(ctx context.Context, key string, now time.Time, window time.Duration) (int64, error)
+	ResetFailures(ctx context.Context, key string) error
+	// IncrementStrikes counts a lockout and returns the lockouts within ttl
+	IncrementStrikes(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	SetLock(ctx context.Context, key string, until time.Time) error
+	// LockedUntil returns the end of the current lock, or the zero time when the key is not locked
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+}
+
+type Service struct {
+	store Store
+}
+
+// NewService will create a new login attempt tracker
+func NewService(store Store) *Service {
+	return &Service{
+		store: store,
+	}
+}
+
+// Check returns domain.ErrTooManyAttempts and the remaining lock time when the
+// account or the IP address is locked
+func (s *Service) Check(ctx context.Context, email string, ip string) (time.Duration, error) {
+	var retryAfter time.Duration
+
+	for _, key := range keys(email, ip) {
+		until, err := s.store.LockedUntil(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if wait := time.Until(until); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return retryAfter, domain.ErrTooManyAttempts
+	}
+	return 0, nil
+}
+
+// Fail records a failed login and locks the account or IP address once its
+// limit within Window is reached
+func (s *Service) Fail(ctx context.Context, email string, ip string) error {
+	now := time.Now()
+
+	limits := map[string]int64{
+		emailKey(email): MaxEmailFailures,
+		ipKey(ip):       MaxIPFailures,
+	}
+
+	for key, limit := range limits {
+		failures, err := s.store.RecordFailure(ctx, key, now, Window)
+		if err != nil {
+			return err
+		}
+		if failures < limit {
+			continue
+		}
+
+		strikes, err := s.store.IncrementStrikes(ctx, key, StrikeMemory)
+		if err != nil {
+			return err
+		}
+
+		lockout := backoff(strikes)
+		if err = s.store.SetLock(ctx, key, now.Add(lockout)); err != nil {
+			return err
+		}
+		if err = s.store.ResetFailures(ctx, key); err != nil {
+			return err
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"event":    "login_lockout",
+			"key":      key,
+			"email":    email,
+			"ip":       ip,
+			"failures": failures,
+			"strikes":  strikes,
+			"until":    now.Add(lockout),
+		}).Warn("Too many failed login attempts, temporarily locked")
+	}
+
+	return nil
+}
+
+// Succeed clears the failed attempts of the account after a successful login
+func (s *Service) Succeed(ctx context.Context, email string) error {
+	return s.store.ResetFailures(ctx, emailKey(email))
+}
+
+// backoff doubles the lockout for every lockout within StrikeMemory
+func backoff(strikes int64) time.Duration {
+	if strikes < 1 {
+		strikes = 1
+	}
+
+	lockout := float64(BaseLockout) * math.Pow(2, float64(strikes-1))
+	if lockout > float64(MaxLockout) {
+		return MaxLockout
+	}
+	return time.Duration(lockout)
+}
+
+func keys(email string, ip string) []string {
+	return []string{emailKey(email), ipKey(ip)}
+}
+
+func emailKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
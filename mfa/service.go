@@ -0,0 +1,236 @@
+package mfa
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"santapan/domain"
+	"santapan/pkg/totp"
+	"strings"
+	"time"
+)
+
+const (
+	// Issuer is the name authenticator apps show next to the account
+	Issuer = "Santapan"
+	// RecoveryCodeCount is the number of recovery codes handed out at a time
+	RecoveryCodeCount = 10
+)
+
+type PostgresRepositoryQueries interface {
+	GetByUserID(ctx context.Context, userID int64) (domain.UserMfa, error)
+}
+
+type PostgresRepositoryCommand interface {
+	StorePending(ctx context.Context, userID int64, secret string) error
+	Enable(ctx context.Context, userID int64, step int64, codeHashes []string) error
+	UseStep(ctx context.Context, userID int64, step int64) (bool, error)
+	ConsumeRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error
+	Delete(ctx context.Context, userID int64) error
+}
+
+// SecretSealer encrypts TOTP secrets before they are stored
+type SecretSealer interface {
+	Seal(plaintext string) (string, error)
+	Open(ciphertext string) (string, error)
+}
+
+type Service struct {
+	postgresRepoQuery   PostgresRepositoryQueries
+	postgresRepoCommand PostgresRepositoryCommand
+	sealer              SecretSealer
+}
+
+// NewService will create a new mfa service object
+func NewService(pq PostgresRepositoryQueries, pc PostgresRepositoryCommand, sealer SecretSealer) *Service {
+	return &Service{
+		postgresRepoQuery:   pq,
+		postgresRepoCommand: pc,
+		sealer:              sealer,
+	}
+}
+
+// IsEnabled reports whether the user has an activated second factor
+func (s *Service) IsEnabled(ctx context.Context, userID int64) (bool, error) {
+	m, err := s.postgresRepoQuery.GetByUserID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return m.EnabledAt != nil, nil
+}
+
+// Enroll generates a new secret for the user. The second factor is only enabled once
+// Activate receives a code from it. Returns domain.ErrConflict when already enabled.
+func (s *Service) Enroll(ctx context.Context, userID int64, account string) (domain.MfaEnrollment, error) {
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return domain.MfaEnrollment{}, err
+	}
+	if enabled {
+		return domain.MfaEnrollment{}, domain.ErrConflict
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return domain.MfaEnrollment{}, err
+	}
+
+	sealed, err := s.sealer.Seal(secret)
+	if err != nil {
+		return domain.MfaEnrollment{}, err
+	}
+
+	if err = s.postgresRepoCommand.StorePending(ctx, userID, sealed); err != nil {
+		return domain.MfaEnrollment{}, err
+	}
+
+	return domain.MfaEnrollment{
+		Secret: secret,
+		URI:    totp.URI(Issuer, account, secret),
+	}, nil
+}
+
+// Activate enables the pending enrolment with a code from the authenticator and
+// returns the recovery codes, which are shown to the user only once
+func (s *Service) Activate(ctx context.Context, userID int64, code string) ([]string, error) {
+	m, err := s.postgresRepoQuery.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m.EnabledAt != nil {
+		return nil, domain.ErrConflict
+	}
+
+	step, err := s.validate(m, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.postgresRepoCommand.Enable(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Verify checks a TOTP code or a recovery code of the user. Each TOTP code and
+// each recovery code is accepted once; wrong or reused codes give domain.ErrInvalidOtp.
+func (s *Service) Verify(ctx context.Context, userID int64, code string) error {
+	m, err := s.postgresRepoQuery.GetByUserID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return domain.ErrInvalidOtp
+	}
+	if err != nil {
+		return err
+	}
+	if m.EnabledAt == nil {
+		return domain.ErrInvalidOtp
+	}
+
+	if isRecoveryCode(code) {
+		used, err := s.postgresRepoCommand.ConsumeRecoveryCode(ctx, userID, hashRecoveryCode(code))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return domain.ErrInvalidOtp
+		}
+		return nil
+	}
+
+	step, err := s.validate(m, code)
+	if err != nil {
+		return err
+	}
+
+	fresh, err := s.postgresRepoCommand.UseStep(ctx, userID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return domain.ErrInvalidOtp
+	}
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a code
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.postgresRepoCommand.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable removes the second factor after checking a code
+func (s *Service) Disable(ctx context.Context, userID int64, code string) error {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+
+	return s.postgresRepoCommand.Delete(ctx, userID)
+}
+
+// validate checks a TOTP code against the stored secret and returns its time step
+func (s *Service) validate(m domain.UserMfa, code string) (int64, error) {
+	secret, err := s.sealer.Open(m.Secret)
+	if err != nil {
+		return 0, err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok || step <= m.LastUsedStep {
+		return 0, domain.ErrInvalidOtp
+	}
+
+	return step, nil
+}
+
+// generateRecoveryCodes returns plain codes formatted as xxxxx-xxxxx and their hashes
+func generateRecoveryCodes() (codes []string, hashes []string, err error) {
+	for i := 0; i < RecoveryCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err = rand.Read(b); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		raw := hex.EncodeToString(b)
+		code := raw[:5] + "-" + raw[5:]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func isRecoveryCode(code string) bool {
+	return strings.Contains(code, "-")
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
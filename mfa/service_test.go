@@ -0,0 +1,197 @@
+package mfa
+
+import (
+	"context"
+	"santapan/domain"
+	"santapan/pkg/totp"
+	"testing"
+	"time"
+)
+
+const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// plainSealer stores secrets as they are
+type plainSealer struct{}
+
+func (plainSealer) Seal(plaintext string) (string, error)  { return plaintext, nil }
+func (plainSealer) Open(ciphertext string) (string, error) { return ciphertext, nil }
+
+// memoryStore keeps one user's second factor the way the postgres repositories do
+type memoryStore struct {
+	mfa           domain.UserMfa
+	recoveryCodes map[string]bool // hash -> used
+}
+
+func newMemoryStore(lastUsedStep int64) *memoryStore {
+	enabledAt := time.Now()
+	return &memoryStore{
+		mfa:           domain.UserMfa{UserID: 1, Secret: secret, EnabledAt: &enabledAt, LastUsedStep: lastUsedStep},
+		recoveryCodes: map[string]bool{},
+	}
+}
+
+func (m *memoryStore) GetByUserID(ctx context.Context, userID int64) (domain.UserMfa, error) {
+	if userID != m.mfa.UserID {
+		return domain.UserMfa{}, domain.ErrNotFound
+	}
+	return m.mfa, nil
+}
+
+func (m *memoryStore) StorePending(ctx context.Context, userID int64, secret string) error {
+	m.mfa = domain.UserMfa{UserID: userID, Secret: secret}
+	return nil
+}
+
+func (m *memoryStore) Enable(ctx context.Context, userID int64, step int64, codeHashes []string) error {
+	enabledAt := time.Now()
+	m.mfa.EnabledAt = &enabledAt
+	m.mfa.LastUsedStep = step
+	return m.ReplaceRecoveryCodes(ctx, userID, codeHashes)
+}
+
+func (m *memoryStore) UseStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	if m.mfa.LastUsedStep >= step {
+		return false, nil
+	}
+	m.mfa.LastUsedStep = step
+	return true, nil
+}
+
+func (m *memoryStore) ConsumeRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	used, ok := m.recoveryCodes[codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	m.recoveryCodes[codeHash] = true
+	return true, nil
+}
+
+func (m *memoryStore) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	m.recoveryCodes = map[string]bool{}
+	for _, h := range codeHashes {
+		m.recoveryCodes[h] = false
+	}
+	return nil
+}
+
+func (m *memoryStore) Delete(ctx context.Context, userID int64) error {
+	m.mfa = domain.UserMfa{}
+	return nil
+}
+
+func codeAt(t *testing.T, step int64) string {
+	t.Helper()
+	code, err := totp.Code(secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+// currentStep returns the current time step, waiting out the last second of a period so
+// the step cannot roll over before Verify looks at the clock
+func currentStep() int64 {
+	if left := totp.Period - time.Now().Unix()%totp.Period; left <= 1 {
+		time.Sleep(time.Duration(left) * time.Second)
+	}
+	return totp.Step(time.Now())
+}
+
+func TestVerifyTotp(t *testing.T) {
+	current := currentStep()
+
+	tests := []struct {
+		name         string
+		lastUsedStep int64
+		step         int64
+		want         error
+	}{
+		{"current step", current - 5, current, nil},
+		{"previous step within the skew", current - 5, current - 1, nil},
+		{"step already used", current, current, domain.ErrInvalidOtp},
+		{"step before the last used one", current, current - 1, domain.ErrInvalidOtp},
+		{"step outside the window", current - 5, current - 3, domain.ErrInvalidOtp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStore(tt.lastUsedStep)
+			s := NewService(store, store, plainSealer{})
+
+			if err := s.Verify(context.Background(), 1, codeAt(t, tt.step)); err != tt.want {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsReusedCode(t *testing.T) {
+	store := newMemoryStore(0)
+	s := NewService(store, store, plainSealer{})
+	code := codeAt(t, currentStep())
+
+	if err := s.Verify(context.Background(), 1, code); err != nil {
+		t.Fatalf("first use: got %v, want nil", err)
+	}
+	if err := s.Verify(context.Background(), 1, code); err != domain.ErrInvalidOtp {
+		t.Fatalf("second use: got %v, want domain.ErrInvalidOtp", err)
+	}
+}
+
+// staleStore hands out the second factor as it was before any code was used, like two
+// requests that read the row at the same time
+type staleStore struct {
+	*memoryStore
+	snapshot domain.UserMfa
+}
+
+func (s staleStore) GetByUserID(ctx context.Context, userID int64) (domain.UserMfa, error) {
+	return s.snapshot, nil
+}
+
+func TestVerifyRejectsConcurrentReuse(t *testing.T) {
+	store := newMemoryStore(0)
+	s := NewService(staleStore{memoryStore: store, snapshot: store.mfa}, store, plainSealer{})
+	code := codeAt(t, currentStep())
+
+	if err := s.Verify(context.Background(), 1, code); err != nil {
+		t.Fatalf("first use: got %v, want nil", err)
+	}
+	if err := s.Verify(context.Background(), 1, code); err != domain.ErrInvalidOtp {
+		t.Fatalf("second use: got %v, want domain.ErrInvalidOtp", err)
+	}
+}
+
+func TestVerifyRecoveryCodeOnce(t *testing.T) {
+	store := newMemoryStore(0)
+	s := NewService(store, store, plainSealer{})
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.ReplaceRecoveryCodes(context.Background(), 1, hashes)
+
+	if err := s.Verify(context.Background(), 1, codes[0]); err != nil {
+		t.Fatalf("first use: got %v, want nil", err)
+	}
+	if err := s.Verify(context.Background(), 1, codes[0]); err != domain.ErrInvalidOtp {
+		t.Fatalf("second use: got %v, want domain.ErrInvalidOtp", err)
+	}
+	if err := s.Verify(context.Background(), 1, "aaaaa-bbbbb"); err != domain.ErrInvalidOtp {
+		t.Fatalf("unknown code: got %v, want domain.ErrInvalidOtp", err)
+	}
+}
+
+func TestVerifyNotEnabled(t *testing.T) {
+	store := newMemoryStore(0)
+	store.mfa.EnabledAt = nil
+	s := NewService(store, store, plainSealer{})
+
+	if err := s.Verify(context.Background(), 1, codeAt(t, currentStep())); err != domain.ErrInvalidOtp {
+		t.Fatalf("pending enrolment: got %v, want domain.ErrInvalidOtp", err)
+	}
+	if err := s.Verify(context.Background(), 2, "123456"); err != domain.ErrInvalidOtp {
+		t.Fatalf("no enrolment: got %v, want domain.ErrInvalidOtp", err)
+	}
+}
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_token_family_id;
+
+ALTER TABLE token
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS family_id,
+    ALTER COLUMN refresh_token TYPE VARCHAR(255);
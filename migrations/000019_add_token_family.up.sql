@@ -0,0 +1,8 @@
+-- Track refresh token families so a replayed (already rotated) token can be detected
+ALTER TABLE token
+    ALTER COLUMN refresh_token TYPE TEXT,                          -- Signed refresh tokens can exceed 255 characters
+    ADD COLUMN family_id VARCHAR(64) NOT NULL DEFAULT '',          -- Shared by every token rotated from the same login
+    ADD COLUMN expires_at TIMESTAMP WITH TIME ZONE;                -- Expiry of the current refresh token
+
+-- Create an index for the family_id column for faster lookups on refresh
+CREATE INDEX idx_token_family_id ON token(family_id);
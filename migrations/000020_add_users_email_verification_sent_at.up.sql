@@ -0,0 +1,3 @@
+-- Remember when the last verification email was sent so resends can be throttled
+ALTER TABLE users
+    ADD COLUMN email_verification_sent_at TIMESTAMP WITH TIME ZONE NULL;  -- Maps to `EmailVerificationSentAt`
@@ -0,0 +1,13 @@
+-- Create the password_reset table holding single-use password reset tokens
+CREATE TABLE IF NOT EXISTS password_reset (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,                 -- SHA-256 of the reset token, the token itself is never stored
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,           -- The token can not be used after this time
+    used_at TIMESTAMP WITH TIME ZONE NULL,                  -- Set once the token has been consumed
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_password_reset_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create an index for the user_id column for faster lookups
+CREATE INDEX idx_password_reset_user_id ON password_reset(user_id);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS otp;
+DROP FUNCTION IF EXISTS update_otp_timestamp;
+
+DROP INDEX IF EXISTS idx_users_verified_phone;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS phone_verified_at,
+    DROP COLUMN IF EXISTS phone;
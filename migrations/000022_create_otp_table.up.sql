@@ -0,0 +1,39 @@
+-- Add the verified phone number to users
+ALTER TABLE users
+    ADD COLUMN phone VARCHAR(20) NULL,                                  -- E.164 formatted phone number, maps to `Phone`
+    ADD COLUMN phone_verified_at TIMESTAMP WITH TIME ZONE NULL;         -- Maps to `PhoneVerifiedAt`
+
+-- A phone number can only be verified by one account
+CREATE UNIQUE INDEX idx_users_verified_phone ON users (phone) WHERE phone_verified_at IS NOT NULL;
+
+-- Create the otp table
+CREATE TABLE IF NOT EXISTS otp (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    phone VARCHAR(20) NOT NULL,                          -- E.164 phone number the code was sent to
+    code VARCHAR(255) NOT NULL,                          -- bcrypt hash of the code, the code itself is never stored
+    type VARCHAR(20) NOT NULL,                           -- Delivery channel ('sms', 'whatsapp', 'email')
+    retry SMALLINT NOT NULL DEFAULT 0,                   -- Number of failed verification attempts
+    device_id VARCHAR(255) NOT NULL DEFAULT '',          -- Device that requested the code
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,        -- The code can not be used after this time
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_otp_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create an index for the user_id column for faster lookups
+CREATE INDEX idx_otp_user_id ON otp(user_id);
+
+-- Trigger function to update `updated_at` timestamp for otp
+CREATE OR REPLACE FUNCTION update_otp_timestamp()
+RETURNS TRIGGER AS $$
+BEGIN
+   NEW.updated_at = NOW();
+   RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER update_otp_updated_at
+BEFORE UPDATE ON otp
+FOR EACH ROW
+EXECUTE FUNCTION update_otp_timestamp();
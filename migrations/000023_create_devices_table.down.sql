@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_token_device_id;
+ALTER TABLE token
+    DROP CONSTRAINT IF EXISTS fk_token_device,
+    DROP COLUMN IF EXISTS device_id;
+
+-- Keep only the latest token of every user before restoring one token per user
+DELETE FROM token t
+USING token newer
+WHERE t.user_id = newer.user_id AND t.id < newer.id;
+ALTER TABLE token ADD CONSTRAINT token_user_id_key UNIQUE (user_id);
+
+DROP TABLE IF EXISTS devices;
+DROP FUNCTION IF EXISTS update_devices_timestamp;
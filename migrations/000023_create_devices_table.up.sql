@@ -0,0 +1,42 @@
+-- Create the devices table
+CREATE TABLE IF NOT EXISTS devices (
+    id BIGSERIAL PRIMARY KEY,                                  -- Maps to `ID`
+    name VARCHAR(255) NOT NULL DEFAULT '',                     -- Device name sent in the `devicename` header
+    brand VARCHAR(255) NOT NULL DEFAULT '',                    -- Device brand sent in the `devicebrand` header
+    model VARCHAR(255) NOT NULL DEFAULT '',                    -- Device model sent in the `devicemodel` header
+    unique_id VARCHAR(255) NOT NULL,                           -- Device identifier sent in the `deviceid` header
+    user_id BIGINT NOT NULL,
+    ip_address VARCHAR(255) NOT NULL DEFAULT '',               -- Last IP address the device was seen from
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE NULL,                  -- Set when the device is signed out remotely
+    UNIQUE (user_id, unique_id),                               -- A device is registered once per user
+    CONSTRAINT fk_devices_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create an index for the user_id column for faster lookups
+CREATE INDEX idx_devices_user_id ON devices(user_id);
+
+-- Trigger function to update `updated_at` timestamp for devices
+CREATE OR REPLACE FUNCTION update_devices_timestamp()
+RETURNS TRIGGER AS $$
+BEGIN
+   NEW.updated_at = NOW();
+   RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER update_devices_updated_at
+BEFORE UPDATE ON devices
+FOR EACH ROW
+EXECUTE FUNCTION update_devices_timestamp();
+
+-- Allow one refresh token family per device instead of one per user
+ALTER TABLE token DROP CONSTRAINT IF EXISTS token_user_id_key;
+ALTER TABLE token
+    ADD COLUMN device_id BIGINT NULL,                          -- Device the refresh token was issued to
+    ADD CONSTRAINT fk_token_device FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE;
+
+-- Create an index for the device_id column for faster lookups
+CREATE INDEX idx_token_device_id ON token(device_id);
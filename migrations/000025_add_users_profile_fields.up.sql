@@ -0,0 +1,4 @@
+-- Add the avatar shown on the profile and the address an email change waits to verify
+ALTER TABLE users
+    ADD COLUMN avatar_url VARCHAR(512) NULL,
+    ADD COLUMN pending_email VARCHAR(255) NULL;             -- Replaces email once the link sent to it is opened
@@ -0,0 +1,5 @@
+-- Add the time a requested account deletion becomes final, the account can be restored until then
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMP WITH TIME ZONE NULL;
+
+-- Create a partial index so the purge job only scans accounts waiting for deletion
+CREATE INDEX idx_users_deletion_scheduled_at ON users(deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
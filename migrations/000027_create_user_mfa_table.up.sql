@@ -0,0 +1,23 @@
+-- Create the user_mfa table holding the TOTP second factor of a user
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id BIGINT PRIMARY KEY,
+    secret TEXT NOT NULL,                                   -- TOTP secret, encrypted at rest
+    enabled_at TIMESTAMP WITH TIME ZONE NULL,               -- NULL while the enrolment waits for its first code
+    last_used_step BIGINT NOT NULL DEFAULT 0,               -- Time step of the last accepted code, a code is never accepted twice
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user_mfa_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create the mfa_recovery_code table holding single-use codes for a lost authenticator
+CREATE TABLE IF NOT EXISTS mfa_recovery_code (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,                         -- SHA-256 of the recovery code
+    used_at TIMESTAMP WITH TIME ZONE NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_mfa_recovery_code_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create an index for looking up a recovery code of a user
+CREATE INDEX idx_mfa_recovery_code_user_id ON mfa_recovery_code(user_id, code_hash);
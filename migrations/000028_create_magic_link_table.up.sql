@@ -0,0 +1,13 @@
+-- Create the magic_link table holding single-use passwordless sign-in tokens
+CREATE TABLE IF NOT EXISTS magic_link (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,                 -- SHA-256 of the token, the token itself is never stored
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,           -- The link can not be used after this time
+    used_at TIMESTAMP WITH TIME ZONE NULL,                  -- Set once the link has been used
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_magic_link_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Create an index for the user_id column for faster lookups
+CREATE INDEX idx_magic_link_user_id ON magic_link(user_id);
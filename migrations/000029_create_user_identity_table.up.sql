@@ -0,0 +1,15 @@
+-- Create the user_identity table linking accounts of external identity providers to a user
+CREATE TABLE IF NOT EXISTS user_identity (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    provider VARCHAR(50) NOT NULL,                          -- Identity provider, e.g. google or apple
+    subject VARCHAR(255) NOT NULL,                          -- The "sub" claim, stable per provider account
+    email VARCHAR(255) NOT NULL DEFAULT '',                 -- Email reported by the provider when linked
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user_identity_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT uq_user_identity_provider_subject UNIQUE (provider, subject),
+    CONSTRAINT uq_user_identity_user_provider UNIQUE (user_id, provider)
+);
+
+-- Create an index for the user_id column for faster lookups
+CREATE INDEX idx_user_identity_user_id ON user_identity(user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE cart_item DROP CONSTRAINT IF EXISTS chk_cart_item_quantity;
+DROP INDEX IF EXISTS uq_cart_item_bundling;
+DROP INDEX IF EXISTS uq_cart_item_menu;
+DROP INDEX IF EXISTS uq_cart_user_active;
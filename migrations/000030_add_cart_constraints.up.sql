@@ -0,0 +1,9 @@
+-- A user has at most one active cart, the one being filled
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cart_user_active ON cart(user_id) WHERE status = 'active';
+
+-- A menu or bundling appears once per cart, adding it again raises its quantity
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cart_item_menu ON cart_item(cart_id, menu_id) WHERE menu_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_cart_item_bundling ON cart_item(cart_id, bundling_id) WHERE bundling_id IS NOT NULL;
+
+-- Quantities are always positive, removing an item deletes its row
+ALTER TABLE cart_item ADD CONSTRAINT chk_cart_item_quantity CHECK (quantity > 0);
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS order_status_history;
+
+ALTER TABLE transaction DROP CONSTRAINT IF EXISTS chk_transaction_status;
+
+UPDATE transaction SET status = CASE status
+    WHEN 'pending_payment' THEN 'unpaid'
+    WHEN 'paid' THEN 'ongoing'
+    WHEN 'preparing' THEN 'ongoing'
+    WHEN 'out_for_delivery' THEN 'ongoing'
+    WHEN 'delivered' THEN 'completed'
+    ELSE 'failed'
+END;
@@ -0,0 +1,32 @@
+-- Orders follow an explicit state machine, map the free-form statuses used so far onto it
+UPDATE transaction SET status = CASE status
+    WHEN 'unpaid' THEN 'pending_payment'
+    WHEN 'success' THEN 'delivered'
+    WHEN 'completed' THEN 'delivered'
+    WHEN 'ongoing' THEN 'preparing'
+    ELSE status
+END;
+UPDATE transaction SET status = 'failed'
+WHERE status NOT IN ('pending_payment', 'paid', 'preparing', 'out_for_delivery', 'delivered', 'cancelled', 'refunded', 'failed');
+
+ALTER TABLE transaction ADD CONSTRAINT chk_transaction_status CHECK (status IN (
+    'pending_payment', 'paid', 'preparing', 'out_for_delivery', 'delivered', 'cancelled', 'refunded', 'failed'
+));
+
+-- Table for the status changes of an order, the timeline shown to the customer
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id BIGSERIAL PRIMARY KEY,                                                    -- Unique identifier for the entry
+    transaction_id BIGINT NOT NULL REFERENCES transaction(id) ON DELETE CASCADE, -- The order whose status changed
+    from_status VARCHAR(30),                                                     -- Previous status, NULL when the order was placed
+    to_status VARCHAR(30) NOT NULL,                                              -- New status
+    actor_type VARCHAR(30) NOT NULL,                                             -- 'customer', 'staff', 'payment_provider' or 'system'
+    actor_id BIGINT REFERENCES users(id) ON DELETE SET NULL,                     -- User who made the change, if any
+    note TEXT NOT NULL DEFAULT '',                                               -- Optional reason given by the actor
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP                -- When the change happened
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_transaction ON order_status_history(transaction_id, created_at);
+
+-- Existing orders start their timeline at their current status
+INSERT INTO order_status_history (transaction_id, from_status, to_status, actor_type, created_at)
+SELECT id, NULL, status, 'system', created_at FROM transaction;
@@ -0,0 +1,12 @@
+-- Table for requests sent with an Idempotency-Key, retries replay the recorded response
+CREATE TABLE IF NOT EXISTS idempotency_key (
+    key VARCHAR(300) PRIMARY KEY,                                -- Client key prefixed with the user it belongs to
+    fingerprint VARCHAR(64) NOT NULL,                            -- SHA-256 of the method, path and body of the request
+    status_code INT,                                             -- Recorded response status, NULL while the request is handled
+    content_type VARCHAR(255) NOT NULL DEFAULT '',               -- Recorded response content type
+    body BYTEA,                                                  -- Recorded response body
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL                 -- The key can be used for a new request afterwards
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_key_expires_at ON idempotency_key(expires_at);
@@ -0,0 +1,8 @@
+DELETE FROM permissions WHERE name = 'promotions:manage';
+
+ALTER TABLE transaction DROP COLUMN IF EXISTS discount;
+ALTER TABLE transaction DROP COLUMN IF EXISTS voucher_id;
+
+DROP TABLE IF EXISTS voucher_redemption;
+DROP TABLE IF EXISTS voucher_eligibility;
+DROP TABLE IF EXISTS voucher;
@@ -0,0 +1,59 @@
+-- Table for promo codes
+CREATE TABLE IF NOT EXISTS voucher (
+    id BIGSERIAL PRIMARY KEY,                                                 -- Unique identifier for the voucher
+    code VARCHAR(50) NOT NULL UNIQUE,                                         -- Code entered by the customer, stored in upper case
+    description TEXT NOT NULL DEFAULT '',                                     -- Campaign description shown to customers
+    discount_type VARCHAR(20) NOT NULL CHECK (discount_type IN ('percentage', 'fixed')),
+    discount_value DECIMAL(10, 2) NOT NULL CHECK (discount_value > 0),        -- Percentage (0-100] or rupiah amount
+    min_spend DECIMAL(10, 2) NOT NULL DEFAULT 0,                              -- Minimum subtotal of the eligible items
+    max_discount DECIMAL(10, 2),                                              -- Cap of a percentage discount, NULL for none
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,    -- Start of the validity window
+    ends_at TIMESTAMP WITH TIME ZONE,                                         -- End of the validity window, NULL for none
+    usage_limit INT,                                                          -- Redemptions across all users, NULL for unlimited
+    usage_limit_per_user INT,                                                 -- Redemptions per user, NULL for unlimited
+    active BOOLEAN NOT NULL DEFAULT TRUE,                                     -- Inactive vouchers can no longer be redeemed
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CHECK (discount_type <> 'percentage' OR discount_value <= 100),
+    CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE TRIGGER update_voucher_updated_at
+BEFORE UPDATE ON voucher
+FOR EACH ROW
+EXECUTE FUNCTION update_timestamp();
+
+-- Items a voucher applies to, a voucher without rows applies to the whole cart
+CREATE TABLE IF NOT EXISTS voucher_eligibility (
+    id BIGSERIAL PRIMARY KEY,
+    voucher_id BIGINT NOT NULL REFERENCES voucher(id) ON DELETE CASCADE,
+    category_id BIGINT REFERENCES category(id) ON DELETE CASCADE,   -- Menus of the category
+    menu_id BIGINT REFERENCES menu(id) ON DELETE CASCADE,           -- A single menu
+    bundling_type VARCHAR(50),                                      -- Bundlings of the type, e.g. 'weekly'
+    CHECK ((category_id IS NOT NULL)::INT + (menu_id IS NOT NULL)::INT + (bundling_type IS NOT NULL)::INT = 1)
+);
+
+CREATE INDEX IF NOT EXISTS idx_voucher_eligibility_voucher_id ON voucher_eligibility(voucher_id);
+
+-- Table for vouchers redeemed at checkout, one per order
+CREATE TABLE IF NOT EXISTS voucher_redemption (
+    id BIGSERIAL PRIMARY KEY,
+    voucher_id BIGINT NOT NULL REFERENCES voucher(id) ON DELETE CASCADE,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    transaction_id BIGINT NOT NULL UNIQUE REFERENCES transaction(id) ON DELETE CASCADE,
+    discount DECIMAL(10, 2) NOT NULL,                               -- Discount granted on the order
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_voucher_redemption_voucher_user ON voucher_redemption(voucher_id, user_id);
+
+-- Orders keep the discount they were placed with
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS voucher_id BIGINT REFERENCES voucher(id) ON DELETE SET NULL;
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS discount DECIMAL(10, 2) NOT NULL DEFAULT 0;
+
+-- Admins run the campaigns
+INSERT INTO permissions (name, description) VALUES
+    ('promotions:manage', 'Manage vouchers and promo codes');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p ON p.name = 'promotions:manage' WHERE r.name = 'admin';
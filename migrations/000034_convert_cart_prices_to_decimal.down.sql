@@ -0,0 +1,8 @@
+ALTER TABLE voucher DROP CONSTRAINT IF EXISTS voucher_discount_check;
+ALTER TABLE voucher ADD COLUMN IF NOT EXISTS discount_value DECIMAL(10, 2) NOT NULL DEFAULT 0;
+UPDATE voucher SET discount_value = CASE WHEN discount_type = 'fixed' THEN discount_amount ELSE discount_percent END;
+ALTER TABLE voucher DROP COLUMN IF EXISTS discount_percent;
+ALTER TABLE voucher DROP COLUMN IF EXISTS discount_amount;
+
+ALTER TABLE cart_item ALTER COLUMN price TYPE DOUBLE PRECISION USING price::DOUBLE PRECISION;
+ALTER TABLE cart ALTER COLUMN total_price TYPE DOUBLE PRECISION USING total_price::DOUBLE PRECISION;
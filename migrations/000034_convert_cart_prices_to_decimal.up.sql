@@ -0,0 +1,17 @@
+-- Cart prices were floating point, which drifts once discounts and fees are added.
+-- They become exact decimals like every other price, in whole rupiah.
+ALTER TABLE cart ALTER COLUMN total_price TYPE DECIMAL(10, 2) USING ROUND(total_price::NUMERIC);
+ALTER TABLE cart_item ALTER COLUMN price TYPE DECIMAL(10, 2) USING ROUND(price::NUMERIC);
+
+-- A voucher discount is either a rupiah amount or a percentage, never one value that means both
+ALTER TABLE voucher ADD COLUMN IF NOT EXISTS discount_amount DECIMAL(10, 2) NOT NULL DEFAULT 0;  -- Rupiah off a fixed voucher
+ALTER TABLE voucher ADD COLUMN IF NOT EXISTS discount_percent DECIMAL(5, 2) NOT NULL DEFAULT 0;  -- Percent off a percentage voucher
+
+UPDATE voucher SET discount_amount = ROUND(discount_value) WHERE discount_type = 'fixed';
+UPDATE voucher SET discount_percent = discount_value WHERE discount_type = 'percentage';
+
+ALTER TABLE voucher DROP COLUMN IF EXISTS discount_value;
+ALTER TABLE voucher ADD CONSTRAINT voucher_discount_check CHECK (
+    (discount_type = 'fixed' AND discount_amount > 0 AND discount_percent = 0)
+    OR (discount_type = 'percentage' AND discount_percent > 0 AND discount_percent <= 100 AND discount_amount = 0)
+);
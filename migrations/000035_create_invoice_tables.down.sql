@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS prevent_invoice_change ON invoice;
+DROP TABLE IF EXISTS invoice;
+DROP FUNCTION IF EXISTS prevent_invoice_change;
+DROP TABLE IF EXISTS invoice_sequence;
+
+ALTER TABLE transaction DROP COLUMN IF EXISTS tax_rate;
+ALTER TABLE transaction DROP COLUMN IF EXISTS tax;
+ALTER TABLE transaction DROP COLUMN IF EXISTS service_fee_rate;
+ALTER TABLE transaction DROP COLUMN IF EXISTS service_fee;
+ALTER TABLE transaction DROP COLUMN IF EXISTS courier_fee;
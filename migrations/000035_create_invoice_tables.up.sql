@@ -0,0 +1,63 @@
+-- Fees charged at checkout, kept on the order so its invoice adds up to the payment
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS courier_fee DECIMAL(10, 2) NOT NULL DEFAULT 0;
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS service_fee DECIMAL(10, 2) NOT NULL DEFAULT 0;
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS service_fee_rate DECIMAL(5, 2) NOT NULL DEFAULT 0;  -- Percent of the discounted items
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS tax DECIMAL(10, 2) NOT NULL DEFAULT 0;              -- PPN
+ALTER TABLE transaction ADD COLUMN IF NOT EXISTS tax_rate DECIMAL(5, 2) NOT NULL DEFAULT 0;         -- PPN percent of the taxable amount
+
+-- Older orders were charged the items minus the discount plus the courier, the rest of the amount is the courier fee
+UPDATE transaction t SET courier_fee = GREATEST(t.amount - (c.total_price - t.discount), 0)
+FROM cart c WHERE c.id = t.cart_id;
+
+-- Last invoice number handed out per month, numbers restart every month
+CREATE TABLE IF NOT EXISTS invoice_sequence (
+    period CHAR(6) PRIMARY KEY,   -- Month of issue in WIB, 'YYYYMM'
+    last_number INT NOT NULL
+);
+
+-- Table for invoices, a snapshot of the order at the time of issue
+CREATE TABLE IF NOT EXISTS invoice (
+    id BIGSERIAL PRIMARY KEY,
+    number VARCHAR(30) NOT NULL UNIQUE,                                         -- e.g. 'INV/202610/00042'
+    transaction_id BIGINT UNIQUE REFERENCES transaction(id) ON DELETE SET NULL, -- The invoiced order, one invoice per order
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,                     -- The billed customer
+    customer_name VARCHAR(255) NOT NULL DEFAULT '',
+    customer_email VARCHAR(255) NOT NULL DEFAULT '',
+    recipient_name VARCHAR(255) NOT NULL DEFAULT '',
+    recipient_phone VARCHAR(50) NOT NULL DEFAULT '',
+    delivery_address TEXT NOT NULL DEFAULT '',
+    courier VARCHAR(255) NOT NULL DEFAULT '',
+    payment_method VARCHAR(10) NOT NULL DEFAULT '',
+    lines JSONB NOT NULL DEFAULT '[]',                                          -- Items with quantity, unit price and amount
+    items_total DECIMAL(10, 2) NOT NULL,
+    discount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    courier_fee DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    service_fee DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    service_fee_rate DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    tax DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    tax_rate DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    total DECIMAL(10, 2) NOT NULL,
+    ordered_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    issued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_invoice_user_id ON invoice(user_id);
+
+-- Issued invoices never change. Deleting the order or the user only detaches the invoice.
+CREATE OR REPLACE FUNCTION prevent_invoice_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+       AND (NEW.transaction_id IS NULL OR NEW.transaction_id = OLD.transaction_id)
+       AND (NEW.user_id IS NULL OR NEW.user_id = OLD.user_id)
+       AND to_jsonb(NEW) - 'transaction_id' - 'user_id' = to_jsonb(OLD) - 'transaction_id' - 'user_id' THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'invoice % is immutable', OLD.number;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER prevent_invoice_change
+BEFORE UPDATE OR DELETE ON invoice
+FOR EACH ROW
+EXECUTE FUNCTION prevent_invoice_change();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_payment_refund_required;
+
+ALTER TABLE payment DROP COLUMN IF EXISTS refund_required;
@@ -0,0 +1,4 @@
+-- Set when money is captured for an order that was cancelled, staff have to refund it
+ALTER TABLE payment ADD COLUMN IF NOT EXISTS refund_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_payment_refund_required ON payment(id) WHERE refund_required;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_payment_unpaid_checked_at;
+
+ALTER TABLE payment DROP COLUMN IF EXISTS checked_at;
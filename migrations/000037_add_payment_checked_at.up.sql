@@ -0,0 +1,4 @@
+-- Set every time the reconciler asks the provider about an unpaid payment, the least recently checked go first
+ALTER TABLE payment ADD COLUMN IF NOT EXISTS checked_at TIMESTAMP WITH TIME ZONE NULL;
+
+CREATE INDEX IF NOT EXISTS idx_payment_unpaid_checked_at ON payment(checked_at NULLS FIRST) WHERE status = 'unpaid';
@@ -0,0 +1,2 @@
+-- In-flight reservations hold their key only briefly, a request that never completes frees it long before expires_at
+ALTER TABLE idempotency_key ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP WITH TIME ZONE NULL;
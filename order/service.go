@@ -0,0 +1,163 @@
+package order
+
+import (
+	"context"
+	"santapan/domain"
+	"santapan/policy"
+
+	"github.com/sirupsen/logrus"
+)
+
+type PostgresRepositoryQueries interface {
+	Fetch(ctx context.Context, userID int64, filter domain.OrderFilter, cursor string, num int64) ([]domain.Transaction, string, error)
+	GetByID(ctx context.Context, id int64) (domain.Transaction, error)
+	FetchHistory(ctx context.Context, transactionID int64) ([]domain.OrderStatusHistory, error)
+}
+
+type PostgresRepositoryCommand interface {
+	UpdateStatus(ctx context.Context, id int64, status domain.OrderStatus, actor domain.OrderActor, note string) (domain.OrderStatus, error)
+}
+
+// CartRepository reads the checked out cart holding the items of an order
+type CartRepository interface {
+	GetByID(ctx context.Context, id int64) (domain.Cart, error)
+}
+
+// AddressRepository reads the delivery address of an order
+type AddressRepository interface {
+	GetByID(ctx context.Context, id int64) (domain.Address, error)
+}
+
+// CourierRepository reads the courier of an order
+type CourierRepository interface {
+	FetchByID(ctx context.Context, id int) (*domain.Courier, error)
+}
+
+// PaymentRepository reads the payment of an order
+type PaymentRepository interface {
+	GetByID(ctx context.Context, id int64) (domain.Payment, error)
+}
+
+type Service struct {
+	postgresRepoQuery   PostgresRepositoryQueries
+	postgresRepoCommand PostgresRepositoryCommand
+	cartRepo            CartRepository
+	addressRepo         AddressRepository
+	courierRepo         CourierRepository
+	paymentRepo         PaymentRepository
+}
+
+// NewService will create a new order service object
+func NewService(pq PostgresRepositoryQueries, pc PostgresRepositoryCommand, cartRepo CartRepository, addressRepo AddressRepository, courierRepo CourierRepository, paymentRepo PaymentRepository) *Service {
+	return &Service{
+		postgresRepoQuery:   pq,
+		postgresRepoCommand: pc,
+		cartRepo:            cartRepo,
+		addressRepo:         addressRepo,
+		courierRepo:         courierRepo,
+		paymentRepo:         paymentRepo,
+	}
+}
+
+// Fetch lists the orders of the user, newest first
+func (s *Service) Fetch(ctx context.Context, userID int64, filter domain.OrderFilter, cursor string, num int64) ([]domain.Transaction, string, error) {
+	return s.postgresRepoQuery.Fetch(ctx, userID, filter, cursor, num)
+}
+
+// GetByID returns an order of the user, orders of other users are reported as domain.ErrNotFound
+func (s *Service) GetByID(ctx context.Context, userID int64, id int64) (domain.Transaction, error) {
+	return policy.Load(ctx, userID, id, s.postgresRepoQuery.GetByID)
+}
+
+// GetDetail returns an order of the user with its items, address, courier, payment
+// and timeline. The items are the ones priced at checkout, later menu changes do not
+// show up.
+func (s *Service) GetDetail(ctx context.Context, userID int64, id int64) (domain.OrderDetail, error) {
+	order, err := s.GetByID(ctx, userID, id)
+	if err != nil {
+		return domain.OrderDetail{}, err
+	}
+
+	detail := domain.OrderDetail{Transaction: order, Items: make([]domain.CartItem, 0)}
+
+	cart, err := s.cartRepo.GetByID(ctx, order.CartID)
+	switch err {
+	case nil:
+		detail.Items = cart.Items
+	case domain.ErrNotFound:
+	default:
+		return domain.OrderDetail{}, err
+	}
+
+	detail.Address, err = s.addressRepo.GetByID(ctx, order.AddressID)
+	if err != nil && err != domain.ErrNotFound {
+		return domain.OrderDetail{}, err
+	}
+
+	courier, err := s.courierRepo.FetchByID(ctx, int(order.CourierID))
+	if err != nil {
+		return domain.OrderDetail{}, err
+	}
+	if courier != nil {
+		detail.Courier = *courier
+	}
+
+	detail.Payment, err = s.paymentRepo.GetByID(ctx, order.PaymentID)
+	if err != nil && err != domain.ErrNotFound {
+		return domain.OrderDetail{}, err
+	}
+
+	detail.Timeline, err = s.postgresRepoQuery.FetchHistory(ctx, order.ID)
+	if err != nil {
+		return domain.OrderDetail{}, err
+	}
+
+	return detail, nil
+}
+
+// Timeline returns the status changes of an order of the user, oldest first
+func (s *Service) Timeline(ctx context.Context, userID int64, id int64) ([]domain.OrderStatusHistory, error) {
+	order, err := s.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.postgresRepoQuery.FetchHistory(ctx, order.ID)
+}
+
+// Cancel cancels an order of the user and expires its payment. Only orders that are
+// not paid yet can be cancelled, anything else returns domain.ErrInvalidTransition.
+// Money the provider still captures afterwards marks the payment for a refund.
+func (s *Service) Cancel(ctx context.Context, userID int64, id int64, note string) (domain.Transaction, error) {
+	order, err := s.GetByID(ctx, userID, id)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	return s.UpdateStatus(ctx, domain.OrderActor{Type: domain.OrderActorCustomer, ID: userID}, order.ID, domain.OrderStatusCancelled, note)
+}
+
+// UpdateStatus moves an order to a new status on behalf of the actor. Returns
+// domain.ErrBadParamInput for an unknown status, domain.ErrNotFound for an unknown
+// order and domain.ErrInvalidTransition when the order can not move to the status.
+func (s *Service) UpdateStatus(ctx context.Context, actor domain.OrderActor, id int64, status domain.OrderStatus, note string) (domain.Transaction, error) {
+	if !status.Valid() {
+		return domain.Transaction{}, domain.ErrBadParamInput
+	}
+
+	from, err := s.postgresRepoCommand.UpdateStatus(ctx, id, status, actor, note)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":          "order_status_changed",
+		"transaction_id": id,
+		"from":           from,
+		"to":             status,
+		"actor_type":     actor.Type,
+		"actor_id":       actor.ID,
+	}).Info("Order status changed")
+
+	return s.postgresRepoQuery.GetByID(ctx, id)
+}
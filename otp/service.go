@@ -2,8 +2,23 @@ package otp
 
 import (
 	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
 	"santapan/domain"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// CodeTTL is how long a code stays valid
+	CodeTTL = time.Minute * 5
+	// MaxRetry is the number of wrong codes accepted before the code is burned
+	MaxRetry = 5
+	// ResendInterval is the minimum time between two codes for the same user
+	ResendInterval = time.Minute
+	codeLength     = 6
 )
 
 type PostgresRepositoryQueries interface {
@@ -14,20 +29,29 @@ type PostgresRepositoryQueries interface {
 type PostgresRepositoryCommand interface {
 	Store(ctx context.Context, otp *domain.Otp) (err error)
 	Delete(ctx context.Context, id int64) (err error)
+	DeleteByUserID(ctx context.Context, userID int64) (err error)
 	Update(ctx context.Context, otp *domain.Otp) (err error)
+	IncrementRetry(ctx context.Context, id int64, maxRetry int) (int, error)
+}
+
+// OtpSender delivers a code over one channel (SMS, WhatsApp, ...)
+type OtpSender interface {
+	Send(ctx context.Context, phone string, message string) error
 }
 
 //go:generate mockery --name ArticleRepository
 type Service struct {
 	postgresRepoQuery   PostgresRepositoryQueries
 	postgresRepoCommand PostgresRepositoryCommand
+	senders             map[domain.OtpType]OtpSender
 }
 
-// NewService will create a new article service object
-func NewService(pq PostgresRepositoryQueries, pc PostgresRepositoryCommand) *Service {
+// NewService will create a new otp service object
+func NewService(pq PostgresRepositoryQueries, pc PostgresRepositoryCommand, senders map[domain.OtpType]OtpSender) *Service {
 	return &Service{
 		postgresRepoQuery:   pq,
 		postgresRepoCommand: pc,
+		senders:             senders,
 	}
 }
 
@@ -65,3 +89,113 @@ func (s *Service) Delete(ctx context.Context, id int64) (err error) {
 	}
 	return s.postgresRepoCommand.Delete(ctx, existedToken.ID)
 }
+
+// Request generates a new code for the user, replacing any earlier one, and sends
+// it to the phone over the given channel. Only a bcrypt hash of the code is stored.
+func (s *Service) Request(ctx context.Context, userID int64, phone string, channel domain.OtpType, deviceID string) (err error) {
+	sender, ok := s.senders[channel]
+	if !ok {
+		return domain.ErrBadParamInput
+	}
+
+	latest, err := s.postgresRepoQuery.GetByUserID(ctx, userID)
+	if err != nil && err != domain.ErrNotFound {
+		return
+	}
+	if err == nil && time.Since(latest.CreatedAt) < ResendInterval {
+		return domain.ErrTooManyAttempts
+	}
+
+	code, err := generateCode()
+	if err != nil {
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash otp: %w", err)
+	}
+
+	if err = s.postgresRepoCommand.DeleteByUserID(ctx, userID); err != nil {
+		return
+	}
+
+	otp := domain.Otp{
+		UserId:    userID,
+		Phone:     phone,
+		Code:      string(hash),
+		Type:      string(channel),
+		DeviceId:  deviceID,
+		ExpiresAt: time.Now().Add(CodeTTL),
+	}
+	if err = s.postgresRepoCommand.Store(ctx, &otp); err != nil {
+		return
+	}
+
+	message := fmt.Sprintf("Kode verifikasi Santapan Anda: %s. Berlaku %d menit. Jangan berikan kode ini kepada siapa pun.", code, int(CodeTTL.Minutes()))
+	if err = sender.Send(ctx, phone, message); err != nil {
+		return fmt.Errorf("failed to send otp: %w", err)
+	}
+
+	return nil
+}
+
+// Verify checks the code sent to the phone. Every wrong code counts against
+// MaxRetry; the code is deleted once used, expired or out of retries.
+func (s *Service) Verify(ctx context.Context, userID int64, phone string, code string) (err error) {
+	otp, err := s.postgresRepoQuery.GetByUserID(ctx, userID)
+	if err == domain.ErrNotFound {
+		return domain.ErrInvalidOtp
+	}
+	if err != nil {
+		return
+	}
+
+	if otp.Phone != phone {
+		return domain.ErrInvalidOtp
+	}
+
+	if time.Now().After(otp.ExpiresAt) {
+		if err = s.postgresRepoCommand.Delete(ctx, otp.ID); err != nil {
+			return
+		}
+		return domain.ErrInvalidOtp
+	}
+
+	// The attempt is counted before the code is compared, so concurrent guesses can not
+	// get past MaxRetry. The attempt that uses up the last retry deletes the code.
+	retry, err := s.postgresRepoCommand.IncrementRetry(ctx, otp.ID, MaxRetry)
+	if err == domain.ErrNotFound {
+		return domain.ErrTooManyAttempts
+	}
+	if err != nil {
+		return
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(otp.Code), []byte(code)) != nil {
+		if retry >= MaxRetry {
+			if err = s.postgresRepoCommand.Delete(ctx, otp.ID); err != nil {
+				return
+			}
+			return domain.ErrTooManyAttempts
+		}
+		return domain.ErrInvalidOtp
+	}
+
+	return s.postgresRepoCommand.Delete(ctx, otp.ID)
+}
+
+// generateCode returns a uniformly random numeric code
+func generateCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < codeLength; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate otp: %w", err)
+	}
+
+	return fmt.Sprintf("%0*d", codeLength, n.Int64()), nil
+}
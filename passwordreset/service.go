@@ -0,0 +1,89 @@
+package passwordreset
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"santapan/domain"
+	"time"
+)
+
+// PostgresRepositoryCommand defines the methods for executing commands on the password reset repository.
+type PostgresRepositoryCommand interface {
+	Store(ctx context.Context, reset *domain.PasswordReset) error
+	Consume(ctx context.Context, tokenHash string) (domain.PasswordReset, error)
+	InvalidateByUserID(ctx context.Context, userID int64) error
+	CountSince(ctx context.Context, userID int64, since time.Time) (int64, error)
+}
+
+const (
+	// TokenTTL is how long a password reset token stays valid
+	TokenTTL = time.Hour
+	// ResendInterval is the minimum time between two reset links for the same user
+	ResendInterval = time.Minute * 2
+)
+
+type Service struct {
+	postgresRepoCommand PostgresRepositoryCommand
+}
+
+// NewService will create a new password reset service object
+func NewService(pc PostgresRepositoryCommand) *Service {
+	return &Service{
+		postgresRepoCommand: pc,
+	}
+}
+
+// Create issues a new reset token for the user, invalidating any earlier one.
+// Only the hash of the token is stored; the plain token is returned to be mailed.
+// Returns domain.ErrTooManyAttempts when a link was sent within ResendInterval.
+func (s *Service) Create(ctx context.Context, userID int64) (token string, err error) {
+	recent, err := s.postgresRepoCommand.CountSince(ctx, userID, time.Now().Add(-ResendInterval))
+	if err != nil {
+		return "", err
+	}
+	if recent > 0 {
+		return "", domain.ErrTooManyAttempts
+	}
+
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	token = hex.EncodeToString(b)
+
+	if err = s.postgresRepoCommand.InvalidateByUserID(ctx, userID); err != nil {
+		return "", err
+	}
+
+	reset := domain.PasswordReset{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(TokenTTL),
+	}
+	if err = s.postgresRepoCommand.Store(ctx, &reset); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// Consume uses up the token and returns the ID of the user it was issued for
+func (s *Service) Consume(ctx context.Context, token string) (userID int64, err error) {
+	reset, err := s.postgresRepoCommand.Consume(ctx, hashToken(token))
+	if err == domain.ErrNotFound {
+		return 0, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return reset.UserID, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
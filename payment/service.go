@@ -0,0 +1,205 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"santapan/domain"
+	"santapan/pkg/paymentgateway"
+	"santapan/policy"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ReconcileAfter is how long a payment waits for its webhook before the provider is asked
+	ReconcileAfter = 10 * time.Minute
+	// reconcileBatch limits the payments checked per run
+	reconcileBatch = 100
+)
+
+type PostgresRepositoryQueries interface {
+	GetByID(ctx context.Context, id int64) (domain.Payment, error)
+	GetBySessionID(ctx context.Context, sessionID string) (domain.Payment, error)
+	FetchPending(ctx context.Context, before time.Time, limit int) ([]domain.Payment, error)
+}
+
+type PostgresRepositoryCommand interface {
+	UpdateSession(ctx context.Context, id int64, sessionID string, url string) error
+	MarkChecked(ctx context.Context, id int64) error
+	UpdateStatus(ctx context.Context, id int64, paymentStatus string, orderStatus domain.OrderStatus) (bool, bool, error)
+}
+
+type Service struct {
+	postgresRepoQuery   PostgresRepositoryQueries
+	postgresRepoCommand PostgresRepositoryCommand
+	provider            paymentgateway.Provider
+	returnURL           string
+}
+
+// NewService will create a new payment service object. Customers are sent back to
+// returnURL after paying on the hosted page of the provider.
+func NewService(pq PostgresRepositoryQueries, pc PostgresRepositoryCommand, provider paymentgateway.Provider, returnURL string) *Service {
+	return &Service{
+		postgresRepoQuery:   pq,
+		postgresRepoCommand: pc,
+		provider:            provider,
+		returnURL:           returnURL,
+	}
+}
+
+// GetByID returns a payment of the user, payments of other users are reported as domain.ErrNotFound
+func (s *Service) GetByID(ctx context.Context, userID int64, id int64) (domain.Payment, error) {
+	return policy.Load(ctx, userID, id, s.postgresRepoQuery.GetByID)
+}
+
+// StartSession creates a hosted payment session for an unpaid payment and stores its
+// URL on the payment. Returns domain.ErrConflict for a payment that is already settled.
+func (s *Service) StartSession(ctx context.Context, payment *domain.Payment) error {
+	if payment.Status != domain.PaymentStatusUnpaid {
+		return domain.ErrConflict
+	}
+
+	session, err := s.provider.CreateSession(ctx, paymentgateway.SessionRequest{
+		Reference: reference(payment.ID),
+		Amount:    int64(payment.Amount),
+		Method:    payment.ReferenceID,
+		ReturnURL: s.returnURL,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s payment session: %w", s.provider.Name(), err)
+	}
+
+	if err = s.postgresRepoCommand.UpdateSession(ctx, payment.ID, session.ID, session.URL); err != nil {
+		return err
+	}
+
+	payment.SessionID = session.ID
+	payment.URL = session.URL
+	return nil
+}
+
+// HandleWebhook verifies a webhook callback of the provider and applies the status it
+// reports. Returns domain.ErrInvalidToken when the signature does not verify.
+func (s *Service) HandleWebhook(ctx context.Context, header http.Header, body []byte) error {
+	event, err := s.provider.ParseWebhook(header, body)
+	if err == paymentgateway.ErrInvalidSignature {
+		logrus.WithFields(logrus.Fields{
+			"event":    "payment_webhook_rejected",
+			"provider": s.provider.Name(),
+		}).Warn("Payment webhook with an invalid signature")
+		return domain.ErrInvalidToken
+	}
+	if err != nil {
+		return err
+	}
+
+	payment, err := s.postgresRepoQuery.GetBySessionID(ctx, event.SessionID)
+	if err != nil {
+		return err
+	}
+
+	return s.apply(ctx, payment, event.Status)
+}
+
+// Reconcile asks the provider about payments that stayed unpaid longer than
+// ReconcileAfter, which settles payments whose webhook was lost. Every payment is
+// marked checked first, so payments that stay pending or keep failing move to the
+// back of the queue instead of holding up the rest.
+func (s *Service) Reconcile(ctx context.Context) error {
+	payments, err := s.postgresRepoQuery.FetchPending(ctx, time.Now().Add(-ReconcileAfter), reconcileBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, payment := range payments {
+		if err = s.postgresRepoCommand.MarkChecked(ctx, payment.ID); err != nil {
+			return err
+		}
+
+		status, err := s.provider.GetStatus(ctx, payment.SessionID)
+		if err == paymentgateway.ErrSessionNotFound {
+			// The provider forgot the session, nobody can pay it any more
+			status = paymentgateway.StatusExpired
+		} else if err != nil {
+			logrus.Errorf("Failed to get status of payment %d: %v", payment.ID, err)
+			continue
+		}
+
+		if err = s.apply(ctx, payment, status); err != nil {
+			logrus.Errorf("Failed to reconcile payment %d: %v", payment.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// RunReconciler calls Reconcile every interval until the context is cancelled
+func (s *Service) RunReconciler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Reconcile(ctx); err != nil {
+			logrus.Errorf("Failed to reconcile payments: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// apply maps the provider status onto the payment and its orders. An expired
+// payment fails the order, the customer has to check out again.
+func (s *Service) apply(ctx context.Context, payment domain.Payment, status paymentgateway.Status) error {
+	var (
+		paymentStatus string
+		orderStatus   domain.OrderStatus
+	)
+	switch status {
+	case paymentgateway.StatusPaid:
+		paymentStatus, orderStatus = domain.PaymentStatusPaid, domain.OrderStatusPaid
+	case paymentgateway.StatusFailed:
+		paymentStatus, orderStatus = domain.PaymentStatusFailed, domain.OrderStatusFailed
+	case paymentgateway.StatusExpired:
+		paymentStatus, orderStatus = domain.PaymentStatusExpired, domain.OrderStatusFailed
+	default:
+		return nil
+	}
+
+	updated, refund, err := s.postgresRepoCommand.UpdateStatus(ctx, payment.ID, paymentStatus, orderStatus)
+	if err != nil {
+		return err
+	}
+
+	if refund {
+		logrus.WithFields(logrus.Fields{
+			"event":      "payment_refund_required",
+			"payment_id": payment.ID,
+			"user_id":    payment.UserID,
+			"provider":   s.provider.Name(),
+			"amount":     payment.Amount,
+		}).Warn("Payment captured for a cancelled order, it has to be refunded")
+	}
+
+	if updated {
+		logrus.WithFields(logrus.Fields{
+			"event":      "payment_settled",
+			"payment_id": payment.ID,
+			"user_id":    payment.UserID,
+			"provider":   s.provider.Name(),
+			"status":     paymentStatus,
+		}).Info("Payment settled")
+	}
+
+	return nil
+}
+
+// reference is the identifier of a payment shared with the provider
+func reference(paymentID int64) string {
+	return fmt.Sprintf("PAY-%d", paymentID)
+}
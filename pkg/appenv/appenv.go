@@ -0,0 +1,11 @@
+// Package appenv tells development environments apart from production ones
+package appenv
+
+import "os"
+
+// Development reports whether APP_ENV is "development". Insecure conveniences such as
+// built-in secrets and the fake payment provider are only available there, anywhere
+// else a missing setting stops the server from starting.
+func Development() bool {
+	return os.Getenv("APP_ENV") == "development"
+}
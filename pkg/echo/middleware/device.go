@@ -1,7 +1,6 @@
 package echo
 
 import (
-	"log"
 	"santapan/domain"
 
 	"github.com/labstack/echo/v4"
@@ -16,10 +15,9 @@ func DeviceInfoMiddleware() echo.MiddlewareFunc {
 				DeviceName:  c.Request().Header.Get("devicename"),
 				DeviceBrand: c.Request().Header.Get("devicebrand"),
 				DeviceModel: c.Request().Header.Get("devicemodel"),
-				IPAddress:   c.Request().Header.Get("ipaddress"),
+				IPAddress:   c.RealIP(), // Resolved by the IP extractor configured in Setup
 			}
 
-			log.Default().Println("Device Info: ", deviceInfo)
 			// Store device info in context
 			c.Set("deviceInfo", deviceInfo)
 
@@ -2,8 +2,10 @@ package echo
 
 import (
 	"log"
+	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	localMiddleware "santapan/pkg/echo/middleware"
@@ -19,11 +21,13 @@ const (
 
 func Setup() *echo.Echo {
 	e := echo.New()
+	e.IPExtractor = ipExtractor()
 
 	// Middleware
 	e.Use(echoMiddleware.Logger())
 	e.Use(echoMiddleware.Recover())
 	e.Use(localMiddleware.CORS)
+	e.Use(localMiddleware.DeviceInfoMiddleware())
 
 	timeoutStr := os.Getenv("CONTEXT_TIMEOUT")
 	timeout, err := strconv.Atoi(timeoutStr)
@@ -37,3 +41,25 @@ func Setup() *echo.Echo {
 
 	return e
 }
+
+// ipExtractor decides where c.RealIP() comes from. Without TRUSTED_PROXIES the address
+// of the connection is used and X-Forwarded-For is ignored, since any client can send
+// it. Behind a load balancer, TRUSTED_PROXIES lists its ranges as comma separated
+// CIDRs and X-Forwarded-For is only followed through those.
+func ipExtractor() echo.IPExtractor {
+	value := os.Getenv("TRUSTED_PROXIES")
+	if value == "" {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range strings.Split(value, ",") {
+		_, ipRange, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			log.Fatalf("Invalid TRUSTED_PROXIES range %q: %v", cidr, err)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
@@ -0,0 +1,162 @@
+package jwtkey
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"santapan/pkg/appenv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultKeyID = "default"
+	// developmentSecret keeps local environments working when no key is configured,
+	// it is only used with APP_ENV=development
+	developmentSecret = "SANTAPANSECRET"
+)
+
+// LoadFromEnv builds a KeySet from the environment:
+//
+//	JWT_ACTIVE_KID        kid used to sign new tokens (default "default")
+//	JWT_SECRET            HS256 secret for the active kid
+//	JWT_PRIVATE_KEY_FILE  PEM encoded RSA or EC private key for the active kid (RS256/ES256)
+//	JWT_KEYS_DIR          extra keys accepted for verification while rotating, one per file:
+//	                      <kid>.pem holds a private or public key, <kid>.secret an HMAC secret
+//
+// Without any key the server refuses to start, unless APP_ENV=development.
+func LoadFromEnv() (*KeySet, error) {
+	activeID := os.Getenv("JWT_ACTIVE_KID")
+	if activeID == "" {
+		activeID = defaultKeyID
+	}
+
+	keys := make(map[string]Key)
+	order := make([]string, 0)
+	add := func(key Key) {
+		if _, exists := keys[key.ID]; !exists {
+			order = append(order, key.ID)
+		}
+		keys[key.ID] = key
+	}
+
+	if dir := os.Getenv("JWT_KEYS_DIR"); dir != "" {
+		dirKeys, err := loadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range dirKeys {
+			add(key)
+		}
+	}
+
+	if path := os.Getenv("JWT_PRIVATE_KEY_FILE"); path != "" {
+		key, err := loadPEMFile(activeID, path)
+		if err != nil {
+			return nil, err
+		}
+		add(key)
+	} else if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		add(NewHMACKey(activeID, []byte(secret)))
+	}
+
+	if len(keys) == 0 {
+		if !appenv.Development() {
+			return nil, fmt.Errorf("no JWT signing key configured, set JWT_SECRET or JWT_PRIVATE_KEY_FILE")
+		}
+		logrus.Warn("No JWT signing key configured, falling back to the development secret")
+		add(NewHMACKey(activeID, []byte(developmentSecret)))
+	}
+
+	list := make([]Key, 0, len(order))
+	for _, id := range order {
+		list = append(list, keys[id])
+	}
+
+	return NewKeySet(activeID, list...)
+}
+
+func loadDir(dir string) ([]Key, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT keys directory: %w", err)
+	}
+
+	keys := make([]Key, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+		kid := strings.TrimSuffix(name, filepath.Ext(name))
+
+		switch filepath.Ext(name) {
+		case ".pem":
+			key, err := loadPEMFile(kid, path)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		case ".secret":
+			secret, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JWT secret %s: %w", name, err)
+			}
+			keys = append(keys, NewHMACKey(kid, []byte(strings.TrimSpace(string(secret)))))
+		}
+	}
+
+	return keys, nil
+}
+
+func loadPEMFile(kid string, path string) (Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to read JWT key %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, fmt.Errorf("no PEM block found in %s", path)
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return Key{}, fmt.Errorf("failed to parse private key %s: %w", path, err)
+		}
+		return NewPrivateKey(kid, private)
+	case "RSA PRIVATE KEY":
+		private, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return Key{}, fmt.Errorf("failed to parse private key %s: %w", path, err)
+		}
+		return NewPrivateKey(kid, private)
+	case "EC PRIVATE KEY":
+		private, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return Key{}, fmt.Errorf("failed to parse private key %s: %w", path, err)
+		}
+		return NewPrivateKey(kid, private)
+	case "PUBLIC KEY":
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return Key{}, fmt.Errorf("failed to parse public key %s: %w", path, err)
+		}
+		return NewPublicKey(kid, public)
+	case "RSA PUBLIC KEY":
+		public, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return Key{}, fmt.Errorf("failed to parse public key %s: %w", path, err)
+		}
+		return NewPublicKey(kid, public)
+	default:
+		return Key{}, fmt.Errorf("unsupported PEM block %q in %s", block.Type, path)
+	}
+}
@@ -0,0 +1,117 @@
+package jwtkey
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// JWK is the public part of a key as described in RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS returns the public keys of the provider. HMAC secrets are never published.
+func PublicJWKS(p Provider) JWKS {
+	set := JWKS{Keys: make([]JWK, 0)}
+
+	for _, key := range p.Keys() {
+		jwk := JWK{
+			Kid: key.ID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+		}
+
+		switch public := key.Verifying.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = encodeBigInt(public.N)
+			jwk.E = encodeBigInt(big.NewInt(int64(public.E)))
+		case *ecdsa.PublicKey:
+			size := (public.Curve.Params().BitSize + 7) / 8
+			jwk.Kty = "EC"
+			jwk.Crv = public.Curve.Params().Name
+			jwk.X = base64.RawURLEncoding.EncodeToString(public.X.FillBytes(make([]byte, size)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(public.Y.FillBytes(make([]byte, size)))
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func encodeBigInt(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
+
+// Key converts a published JWK into a verification-only key
+func (j JWK) Key() (Key, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return Key{}, fmt.Errorf("invalid modulus of key %q: %w", j.Kid, err)
+		}
+		e, err := decodeBigInt(j.E)
+		if err != nil || !e.IsInt64() {
+			return Key{}, fmt.Errorf("invalid exponent of key %q", j.Kid)
+		}
+		return NewPublicKey(j.Kid, &rsa.PublicKey{N: n, E: int(e.Int64())})
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return Key{}, fmt.Errorf("unsupported curve %q of key %q", j.Crv, j.Kid)
+		}
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return Key{}, fmt.Errorf("invalid x coordinate of key %q: %w", j.Kid, err)
+		}
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return Key{}, fmt.Errorf("invalid y coordinate of key %q: %w", j.Kid, err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return Key{}, fmt.Errorf("point of key %q is not on curve %s", j.Kid, j.Crv)
+		}
+		return NewPublicKey(j.Kid, &ecdsa.PublicKey{Curve: curve, X: x, Y: y})
+	default:
+		return Key{}, fmt.Errorf("unsupported key type %q of key %q", j.Kty, j.Kid)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,204 @@
+package jwtkey
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrUnknownKey is returned when a token references a kid that is not (or no longer) trusted
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrNoSigningKey is returned when the provider has no active signing key
+	ErrNoSigningKey = errors.New("no active signing key")
+)
+
+// Key is a JWT key identified by its kid. Signing is nil for keys that may only verify.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	Signing   interface{} // []byte, *rsa.PrivateKey or *ecdsa.PrivateKey
+	Verifying interface{} // []byte, *rsa.PublicKey or *ecdsa.PublicKey
+}
+
+// Symmetric reports whether the key is a shared HMAC secret that must never be published
+func (k Key) Symmetric() bool {
+	_, ok := k.Method.(*jwt.SigningMethodHMAC)
+	return ok
+}
+
+// Provider supplies the active signing key and every key still accepted for verification
+type Provider interface {
+	SigningKey() (Key, error)
+	VerificationKey(kid string) (Key, error)
+	Keys() []Key
+}
+
+// KeySet is an in-memory Provider. Keys other than the active one are kept for
+// verification only, which gives an overlapping window while rotating keys.
+type KeySet struct {
+	activeID string
+	keys     map[string]Key
+	order    []string
+}
+
+// NewKeySet creates a KeySet signing with the key identified by activeID
+func NewKeySet(activeID string, keys ...Key) (*KeySet, error) {
+	ks := &KeySet{
+		activeID: activeID,
+		keys:     make(map[string]Key),
+	}
+
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("key without kid")
+		}
+		if _, exists := ks.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate kid %q", key.ID)
+		}
+		ks.keys[key.ID] = key
+		ks.order = append(ks.order, key.ID)
+	}
+
+	active, ok := ks.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active kid %q is not in the key set", activeID)
+	}
+	if active.Signing == nil {
+		return nil, fmt.Errorf("active kid %q has no private key", activeID)
+	}
+
+	return ks, nil
+}
+
+// SigningKey returns the key new tokens are signed with
+func (ks *KeySet) SigningKey() (Key, error) {
+	key, ok := ks.keys[ks.activeID]
+	if !ok {
+		return Key{}, ErrNoSigningKey
+	}
+	return key, nil
+}
+
+// VerificationKey returns the key for the given kid. Tokens without a kid were
+// issued before key IDs existed and are checked against the active key.
+func (ks *KeySet) VerificationKey(kid string) (Key, error) {
+	if kid == "" {
+		kid = ks.activeID
+	}
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return Key{}, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// Keys returns every key in the set, active key included
+func (ks *KeySet) Keys() []Key {
+	keys := make([]Key, 0, len(ks.order))
+	for _, id := range ks.order {
+		keys = append(keys, ks.keys[id])
+	}
+	return keys
+}
+
+// NewHMACKey creates an HS256 key from a shared secret
+func NewHMACKey(id string, secret []byte) Key {
+	return Key{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		Signing:   secret,
+		Verifying: secret,
+	}
+}
+
+// NewPrivateKey creates an RS256 or ES256/ES384/ES512 key from a private key
+func NewPrivateKey(id string, private interface{}) (Key, error) {
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		return Key{ID: id, Method: jwt.SigningMethodRS256, Signing: k, Verifying: &k.PublicKey}, nil
+	case *ecdsa.PrivateKey:
+		method, err := ecdsaMethod(k.Curve)
+		if err != nil {
+			return Key{}, err
+		}
+		return Key{ID: id, Method: method, Signing: k, Verifying: &k.PublicKey}, nil
+	default:
+		return Key{}, fmt.Errorf("unsupported private key type %T", private)
+	}
+}
+
+// NewPublicKey creates a verification-only key, e.g. for a retired key in a rotation window
+func NewPublicKey(id string, public interface{}) (Key, error) {
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		return Key{ID: id, Method: jwt.SigningMethodRS256, Verifying: k}, nil
+	case *ecdsa.PublicKey:
+		method, err := ecdsaMethod(k.Curve)
+		if err != nil {
+			return Key{}, err
+		}
+		return Key{ID: id, Method: method, Verifying: k}, nil
+	default:
+		return Key{}, fmt.Errorf("unsupported public key type %T", public)
+	}
+}
+
+func ecdsaMethod(curve elliptic.Curve) (jwt.SigningMethod, error) {
+	switch curve {
+	case elliptic.P256():
+		return jwt.SigningMethodES256, nil
+	case elliptic.P384():
+		return jwt.SigningMethodES384, nil
+	case elliptic.P521():
+		return jwt.SigningMethodES512, nil
+	default:
+		return nil, fmt.Errorf("unsupported elliptic curve %s", curve.Params().Name)
+	}
+}
+
+// Sign signs the claims with the active key and sets the kid header
+func Sign(p Provider, claims jwt.Claims) (string, error) {
+	key, err := p.SigningKey()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.Signing)
+}
+
+// Parse verifies the token against the key named by its kid header and returns its claims
+func Parse(p Provider, tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		// The algorithm is pinned by the key, never chosen by the token
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.Verifying, nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid or expired token: %w", err)
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+
+	return claims, nil
+}
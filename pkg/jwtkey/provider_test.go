@@ -0,0 +1,168 @@
+package jwtkey
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func mustRSAKey(t *testing.T, id string) Key {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := NewPrivateKey(id, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func mustECKey(t *testing.T, id string) Key {
+	t.Helper()
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := NewPrivateKey(id, private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func claims(expiresIn time.Duration) jwt.MapClaims {
+	return jwt.MapClaims{"sub": float64(7), "typ": "access", "exp": time.Now().Add(expiresIn).Unix()}
+}
+
+// signWith signs the claims with the given method and key, setting kid when it is not empty
+func signWith(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, c jwt.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, c)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestParse(t *testing.T) {
+	hmacKey := NewHMACKey("hs", []byte("secret"))
+	rsaKey := mustRSAKey(t, "rs")
+	ecKey := mustECKey(t, "es")
+	rsaSet, err := NewKeySet("rs", rsaKey, hmacKey, ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hmacSet, err := NewKeySet("hs", hmacKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		set     *KeySet
+		token   string
+		wantErr bool
+	}{
+		{"active RS256 key", rsaSet, signWith(t, rsaKey.Method, rsaKey.Signing, "rs", claims(time.Minute)), false},
+		{"HS256 key still in the set", rsaSet, signWith(t, hmacKey.Method, hmacKey.Signing, "hs", claims(time.Minute)), false},
+		{"ES256 key still in the set", rsaSet, signWith(t, ecKey.Method, ecKey.Signing, "es", claims(time.Minute)), false},
+		{"no kid falls back to the active key", hmacSet, signWith(t, jwt.SigningMethodHS256, []byte("secret"), "", claims(time.Minute)), false},
+		{"unknown kid", rsaSet, signWith(t, rsaKey.Method, rsaKey.Signing, "gone", claims(time.Minute)), true},
+		{"expired", rsaSet, signWith(t, rsaKey.Method, rsaKey.Signing, "rs", claims(-time.Minute)), true},
+		{"wrong secret", hmacSet, signWith(t, jwt.SigningMethodHS256, []byte("guess"), "hs", claims(time.Minute)), true},
+		{"HS512 instead of the pinned HS256", hmacSet, signWith(t, jwt.SigningMethodHS512, []byte("secret"), "hs", claims(time.Minute)), true},
+		{"HS256 with another kid's algorithm", rsaSet, signWith(t, jwt.SigningMethodHS256, []byte("secret"), "rs", claims(time.Minute)), true},
+		{"alg none", hmacSet, signWith(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "hs", claims(time.Minute)), true},
+		{"garbage", hmacSet, "not.a.token", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.set, tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got claims %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v, want nil", err)
+			}
+			if got["sub"] != float64(7) {
+				t.Fatalf("got sub %v, want 7", got["sub"])
+			}
+		})
+	}
+}
+
+func TestParseTamperedPayload(t *testing.T) {
+	set, err := NewKeySet("hs", NewHMACKey("hs", []byte("secret")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := Sign(set, claims(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := Sign(set, jwt.MapClaims{"sub": float64(1), "typ": "access", "exp": time.Now().Add(time.Minute).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The payload of one token with the signature of another
+	parts, otherParts := strings.Split(token, "."), strings.Split(other, ".")
+	forged := parts[0] + "." + otherParts[1] + "." + parts[2]
+
+	if _, err = Parse(set, forged); err == nil {
+		t.Fatal("got nil, want an error for a tampered payload")
+	}
+}
+
+func TestSignSetsKid(t *testing.T) {
+	set, err := NewKeySet("rs", mustRSAKey(t, "rs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := Sign(set, claims(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, _, err := jwt.NewParser().ParseUnverified(signed, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Header["kid"] != "rs" || token.Header["alg"] != "RS256" {
+		t.Fatalf("got header %v, want kid rs and alg RS256", token.Header)
+	}
+}
+
+func TestLoadFromEnvFailsClosed(t *testing.T) {
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_PRIVATE_KEY_FILE", "")
+	t.Setenv("JWT_KEYS_DIR", "")
+
+	t.Setenv("APP_ENV", "production")
+	if _, err := LoadFromEnv(); err == nil {
+		t.Fatal("production without keys: got nil, want an error")
+	}
+
+	t.Setenv("APP_ENV", "development")
+	if _, err := LoadFromEnv(); err != nil {
+		t.Fatalf("development without keys: got %v, want nil", err)
+	}
+}
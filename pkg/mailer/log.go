@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogMailer is a local development stand-in that logs emails instead of sending them
+type LogMailer struct {
+	dir string
+}
+
+// NewLogMailer creates a mailer that logs messages and, when dir is not empty, writes each one to a file
+func NewLogMailer(dir string) *LogMailer {
+	return &LogMailer{dir: dir}
+}
+
+// Send logs the message and writes it to the mail directory
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	logrus.WithFields(logrus.Fields{
+		"to":      msg.To,
+		"subject": msg.Subject,
+	}).Info(msg.Body)
+
+	if m.dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), filepath.Base(msg.To))
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", msg.To, msg.Subject, msg.Body)
+	if err := os.WriteFile(filepath.Join(m.dir, name), []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"santapan/pkg/appenv"
+	"strconv"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewFromEnv creates the mailer selected by MAIL_DRIVER: "smtp" delivers through
+// SMTP_HOST/SMTP_PORT, anything else logs messages (and writes them to MAIL_LOG_DIR when set).
+// Logged messages carry working sign-in links, so outside APP_ENV=development only a
+// fully configured SMTP mailer is accepted.
+func NewFromEnv() (Mailer, error) {
+	if os.Getenv("MAIL_DRIVER") != "smtp" {
+		if !appenv.Development() {
+			return nil, errors.New("MAIL_DRIVER must be smtp outside development")
+		}
+		return NewLogMailer(os.Getenv("MAIL_LOG_DIR")), nil
+	}
+
+	host, from := os.Getenv("SMTP_HOST"), os.Getenv("MAIL_FROM")
+	if host == "" || from == "" {
+		return nil, errors.New("SMTP_HOST and MAIL_FROM are not configured")
+	}
+
+	port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if err != nil {
+		port = defaultSMTPPort
+	}
+
+	return NewSMTPMailer(SMTPConfig{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}), nil
+}
@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+const defaultSMTPPort = 587
+
+// SMTPConfig holds the connection settings of an SMTP relay
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPMailer delivers emails through an SMTP relay
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+// NewSMTPMailer creates a mailer for the given SMTP relay
+func NewSMTPMailer(config SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{config: config}
+}
+
+// Send delivers the message, authenticating when a username is configured
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", m.config.Host, m.config.Port)
+	if err := smtp.SendMail(addr, auth, m.config.From, []string{msg.To}, m.build(msg)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+func (m *SMTPMailer) build(msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + m.config.From + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
@@ -0,0 +1,68 @@
+package oidc
+
+import (
+	"os"
+	"strings"
+)
+
+// defaults holds the well-known issuer and JWKS URL of the supported providers
+var defaults = map[string]Config{
+	"google": {
+		Name:    "google",
+		Issuer:  "https://accounts.google.com",
+		JWKSURL: "https://www.googleapis.com/oauth2/v3/certs",
+	},
+	"apple": {
+		Name:    "apple",
+		Issuer:  "https://appleid.apple.com",
+		JWKSURL: "https://appleid.apple.com/auth/keys",
+	},
+}
+
+// LoadFromEnv builds the configured providers from the environment:
+//
+//	OIDC_PROVIDERS             comma separated provider names (default "google,apple")
+//	OIDC_<NAME>_CLIENT_IDS     comma separated client IDs accepted as audience, required to enable the provider
+//	OIDC_<NAME>_ISSUER         expected issuer, defaults to the well-known issuer of google and apple
+//	OIDC_<NAME>_JWKS_URL       JWKS URL, defaults to the well-known URL of google and apple
+//
+// Pointing ISSUER and JWKS_URL at a Stub allows signing in without a real provider.
+func LoadFromEnv() Providers {
+	names := os.Getenv("OIDC_PROVIDERS")
+	if names == "" {
+		names = "google,apple"
+	}
+
+	providers := make(Providers)
+	for _, name := range splitList(names) {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		config := defaults[name]
+		config.Name = name
+		config.Audience = splitList(os.Getenv(prefix + "CLIENT_IDS"))
+		if issuer := os.Getenv(prefix + "ISSUER"); issuer != "" {
+			config.Issuer = issuer
+		}
+		if url := os.Getenv(prefix + "JWKS_URL"); url != "" {
+			config.JWKSURL = url
+		}
+
+		if len(config.Audience) == 0 || config.Issuer == "" || config.JWKSURL == "" {
+			continue
+		}
+		providers[name] = NewVerifier(config)
+	}
+
+	return providers
+}
+
+func splitList(s string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,134 @@
+package oidc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"santapan/pkg/jwtkey"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// keysTTL is how long fetched keys are trusted before the JWKS is fetched again
+	keysTTL = time.Hour
+	// refetchInterval limits how often an unknown kid may trigger a fetch
+	refetchInterval = time.Minute
+)
+
+// RemoteKeySet is a verification-only jwtkey.Provider backed by the JWKS URL of an
+// identity provider. Keys are cached and fetched again once stale or when a token
+// names a kid that is not known yet, which is how providers rotate keys.
+type RemoteKeySet struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]jwtkey.Key
+	order     []string
+	fetchedAt time.Time
+}
+
+// NewRemoteKeySet creates a key set for the given JWKS URL. Nothing is fetched until
+// the first token is verified.
+func NewRemoteKeySet(url string) *RemoteKeySet {
+	return &RemoteKeySet{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		keys:   make(map[string]jwtkey.Key),
+	}
+}
+
+// SigningKey always fails, keys of an identity provider can only verify
+func (r *RemoteKeySet) SigningKey() (jwtkey.Key, error) {
+	return jwtkey.Key{}, jwtkey.ErrNoSigningKey
+}
+
+// VerificationKey returns the key for the given kid, fetching the JWKS when needed.
+// A token without a kid is only accepted when the provider publishes a single key.
+func (r *RemoteKeySet) VerificationKey(kid string) (jwtkey.Key, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stale := time.Since(r.fetchedAt) > keysTTL
+	_, known := r.keys[kid]
+	if stale || (!known && kid != "" && time.Since(r.fetchedAt) > refetchInterval) {
+		if err := r.fetch(); err != nil {
+			// Keep verifying with the cached keys while the provider is unreachable
+			if len(r.keys) == 0 {
+				return jwtkey.Key{}, err
+			}
+			logrus.Error(err)
+		}
+	}
+
+	if kid == "" {
+		if len(r.order) != 1 {
+			return jwtkey.Key{}, jwtkey.ErrUnknownKey
+		}
+		kid = r.order[0]
+	}
+
+	key, ok := r.keys[kid]
+	if !ok {
+		return jwtkey.Key{}, jwtkey.ErrUnknownKey
+	}
+	return key, nil
+}
+
+// Keys returns the cached keys
+func (r *RemoteKeySet) Keys() []jwtkey.Key {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]jwtkey.Key, 0, len(r.order))
+	for _, id := range r.order {
+		keys = append(keys, r.keys[id])
+	}
+	return keys
+}
+
+// fetch replaces the cached keys with the ones currently published. Keys of an
+// unsupported type are skipped. The caller holds the lock.
+func (r *RemoteKeySet) fetch() error {
+	// Failed fetches count too so an unreachable provider is not hammered
+	r.fetchedAt = time.Now()
+
+	resp, err := r.client.Get(r.url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS %s: %w", r.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS %s: status %d", r.url, resp.StatusCode)
+	}
+
+	var set jwtkey.JWKS
+	if err = json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("failed to decode JWKS %s: %w", r.url, err)
+	}
+
+	keys := make(map[string]jwtkey.Key)
+	order := make([]string, 0, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.Key()
+		if err != nil {
+			logrus.Warnf("Skipping key from %s: %v", r.url, err)
+			continue
+		}
+		if _, exists := keys[key.ID]; !exists {
+			order = append(order, key.ID)
+		}
+		keys[key.ID] = key
+	}
+
+	r.keys = keys
+	r.order = order
+	return nil
+}
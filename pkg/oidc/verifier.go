@@ -0,0 +1,115 @@
+package oidc
+
+import (
+	"errors"
+	"fmt"
+	"santapan/pkg/jwtkey"
+	"strings"
+)
+
+var (
+	// ErrUnknownProvider is returned for a provider that is not configured
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	// ErrInvalidIDToken is returned for an ID token that fails verification
+	ErrInvalidIDToken = errors.New("invalid id token")
+)
+
+// Config describes an OpenID Connect identity provider
+type Config struct {
+	Name     string
+	Issuer   string
+	Audience []string // client IDs of our apps, the token must be issued to one of them
+	JWKSURL  string
+}
+
+// Claims are the identity claims of a verified ID token
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// Verifier checks ID tokens issued by a single provider
+type Verifier struct {
+	config Config
+	keys   jwtkey.Provider
+}
+
+// NewVerifier creates a verifier fetching the signing keys from the JWKS URL of the provider
+func NewVerifier(config Config) *Verifier {
+	return &Verifier{
+		config: config,
+		keys:   NewRemoteKeySet(config.JWKSURL),
+	}
+}
+
+// Verify checks the signature, expiry, issuer and audience of the ID token and returns its claims
+func (v *Verifier) Verify(idToken string) (Claims, error) {
+	claims, err := jwtkey.Parse(v.keys, idToken)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if issuer, _ := claims.GetIssuer(); issuer != v.config.Issuer {
+		return Claims{}, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, issuer)
+	}
+
+	audience, _ := claims.GetAudience()
+	if !intersects(audience, v.config.Audience) {
+		return Claims{}, fmt.Errorf("%w: unexpected audience %v", ErrInvalidIDToken, audience)
+	}
+
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return Claims{}, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	email, _ := claims["email"].(string)
+	name, _ := claims["name"].(string)
+	picture, _ := claims["picture"].(string)
+
+	return Claims{
+		Subject:       subject,
+		Email:         strings.ToLower(strings.TrimSpace(email)),
+		EmailVerified: boolClaim(claims["email_verified"]),
+		Name:          name,
+		Picture:       picture,
+	}, nil
+}
+
+// Providers verifies ID tokens of every configured provider, keyed by provider name
+type Providers map[string]*Verifier
+
+// Verify checks the ID token with the verifier of the named provider
+func (p Providers) Verify(provider string, idToken string) (Claims, error) {
+	verifier, ok := p[provider]
+	if !ok {
+		return Claims{}, ErrUnknownProvider
+	}
+	return verifier.Verify(idToken)
+}
+
+func intersects(a []string, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// boolClaim reads a boolean claim, Apple sends email_verified as the string "true"
+func boolClaim(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,41 @@
+package otpsender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"santapan/pkg/appenv"
+)
+
+// ErrNotConfigured is returned by NewFromEnv outside development when OTP_DRIVER is not
+// set, phone verification and OTP login are then switched off
+var ErrNotConfigured = errors.New("OTP_DRIVER is not configured")
+
+// Sender delivers a message to a phone number
+type Sender interface {
+	Send(ctx context.Context, phone string, message string) error
+}
+
+// NewFromEnv creates the sender for the given channel selected by OTP_DRIVER. Only "log"
+// exists so far and only with APP_ENV=development, where it is also the default, since
+// it writes working codes to the logs.
+func NewFromEnv(channel string) (Sender, error) {
+	driver := os.Getenv("OTP_DRIVER")
+	if driver == "" {
+		if !appenv.Development() {
+			return nil, ErrNotConfigured
+		}
+		driver = "log"
+	}
+
+	switch driver {
+	case "log":
+		if !appenv.Development() {
+			return nil, errors.New("the log OTP sender is only available with APP_ENV=development")
+		}
+		return NewLogSender(channel), nil
+	default:
+		return nil, fmt.Errorf("unknown OTP driver %q", driver)
+	}
+}
@@ -0,0 +1,26 @@
+package otpsender
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogSender is a local development stand-in that logs OTP messages instead of delivering them
+type LogSender struct {
+	channel string
+}
+
+// NewLogSender creates a stand-in sender for the given channel, e.g. "sms" or "whatsapp"
+func NewLogSender(channel string) *LogSender {
+	return &LogSender{channel: channel}
+}
+
+// Send logs the message
+func (s *LogSender) Send(ctx context.Context, phone string, message string) error {
+	logrus.WithFields(logrus.Fields{
+		"channel": s.channel,
+		"phone":   phone,
+	}).Info(message)
+	return nil
+}
@@ -0,0 +1,100 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id hashes passwords with argon2id and encodes them in the PHC string format:
+// $argon2id$v=19$m=<memory KiB>,t=<time>,p=<threads>$<salt>$<key>
+type Argon2id struct {
+	Time    uint32
+	Memory  uint32 // KiB
+	Threads uint8
+	KeyLen  uint32
+	SaltLen uint32
+}
+
+// NewArgon2id creates an argon2id hasher, zero values take the RFC 9106 second recommended option
+func NewArgon2id(time uint32, memory uint32, threads uint8) *Argon2id {
+	a := &Argon2id{Time: time, Memory: memory, Threads: threads, KeyLen: 32, SaltLen: 16}
+	if a.Time == 0 {
+		a.Time = 3
+	}
+	if a.Memory == 0 {
+		a.Memory = 64 * 1024
+	}
+	if a.Threads == 0 {
+		a.Threads = 4
+	}
+	return a
+}
+
+var argonEncoding = base64.RawStdEncoding
+
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.Time, a.Memory, a.Threads, a.KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, a.Memory, a.Time, a.Threads,
+		argonEncoding.EncodeToString(salt), argonEncoding.EncodeToString(key)), nil
+}
+
+func (a *Argon2id) Verify(hash string, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (a *Argon2id) NeedsRehash(hash string) bool {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return true
+	}
+
+	return params.Time != a.Time || params.Memory != a.Memory || params.Threads != a.Threads ||
+		uint32(len(key)) != a.KeyLen || uint32(len(salt)) != a.SaltLen
+}
+
+func (a *Argon2id) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+func decodeArgon2id(hash string) (params Argon2id, salt []byte, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Time, &params.Threads); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 parameters: %w", err)
+	}
+
+	if salt, err = argonEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 salt: %w", err)
+	}
+	if key, err = argonEncoding.DecodeString(parts[5]); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 key: %w", err)
+	}
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,53 @@
+package password
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultBcryptCost matches the cost of the seeded accounts
+const defaultBcryptCost = 12
+
+// NewFromEnv builds the hasher from the environment:
+//
+//	PASSWORD_HASHER  algorithm for new hashes, "bcrypt" (default) or "argon2id"
+//	BCRYPT_COST      bcrypt cost (default 12)
+//	ARGON2_TIME      argon2id iterations (default 3)
+//	ARGON2_MEMORY    argon2id memory in KiB (default 65536)
+//	ARGON2_THREADS   argon2id parallelism (default 4)
+//
+// Hashes of the other algorithm keep verifying and are replaced on the next login.
+func NewFromEnv() *MultiHasher {
+	bcryptHasher := NewBcrypt(envInt("BCRYPT_COST", defaultBcryptCost))
+	argonHasher := NewArgon2id(
+		uint32(envInt("ARGON2_TIME", 0)),
+		uint32(envInt("ARGON2_MEMORY", 0)),
+		uint8(envInt("ARGON2_THREADS", 0)),
+	)
+
+	switch os.Getenv("PASSWORD_HASHER") {
+	case "", "bcrypt":
+		return NewMultiHasher(bcryptHasher, argonHasher)
+	case "argon2id":
+		return NewMultiHasher(argonHasher, bcryptHasher)
+	default:
+		logrus.Warnf("Unknown PASSWORD_HASHER %q, using bcrypt", os.Getenv("PASSWORD_HASHER"))
+		return NewMultiHasher(bcryptHasher, argonHasher)
+	}
+}
+
+func envInt(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		logrus.Warnf("Invalid %s %q, using %d", name, value, fallback)
+		return fallback
+	}
+	return n
+}
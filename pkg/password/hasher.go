@@ -0,0 +1,68 @@
+package password
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnknownHash is returned for a stored hash no configured algorithm understands
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// Hasher hashes passwords and checks them against stored hashes
+type Hasher interface {
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches the hash
+	Verify(hash string, password string) (bool, error)
+	// NeedsRehash reports whether the hash was made with another algorithm or other parameters
+	NeedsRehash(hash string) bool
+}
+
+// Algorithm is a single hashing scheme recognised by the prefix of its hashes
+type Algorithm interface {
+	Hasher
+	Recognizes(hash string) bool
+}
+
+// MultiHasher hashes with the current algorithm and still verifies hashes of
+// the others, so stored hashes can be upgraded on the next successful login
+type MultiHasher struct {
+	current Algorithm
+	known   []Algorithm
+}
+
+// NewMultiHasher creates a hasher hashing with current and verifying with current and legacy
+func NewMultiHasher(current Algorithm, legacy ...Algorithm) *MultiHasher {
+	return &MultiHasher{
+		current: current,
+		known:   append([]Algorithm{current}, legacy...),
+	}
+}
+
+func (m *MultiHasher) Hash(password string) (string, error) {
+	return m.current.Hash(password)
+}
+
+func (m *MultiHasher) Verify(hash string, password string) (bool, error) {
+	for _, algorithm := range m.known {
+		if algorithm.Recognizes(hash) {
+			return algorithm.Verify(hash, password)
+		}
+	}
+	return false, ErrUnknownHash
+}
+
+func (m *MultiHasher) NeedsRehash(hash string) bool {
+	if !m.current.Recognizes(hash) {
+		return true
+	}
+	return m.current.NeedsRehash(hash)
+}
+
+func hasAnyPrefix(s string, prefixes ...string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package password
+
+import (
+	_ "embed"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MinLength is the minimum number of characters of a password
+	MinLength = 8
+	// MaxLength is the maximum number of bytes of a password, bcrypt ignores anything past 72 bytes
+	MaxLength = 72
+)
+
+var (
+	ErrTooShort = errors.New("password must be at least 8 characters")
+	ErrTooLong  = errors.New("password must be at most 72 bytes")
+	ErrCommon   = errors.New("password is too common, please choose another one")
+)
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+var commonPasswords = parseList(commonPasswordList)
+
+// CheckPolicy returns the first password policy rule the password breaks
+func CheckPolicy(password string) error {
+	if utf8.RuneCountInString(password) < MinLength {
+		return ErrTooShort
+	}
+	if len(password) > MaxLength {
+		return ErrTooLong
+	}
+	if _, common := commonPasswords[strings.ToLower(password)]; common {
+		return ErrCommon
+	}
+	return nil
+}
+
+func parseList(list string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,38 @@
+package paymentgateway
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"santapan/pkg/appenv"
+)
+
+// ErrNotConfigured is returned by NewFromEnv when PAYMENT_PROVIDER is not set, the
+// API then runs without checkout and payments
+var ErrNotConfigured = errors.New("PAYMENT_PROVIDER is not configured")
+
+// NewFromEnv creates the provider selected by PAYMENT_PROVIDER. Only "fake" exists so
+// far and only with APP_ENV=development, since its hosted pages under
+// <appURL>/payments/fake let anyone mark a payment paid. Webhooks, signed with
+// PAYMENT_WEBHOOK_SECRET, go to <appURL>/payments/webhook.
+func NewFromEnv(appURL string) (Provider, error) {
+	name := os.Getenv("PAYMENT_PROVIDER")
+	if name == "" {
+		return nil, ErrNotConfigured
+	}
+
+	secret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if secret == "" {
+		return nil, errors.New("PAYMENT_WEBHOOK_SECRET is not configured")
+	}
+
+	switch name {
+	case "fake":
+		if !appenv.Development() {
+			return nil, errors.New("the fake payment provider is only available with APP_ENV=development")
+		}
+		return NewFakeProvider(appURL+"/payments/fake", appURL+"/payments/webhook", []byte(secret)), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", name)
+	}
+}
@@ -0,0 +1,225 @@
+package paymentgateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// FakeSignatureHeader carries the webhook signature of the fake provider
+	FakeSignatureHeader = "X-Fake-Signature"
+	fakeSessionTTL      = time.Hour
+)
+
+// FakeProvider is a local stand-in for a payment gateway. It keeps sessions in
+// memory, serves a hosted page where the payment can be approved or declined and
+// delivers signed webhooks like a real gateway, so checkout can be exercised end to end.
+type FakeProvider struct {
+	baseURL    string
+	webhookURL string
+	secret     []byte
+	client     *http.Client
+
+	mu       sync.Mutex
+	sessions map[string]*fakeSession
+}
+
+type fakeSession struct {
+	Reference string
+	Amount    int64
+	Method    string
+	ReturnURL string
+	Status    Status
+	ExpiresAt time.Time
+}
+
+type fakeWebhook struct {
+	SessionID string `json:"session_id"`
+	Reference string `json:"reference"`
+	Status    Status `json:"status"`
+}
+
+// NewFakeProvider creates a fake gateway whose hosted pages live under baseURL and
+// whose webhooks are signed with secret and posted to webhookURL
+func NewFakeProvider(baseURL string, webhookURL string, secret []byte) *FakeProvider {
+	return &FakeProvider{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		webhookURL: webhookURL,
+		secret:     secret,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		sessions:   make(map[string]*fakeSession),
+	}
+}
+
+func (f *FakeProvider) Name() string {
+	return "fake"
+}
+
+// CreateSession registers a pending session and returns the URL of its hosted page
+func (f *FakeProvider) CreateSession(ctx context.Context, req SessionRequest) (Session, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return Session{}, fmt.Errorf("failed to generate session id: %w", err)
+	}
+	id := "fake_" + hex.EncodeToString(b)
+	expiresAt := time.Now().Add(fakeSessionTTL)
+
+	f.mu.Lock()
+	f.sessions[id] = &fakeSession{
+		Reference: req.Reference,
+		Amount:    req.Amount,
+		Method:    req.Method,
+		ReturnURL: req.ReturnURL,
+		Status:    StatusPending,
+		ExpiresAt: expiresAt,
+	}
+	f.mu.Unlock()
+
+	return Session{ID: id, URL: f.baseURL + "/" + id, ExpiresAt: expiresAt}, nil
+}
+
+// ParseWebhook verifies the signature header and decodes the event
+func (f *FakeProvider) ParseWebhook(header http.Header, body []byte) (Event, error) {
+	if err := VerifySignature(f.secret, header.Get(FakeSignatureHeader), body, time.Now()); err != nil {
+		return Event{}, err
+	}
+
+	var webhook fakeWebhook
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return Event{}, fmt.Errorf("failed to decode webhook: %w", err)
+	}
+
+	return Event{SessionID: webhook.SessionID, Reference: webhook.Reference, Status: webhook.Status}, nil
+}
+
+// GetStatus returns the state of the session, pending sessions expire after an hour
+func (f *FakeProvider) GetStatus(ctx context.Context, sessionID string) (Status, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	session, ok := f.sessions[sessionID]
+	if !ok {
+		return "", ErrSessionNotFound
+	}
+	if session.Status == StatusPending && time.Now().After(session.ExpiresAt) {
+		session.Status = StatusExpired
+	}
+
+	return session.Status, nil
+}
+
+// Complete settles a pending session with the given status and posts the signed webhook
+func (f *FakeProvider) Complete(ctx context.Context, sessionID string, status Status) error {
+	f.mu.Lock()
+	session, ok := f.sessions[sessionID]
+	if !ok {
+		f.mu.Unlock()
+		return ErrSessionNotFound
+	}
+	if session.Status != StatusPending {
+		f.mu.Unlock()
+		return fmt.Errorf("session %s is already %s", sessionID, session.Status)
+	}
+	session.Status = status
+	reference := session.Reference
+	f.mu.Unlock()
+
+	body, err := json.Marshal(fakeWebhook{SessionID: sessionID, Reference: reference, Status: status})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(FakeSignatureHeader, Sign(f.secret, body, time.Now()))
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		// Like a real gateway the status stays settled, reconciliation picks it up
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook rejected with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+var fakePage = template.Must(template.New("fake").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Fake payment</title></head>
+<body>
+<h1>Fake payment</h1>
+<p>Reference {{.Reference}}, method {{.Method}}</p>
+<p>Amount Rp {{.Amount}}</p>
+<p>Status {{.Status}}</p>
+{{if eq .Status "pending"}}
+<form method="post" action="{{.ID}}/pay"><button type="submit">Pay</button></form>
+<form method="post" action="{{.ID}}/fail"><button type="submit">Decline</button></form>
+{{end}}
+</body>
+</html>`))
+
+// ServeHTTP serves the hosted pages, mount it under the path of baseURL with
+// http.StripPrefix. GET /<id> shows the session, POST /<id>/pay and /<id>/fail settle it.
+func (f *FakeProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, action, _ := strings.Cut(strings.Trim(r.URL.Path, "/"), "/")
+
+	status, err := f.GetStatus(r.Context(), id)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && action == "":
+		f.mu.Lock()
+		session := *f.sessions[id]
+		f.mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fakePage.Execute(w, map[string]interface{}{
+			"ID":        id,
+			"Reference": session.Reference,
+			"Method":    session.Method,
+			"Amount":    session.Amount,
+			"Status":    status,
+		})
+	case r.Method == http.MethodPost && (action == "pay" || action == "fail"):
+		result := StatusPaid
+		if action == "fail" {
+			result = StatusFailed
+		}
+
+		if err := f.Complete(r.Context(), id, result); err != nil {
+			logrus.Error(err)
+		}
+
+		f.mu.Lock()
+		returnURL := f.sessions[id].ReturnURL
+		f.mu.Unlock()
+
+		if returnURL == "" {
+			returnURL = f.baseURL + "/" + id
+		}
+		http.Redirect(w, r, returnURL, http.StatusSeeOther)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
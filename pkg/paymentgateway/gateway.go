@@ -0,0 +1,62 @@
+package paymentgateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrInvalidSignature is returned for a webhook whose signature does not verify
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrSessionNotFound is returned when the provider does not know the session
+	ErrSessionNotFound = errors.New("payment session not found")
+)
+
+// Status is the provider independent state of a payment session
+type Status string
+
+const (
+	StatusPending Status = "pending" // Waiting for the customer to pay
+	StatusPaid    Status = "paid"    // The money was captured
+	StatusFailed  Status = "failed"  // The payment was declined or cancelled
+	StatusExpired Status = "expired" // The session ended before it was paid
+)
+
+// SessionRequest describes the hosted payment session to create
+type SessionRequest struct {
+	Reference     string // Our reference of the payment, echoed back by the provider
+	Amount        int64  // Amount in whole rupiah
+	Method        string // Preferred payment method, e.g. 'CC', 'BT', 'EW'
+	CustomerEmail string
+	ReturnURL     string // Where the customer lands after paying
+}
+
+// Session is a hosted payment page the customer is sent to
+type Session struct {
+	ID        string
+	URL       string
+	ExpiresAt time.Time
+}
+
+// Event is a verified status change reported by the provider
+type Event struct {
+	SessionID string
+	Reference string
+	Status    Status
+}
+
+// Provider is a payment gateway offering hosted checkout pages
+type Provider interface {
+	// Name identifies the provider in logs
+	Name() string
+	// CreateSession creates a hosted payment page for the request
+	CreateSession(ctx context.Context, req SessionRequest) (Session, error)
+	// ParseWebhook verifies the signature of a webhook callback and returns its event.
+	// Returns ErrInvalidSignature when the callback can not be trusted.
+	ParseWebhook(header http.Header, body []byte) (Event, error)
+	// GetStatus asks the provider for the current state of a session, used to
+	// reconcile payments whose webhook never arrived
+	GetStatus(ctx context.Context, sessionID string) (Status, error)
+}
@@ -0,0 +1,76 @@
+package paymentgateway
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testSecret = []byte("whsec_test")
+	testBody   = []byte(`{"session_id":"fake_1","reference":"INV-1","status":"paid"}`)
+)
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	valid := Sign(testSecret, testBody, now)
+	signature := valid[strings.Index(valid, "v1=")+3:]
+
+	tests := []struct {
+		name   string
+		secret []byte
+		header string
+		body   []byte
+		want   error
+	}{
+		{"valid", testSecret, valid, testBody, nil},
+		{"spaces after the comma", testSecret, strings.Replace(valid, ",", ", ", 1), testBody, nil},
+		{"fields in any order", testSecret, "v1=" + signature + ",t=1700000000", testBody, nil},
+		{"at the edge of the tolerance", testSecret, Sign(testSecret, testBody, now.Add(-SignatureTolerance)), testBody, nil},
+		{"older than the tolerance", testSecret, Sign(testSecret, testBody, now.Add(-SignatureTolerance-time.Second)), testBody, ErrInvalidSignature},
+		{"further ahead than the tolerance", testSecret, Sign(testSecret, testBody, now.Add(SignatureTolerance+time.Second)), testBody, ErrInvalidSignature},
+		{"timestamp swapped on a valid signature", testSecret, "t=1700000001,v1=" + signature, testBody, ErrInvalidSignature},
+		{"tampered body", testSecret, valid, []byte(`{"session_id":"fake_1","reference":"INV-1","status":"paid" }`), ErrInvalidSignature},
+		{"tampered status", testSecret, valid, []byte(`{"session_id":"fake_1","reference":"INV-2","status":"paid"}`), ErrInvalidSignature},
+		{"wrong secret", []byte("whsec_other"), valid, testBody, ErrInvalidSignature},
+		{"signature in upper case", testSecret, "t=1700000000,v1=" + strings.ToUpper(signature), testBody, ErrInvalidSignature},
+		{"missing timestamp", testSecret, "v1=" + signature, testBody, ErrInvalidSignature},
+		{"missing signature", testSecret, "t=1700000000", testBody, ErrInvalidSignature},
+		{"timestamp not a number", testSecret, "t=now,v1=" + signature, testBody, ErrInvalidSignature},
+		{"empty header", testSecret, "", testBody, ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := VerifySignature(tt.secret, tt.header, tt.body, now); err != tt.want {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFakeProviderParseWebhook(t *testing.T) {
+	f := NewFakeProvider("http://localhost/fake", "http://localhost/webhook", testSecret)
+
+	header := http.Header{}
+	header.Set(FakeSignatureHeader, Sign(testSecret, testBody, time.Now()))
+
+	event, err := f.ParseWebhook(header, testBody)
+	if err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+	want := Event{SessionID: "fake_1", Reference: "INV-1", Status: StatusPaid}
+	if event != want {
+		t.Fatalf("got %+v, want %+v", event, want)
+	}
+
+	if _, err := f.ParseWebhook(http.Header{}, testBody); err != ErrInvalidSignature {
+		t.Fatalf("unsigned: got %v, want ErrInvalidSignature", err)
+	}
+
+	header.Set(FakeSignatureHeader, Sign(testSecret, testBody, time.Now().Add(-time.Hour)))
+	if _, err := f.ParseWebhook(header, testBody); err != ErrInvalidSignature {
+		t.Fatalf("replayed: got %v, want ErrInvalidSignature", err)
+	}
+}
@@ -0,0 +1,25 @@
+package phone
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Normalize formats a national number and its country dialing code as E.164,
+// e.g. country code 62 and 81234567890 become "+6281234567890"
+func Normalize(countryCode int32, number int64) (string, error) {
+	if countryCode <= 0 || countryCode > 999 {
+		return "", fmt.Errorf("invalid country code %d", countryCode)
+	}
+	if number <= 0 {
+		return "", fmt.Errorf("invalid phone number")
+	}
+
+	digits := strconv.FormatInt(int64(countryCode), 10) + strconv.FormatInt(number, 10)
+	// E.164 numbers have at most 15 digits; anything under 8 is not a real subscriber number
+	if len(digits) < 8 || len(digits) > 15 {
+		return "", fmt.Errorf("invalid phone number length")
+	}
+
+	return "+" + digits, nil
+}
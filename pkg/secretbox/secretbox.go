@@ -0,0 +1,94 @@
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"santapan/pkg/appenv"
+
+	"github.com/sirupsen/logrus"
+)
+
+// developmentKey keeps local environments working when no key is configured, it is
+// only used with APP_ENV=development
+const developmentKey = "SANTAPANSECRETBOX"
+
+// Box encrypts small secrets stored at rest with AES-256-GCM
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New creates a Box from a 32 byte key
+func New(key []byte) (*Box, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secretbox key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	return &Box{aead: aead}, nil
+}
+
+// NewFromEnv creates a Box from the base64 encoded 32 byte key in SECRETBOX_KEY. A
+// missing key is an error unless APP_ENV=development.
+func NewFromEnv() (*Box, error) {
+	encoded := os.Getenv("SECRETBOX_KEY")
+	if encoded == "" {
+		if !appenv.Development() {
+			return nil, fmt.Errorf("no SECRETBOX_KEY configured")
+		}
+		logrus.Warn("No SECRETBOX_KEY configured, falling back to the development key")
+		key := sha256.Sum256([]byte(developmentKey))
+		return New(key[:])
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode SECRETBOX_KEY: %w", err)
+	}
+
+	return New(key)
+}
+
+// Seal encrypts the plaintext and returns it base64 encoded with its nonce
+func (b *Box) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal
+func (b *Box) Open(ciphertext string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode ciphertext: %w", err)
+	}
+
+	size := b.aead.NonceSize()
+	if len(sealed) < size {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+
+	plaintext, err := b.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: %w", err)
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,37 @@
+package policy
+
+import (
+	"context"
+	"santapan/domain"
+)
+
+// Owned is implemented by every resource that belongs to a single user
+type Owned interface {
+	OwnerID() int64
+}
+
+// Authorize checks that the resource belongs to the user. Resources of other
+// users are reported as domain.ErrNotFound so their existence is not leaked.
+func Authorize(userID int64, resource Owned) error {
+	if userID == 0 || resource.OwnerID() != userID {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Load fetches a resource and authorizes it for the user. User-owned resources
+// must be read through Load before being returned, updated or deleted.
+func Load[T Owned](ctx context.Context, userID int64, id int64, fetch func(ctx context.Context, id int64) (T, error)) (T, error) {
+	var zero T
+
+	resource, err := fetch(ctx, id)
+	if err != nil {
+		return zero, err
+	}
+
+	if err = Authorize(userID, resource); err != nil {
+		return zero, err
+	}
+
+	return resource, nil
+}
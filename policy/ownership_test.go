@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"santapan/domain"
+	"testing"
+)
+
+type note struct {
+	ID     int64
+	UserID int64
+}
+
+func (n note) OwnerID() int64 {
+	return n.UserID
+}
+
+func fetchNote(n note, err error) func(ctx context.Context, id int64) (note, error) {
+	return func(ctx context.Context, id int64) (note, error) {
+		return n, err
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	resource := note{ID: 1, UserID: 7}
+
+	if err := Authorize(7, resource); err != nil {
+		t.Fatalf("owner: got %v, want nil", err)
+	}
+	if err := Authorize(8, resource); err != domain.ErrNotFound {
+		t.Fatalf("other user: got %v, want domain.ErrNotFound", err)
+	}
+	if err := Authorize(0, note{ID: 1}); err != domain.ErrNotFound {
+		t.Fatalf("anonymous user: got %v, want domain.ErrNotFound", err)
+	}
+}
+
+func TestLoadOwner(t *testing.T) {
+	want := note{ID: 1, UserID: 7}
+
+	got, err := Load(context.Background(), 7, 1, fetchNote(want, nil))
+	if err != nil {
+		t.Fatalf("got error %v, want nil", err)
+	}
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadOtherUser(t *testing.T) {
+	got, err := Load(context.Background(), 8, 1, fetchNote(note{ID: 1, UserID: 7}, nil))
+	if err != domain.ErrNotFound {
+		t.Fatalf("got error %v, want domain.ErrNotFound", err)
+	}
+	if got != (note{}) {
+		t.Fatalf("got %+v, want the zero value", got)
+	}
+}
+
+func TestLoadRepositoryError(t *testing.T) {
+	repoErr := errors.New("connection refused")
+
+	_, err := Load(context.Background(), 7, 1, fetchNote(note{}, repoErr))
+	if err != repoErr {
+		t.Fatalf("got error %v, want %v", err, repoErr)
+	}
+
+	_, err = Load(context.Background(), 7, 1, fetchNote(note{}, domain.ErrNotFound))
+	if err != domain.ErrNotFound {
+		t.Fatalf("got error %v, want domain.ErrNotFound", err)
+	}
+}
@@ -0,0 +1,96 @@
+package role
+
+import (
+	"context"
+	"santapan/domain"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+type PostgresRepositoryQueries interface {
+	GetByName(ctx context.Context, name string) (domain.Role, error)
+	GetNamesByUserID(ctx context.Context, userID int64) ([]string, error)
+	GetPermissionsByUserID(ctx context.Context, userID int64) ([]string, error)
+}
+
+type PostgresRepositoryCommand interface {
+	AssignToUser(ctx context.Context, userID int64, roleID int64) error
+	RevokeFromUser(ctx context.Context, userID int64, roleID int64) error
+	AssignIfUnheld(ctx context.Context, userID int64, name string) (bool, error)
+}
+
+type Service struct {
+	postgresRepoQuery   PostgresRepositoryQueries
+	postgresRepoCommand PostgresRepositoryCommand
+	bootstrapAdminEmail string
+}
+
+// NewService will create a new role service object. The user who verified
+// bootstrapAdminEmail becomes admin as long as nobody holds the admin role.
+func NewService(pq PostgresRepositoryQueries, pc PostgresRepositoryCommand, bootstrapAdminEmail string) *Service {
+	return &Service{
+		postgresRepoQuery:   pq,
+		postgresRepoCommand: pc,
+		bootstrapAdminEmail: strings.ToLower(strings.TrimSpace(bootstrapAdminEmail)),
+	}
+}
+
+// GetAccess returns the roles of the user and the permissions they grant
+func (s *Service) GetAccess(ctx context.Context, userID int64) (res domain.Access, err error) {
+	res.Roles, err = s.postgresRepoQuery.GetNamesByUserID(ctx, userID)
+	if err != nil {
+		return domain.Access{}, err
+	}
+
+	res.Permissions, err = s.postgresRepoQuery.GetPermissionsByUserID(ctx, userID)
+	if err != nil {
+		return domain.Access{}, err
+	}
+
+	return res, nil
+}
+
+// Assign grants the named role to the user, returns domain.ErrNotFound for an unknown role
+func (s *Service) Assign(ctx context.Context, userID int64, name string) error {
+	role, err := s.postgresRepoQuery.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return s.postgresRepoCommand.AssignToUser(ctx, userID, role.ID)
+}
+
+// Revoke removes the named role from the user, returns domain.ErrNotFound for an unknown role
+func (s *Service) Revoke(ctx context.Context, userID int64, name string) error {
+	role, err := s.postgresRepoQuery.GetByName(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return s.postgresRepoCommand.RevokeFromUser(ctx, userID, role.ID)
+}
+
+// Bootstrap makes the user admin when they own the verified bootstrap admin email and
+// no admin exists yet. Once an admin exists, roles are only managed through the API.
+// An unverified address proves nothing, anyone could have registered it.
+func (s *Service) Bootstrap(ctx context.Context, user domain.User) error {
+	if s.bootstrapAdminEmail == "" || strings.ToLower(user.Email) != s.bootstrapAdminEmail || user.EmailVerifiedAt == nil {
+		return nil
+	}
+
+	granted, err := s.postgresRepoCommand.AssignIfUnheld(ctx, user.ID, domain.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if !granted {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":   "admin_bootstrap",
+		"user_id": user.ID,
+	}).Warn("Granted the admin role to the bootstrap admin")
+
+	return nil
+}
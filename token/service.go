@@ -2,21 +2,34 @@ package token
 
 import (
 	"context"
+	"crypto/subtle"
 	"santapan/domain"
 	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
 type PostgresRepositoryQueries interface {
 	GetByID(ctx context.Context, id int64) (res domain.Token, err error)
 	GetByUserID(ctx context.Context, id int64) (res domain.Token, err error)
+	GetByFamilyID(ctx context.Context, familyID string) (res domain.Token, err error)
+	GetByDeviceID(ctx context.Context, deviceID int64) (res domain.Token, err error)
 }
 
 type PostgresRepositoryCommand interface {
 	Store(ctx context.Context, token *domain.Token) (err error)
 	Delete(ctx context.Context, id int64) (err error)
 	Update(ctx context.Context, token *domain.Token) (err error)
+	Rotate(ctx context.Context, token *domain.Token, previous string) (err error)
+	DeleteByUserID(ctx context.Context, userID int64) (err error)
+	DeleteByDeviceID(ctx context.Context, deviceID int64) (err error)
+	PruneDeviceless(ctx context.Context, userID int64, keep int) (err error)
 }
 
+// MaxDevicelessSessions is how many sessions a user keeps from clients that sent no
+// device ID, logging in again drops the oldest one
+const MaxDevicelessSessions = 5
+
 //go:generate mockery --name ArticleRepository
 type Service struct {
 	postgresRepoQuery   PostgresRepositoryQueries
@@ -58,6 +71,14 @@ func (a *Service) Update(ctx context.Context, token *domain.Token) (err error) {
 	return a.postgresRepoCommand.Update(ctx, token)
 }
 
+func (s *Service) GetByDeviceID(ctx context.Context, deviceID int64) (res domain.Token, err error) {
+	return s.postgresRepoQuery.GetByDeviceID(ctx, deviceID)
+}
+
+func (s *Service) GetByFamilyID(ctx context.Context, familyID string) (res domain.Token, err error) {
+	return s.postgresRepoQuery.GetByFamilyID(ctx, familyID)
+}
+
 func (s *Service) Delete(ctx context.Context, id int64) (err error) {
 	existedToken, err := s.postgresRepoQuery.GetByID(ctx, id)
 	if err != nil {
@@ -65,3 +86,87 @@ func (s *Service) Delete(ctx context.Context, id int64) (err error) {
 	}
 	return s.postgresRepoCommand.Delete(ctx, existedToken.ID)
 }
+
+// DeleteByUserID revokes every refresh token family of the user
+func (s *Service) DeleteByUserID(ctx context.Context, userID int64) (err error) {
+	return s.postgresRepoCommand.DeleteByUserID(ctx, userID)
+}
+
+// DeleteByDeviceID revokes the refresh token family issued to the device
+func (s *Service) DeleteByDeviceID(ctx context.Context, deviceID int64) (err error) {
+	return s.postgresRepoCommand.DeleteByDeviceID(ctx, deviceID)
+}
+
+// PruneDeviceless keeps the MaxDevicelessSessions most recent unexpired sessions of the
+// user that are not tied to a device and removes the rest
+func (s *Service) PruneDeviceless(ctx context.Context, userID int64) error {
+	return s.postgresRepoCommand.PruneDeviceless(ctx, userID, MaxDevicelessSessions)
+}
+
+// IsSessionActive reports whether the token family an access token was issued
+// for still exists, so logged out or revoked sessions are rejected immediately
+func (s *Service) IsSessionActive(ctx context.Context, userID int64, familyID string) (bool, error) {
+	existedToken, err := s.postgresRepoQuery.GetByFamilyID(ctx, familyID)
+	if err == domain.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if existedToken.UserID != userID {
+		return false, nil
+	}
+
+	return existedToken.ExpiresAt.IsZero() || existedToken.ExpiresAt.After(time.Now()), nil
+}
+
+// Rotate replaces the stored refresh token of a family with the next one.
+// Presenting a token that no longer matches the stored one means it was
+// already rotated, so the whole family is revoked to lock out whoever holds it.
+func (s *Service) Rotate(ctx context.Context, userID int64, familyID string, presented string, next *domain.Token) (err error) {
+	existedToken, err := s.postgresRepoQuery.GetByFamilyID(ctx, familyID)
+	if err == domain.ErrNotFound {
+		return domain.ErrInvalidToken
+	}
+	if err != nil {
+		return
+	}
+
+	if existedToken.UserID != userID {
+		return domain.ErrInvalidToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(existedToken.RefreshToken), []byte(presented)) != 1 {
+		return s.revokeReused(ctx, existedToken)
+	}
+
+	next.ID = existedToken.ID
+	next.UserID = existedToken.UserID
+	next.DeviceID = existedToken.DeviceID
+	next.FamilyID = existedToken.FamilyID
+	next.CreatedAt = existedToken.CreatedAt
+	next.UpdatedAt = time.Now()
+
+	// The update only applies while the presented token is still stored, a concurrent
+	// rotation with the same token makes it a replay as well
+	err = s.postgresRepoCommand.Rotate(ctx, next, presented)
+	if err == domain.ErrTokenReused {
+		return s.revokeReused(ctx, existedToken)
+	}
+	return err
+}
+
+// revokeReused revokes the family of a refresh token that was presented after it had
+// been rotated
+func (s *Service) revokeReused(ctx context.Context, existedToken domain.Token) error {
+	logrus.WithFields(logrus.Fields{
+		"user_id":   existedToken.UserID,
+		"family_id": existedToken.FamilyID,
+	}).Warn("Refresh token reuse detected, revoking token family")
+
+	if err := s.postgresRepoCommand.Delete(ctx, existedToken.ID); err != nil {
+		return err
+	}
+	return domain.ErrTokenReused
+}
@@ -3,7 +3,10 @@ package user
 import (
 	"context"
 	"santapan/domain"
+	"santapan/pkg/password"
 	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
 // ArticleRepository represent the article's repository contract
@@ -11,23 +14,34 @@ import (
 //go:generate mockery --name ArticleRepository
 type PostgresRepositoryQueries interface {
 	GetByEmail(ctx context.Context, email string) (res domain.User, err error)
+	GetByID(ctx context.Context, id int64) (res domain.User, err error)
+	GetByPhone(ctx context.Context, phone string) (res domain.User, err error)
 }
 
 type PostgresRepositoryCommand interface {
 	Store(ctx context.Context, user *domain.User) (err error)
-	UpdatePhoneVerifiedAt(ctx context.Context, id int64, time time.Time) (err error)
+	UpdatePhoneVerifiedAt(ctx context.Context, id int64, phone string, time time.Time) (err error)
+	UpdateEmailVerifiedAt(ctx context.Context, id int64, time time.Time) (err error)
+	UpdateEmailVerificationSentAt(ctx context.Context, id int64, time time.Time) (err error)
+	UpdatePassword(ctx context.Context, id int64, password string) (err error)
+	UpdateProfile(ctx context.Context, user domain.User) (err error)
+	UpdatePendingEmail(ctx context.Context, id int64, email string) (err error)
+	ConfirmPendingEmail(ctx context.Context, id int64, email string, time time.Time) (err error)
+	UpdateDeletionScheduledAt(ctx context.Context, id int64, time *time.Time) (err error)
 }
 
 type Service struct {
 	postgresRepoQuery   PostgresRepositoryQueries
 	postgresRepoCommand PostgresRepositoryCommand
+	hasher              password.Hasher
 }
 
 // NewService will create a new article service object
-func NewService(pq PostgresRepositoryQueries, pc PostgresRepositoryCommand) *Service {
+func NewService(pq PostgresRepositoryQueries, pc PostgresRepositoryCommand, hasher password.Hasher) *Service {
 	return &Service{
 		postgresRepoQuery:   pq,
 		postgresRepoCommand: pc,
+		hasher:              hasher,
 	}
 }
 
@@ -35,10 +49,152 @@ func (s *Service) GetByEmail(ctx context.Context, email string) (res domain.User
 	return s.postgresRepoQuery.GetByEmail(ctx, email)
 }
 
-func (s *Service) UpdatePhoneVerifiedAt(ctx context.Context, id int64, time time.Time) (err error) {
-	return s.postgresRepoCommand.UpdatePhoneVerifiedAt(ctx, id, time)
+func (s *Service) GetByID(ctx context.Context, id int64) (res domain.User, err error) {
+	return s.postgresRepoQuery.GetByID(ctx, id)
+}
+
+func (s *Service) UpdateEmailVerifiedAt(ctx context.Context, id int64, time time.Time) (err error) {
+	return s.postgresRepoCommand.UpdateEmailVerifiedAt(ctx, id, time)
+}
+
+func (s *Service) UpdateEmailVerificationSentAt(ctx context.Context, id int64, time time.Time) (err error) {
+	return s.postgresRepoCommand.UpdateEmailVerificationSentAt(ctx, id, time)
+}
+
+// HashPassword hashes a plain password with the configured algorithm
+func (s *Service) HashPassword(plain string) (string, error) {
+	return s.hasher.Hash(plain)
+}
+
+// UpdatePassword hashes the plain password and stores it for the user
+func (s *Service) UpdatePassword(ctx context.Context, id int64, plain string) (err error) {
+	hash, err := s.hasher.Hash(plain)
+	if err != nil {
+		return err
+	}
+	return s.postgresRepoCommand.UpdatePassword(ctx, id, hash)
+}
+
+// VerifyPassword checks the plain password of the user. A hash made with an older
+// algorithm or other parameters is replaced on success, which is invisible to the user.
+func (s *Service) VerifyPassword(ctx context.Context, user domain.User, plain string) bool {
+	// Accounts created through social sign-in have no password until they set one
+	if user.Password == "" {
+		return false
+	}
+
+	ok, err := s.hasher.Verify(user.Password, plain)
+	if err != nil {
+		logrus.Error(err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+
+	if s.hasher.NeedsRehash(user.Password) {
+		hash, err := s.hasher.Hash(plain)
+		if err == nil {
+			err = s.postgresRepoCommand.UpdatePassword(ctx, user.ID, hash)
+		}
+		if err != nil {
+			logrus.Errorf("Failed to rehash password of user %d: %v", user.ID, err)
+		}
+	}
+
+	return true
+}
+
+// IsEmailVerified reports whether the user confirmed their email address
+func (s *Service) IsEmailVerified(ctx context.Context, id int64) (bool, error) {
+	user, err := s.postgresRepoQuery.GetByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	return user.EmailVerifiedAt != nil, nil
+}
+
+func (s *Service) GetByPhone(ctx context.Context, phone string) (res domain.User, err error) {
+	return s.postgresRepoQuery.GetByPhone(ctx, phone)
+}
+
+// UpdatePhoneVerifiedAt stores the phone number as verified for the user. A number
+// already verified by another account is rejected with domain.ErrConflict.
+func (s *Service) UpdatePhoneVerifiedAt(ctx context.Context, id int64, phone string, time time.Time) (err error) {
+	owner, err := s.postgresRepoQuery.GetByPhone(ctx, phone)
+	if err != nil && err != domain.ErrNotFound {
+		return err
+	}
+	if err == nil && owner.ID != id {
+		return domain.ErrConflict
+	}
+
+	return s.postgresRepoCommand.UpdatePhoneVerifiedAt(ctx, id, phone, time)
 }
 
 func (s *Service) Store(ctx context.Context, user *domain.User) (err error) {
 	return s.postgresRepoCommand.Store(ctx, user)
 }
+
+// UpdateProfile applies the given profile fields. Changing the phone number
+// clears its verification, the new number has to be verified again over OTP.
+func (s *Service) UpdateProfile(ctx context.Context, id int64, fullName *string, phone *string, avatarURL *string) (res domain.User, err error) {
+	res, err = s.postgresRepoQuery.GetByID(ctx, id)
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if fullName != nil {
+		res.FullName = *fullName
+	}
+	if avatarURL != nil {
+		res.AvatarURL = *avatarURL
+	}
+	if phone != nil && *phone != res.Phone {
+		res.Phone = *phone
+		res.PhoneVerifiedAt = nil
+	}
+
+	if err = s.postgresRepoCommand.UpdateProfile(ctx, res); err != nil {
+		return domain.User{}, err
+	}
+
+	return res, nil
+}
+
+// RequestEmailChange records the new address until it is verified. An address
+// already used by another account is rejected with domain.ErrConflict.
+func (s *Service) RequestEmailChange(ctx context.Context, id int64, email string) (err error) {
+	owner, err := s.postgresRepoQuery.GetByEmail(ctx, email)
+	if err != nil && err != domain.ErrNotFound {
+		return err
+	}
+	if err == nil && owner.ID != id {
+		return domain.ErrConflict
+	}
+
+	return s.postgresRepoCommand.UpdatePendingEmail(ctx, id, email)
+}
+
+// ConfirmEmailChange switches the user to the pending address once the link sent to it is opened
+func (s *Service) ConfirmEmailChange(ctx context.Context, id int64, email string, time time.Time) (err error) {
+	owner, err := s.postgresRepoQuery.GetByEmail(ctx, email)
+	if err != nil && err != domain.ErrNotFound {
+		return err
+	}
+	if err == nil && owner.ID != id {
+		return domain.ErrConflict
+	}
+
+	return s.postgresRepoCommand.ConfirmPendingEmail(ctx, id, email, time)
+}
+
+// ScheduleDeletion marks the account for deletion at the given time
+func (s *Service) ScheduleDeletion(ctx context.Context, id int64, at time.Time) (err error) {
+	return s.postgresRepoCommand.UpdateDeletionScheduledAt(ctx, id, &at)
+}
+
+// CancelDeletion restores an account whose deletion is still in its grace period
+func (s *Service) CancelDeletion(ctx context.Context, id int64) (err error) {
+	return s.postgresRepoCommand.UpdateDeletionScheduledAt(ctx, id, nil)
+}
@@ -0,0 +1,208 @@
+package voucher
+
+import (
+	"context"
+	"santapan/domain"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type PostgresRepositoryQueries interface {
+	GetByCode(ctx context.Context, code string) (domain.Voucher, error)
+	Fetch(ctx context.Context) ([]domain.Voucher, error)
+	EligibleSubtotal(ctx context.Context, voucherID int64, cartID int64) (domain.Money, error)
+	CountRedemptions(ctx context.Context, voucherID int64, userID int64) (int, int, error)
+}
+
+type PostgresRepositoryCommand interface {
+	Store(ctx context.Context, voucher *domain.Voucher) error
+	Deactivate(ctx context.Context, id int64) error
+}
+
+// CartService supplies the active cart priced at the current prices
+type CartService interface {
+	GetActive(ctx context.Context, userID int64) (domain.Cart, error)
+}
+
+type Service struct {
+	postgresRepoQuery   PostgresRepositoryQueries
+	postgresRepoCommand PostgresRepositoryCommand
+	cartService         CartService
+}
+
+// NewService will create a new voucher service object
+func NewService(pq PostgresRepositoryQueries, pc PostgresRepositoryCommand, cartService CartService) *Service {
+	return &Service{
+		postgresRepoQuery:   pq,
+		postgresRepoCommand: pc,
+		cartService:         cartService,
+	}
+}
+
+// Preview quotes the voucher on the active cart of the user without redeeming it
+func (s *Service) Preview(ctx context.Context, userID int64, code string) (domain.VoucherQuote, error) {
+	cart, err := s.cartService.GetActive(ctx, userID)
+	if err != nil {
+		return domain.VoucherQuote{}, err
+	}
+
+	return s.Quote(ctx, userID, code, cart)
+}
+
+// Quote returns the discount the voucher gives on the cart. Returns domain.ErrNotFound
+// for an unknown code, domain.ErrBadParamInput for an empty cart and the voucher
+// errors when the voucher can not be used. The usage limits are checked again when
+// the voucher is redeemed at checkout.
+func (s *Service) Quote(ctx context.Context, userID int64, code string, cart domain.Cart) (domain.VoucherQuote, error) {
+	if len(cart.Items) == 0 {
+		return domain.VoucherQuote{}, domain.ErrBadParamInput
+	}
+
+	voucher, err := s.postgresRepoQuery.GetByCode(ctx, normalizeCode(code))
+	if err != nil {
+		return domain.VoucherQuote{}, err
+	}
+
+	if !voucher.ValidAt(time.Now()) {
+		return domain.VoucherQuote{}, domain.ErrVoucherUnavailable
+	}
+
+	total, byUser, err := s.postgresRepoQuery.CountRedemptions(ctx, voucher.ID, userID)
+	if err != nil {
+		return domain.VoucherQuote{}, err
+	}
+	if (voucher.UsageLimit != nil && total >= *voucher.UsageLimit) || (voucher.UsageLimitPerUser != nil && byUser >= *voucher.UsageLimitPerUser) {
+		return domain.VoucherQuote{}, domain.ErrVoucherLimitReached
+	}
+
+	eligible, err := s.postgresRepoQuery.EligibleSubtotal(ctx, voucher.ID, cart.ID)
+	if err != nil {
+		return domain.VoucherQuote{}, err
+	}
+	if eligible <= 0 {
+		return domain.VoucherQuote{}, domain.ErrVoucherNotEligible
+	}
+	if eligible < voucher.MinSpend {
+		return domain.VoucherQuote{}, domain.ErrVoucherMinSpend
+	}
+
+	discount := voucher.Discount(eligible)
+
+	return domain.VoucherQuote{
+		VoucherID:        voucher.ID,
+		Code:             voucher.Code,
+		Description:      voucher.Description,
+		ItemsTotal:       cart.TotalPrice,
+		EligibleSubtotal: eligible,
+		Discount:         discount,
+		Total:            cart.TotalPrice - discount,
+	}, nil
+}
+
+// Fetch returns every voucher
+func (s *Service) Fetch(ctx context.Context) ([]domain.Voucher, error) {
+	return s.postgresRepoQuery.Fetch(ctx)
+}
+
+// Create stores a new voucher. Returns domain.ErrBadParamInput for a discount that does
+// not match its type or a validity window that ends before it starts, and domain.ErrConflict when
+// the code is taken.
+func (s *Service) Create(ctx context.Context, actorID int64, body domain.CreateVoucherBody) (domain.Voucher, error) {
+	voucher := domain.Voucher{
+		Code:              normalizeCode(body.Code),
+		Description:       body.Description,
+		DiscountType:      body.DiscountType,
+		DiscountAmount:    body.DiscountAmount,
+		DiscountPercent:   body.DiscountPercent,
+		MinSpend:          body.MinSpend,
+		MaxDiscount:       body.MaxDiscount,
+		StartsAt:          time.Now(),
+		EndsAt:            body.EndsAt,
+		UsageLimit:        body.UsageLimit,
+		UsageLimitPerUser: body.UsageLimitPerUser,
+		Active:            true,
+		CategoryIDs:       uniqueIDs(body.CategoryIDs),
+		MenuIDs:           uniqueIDs(body.MenuIDs),
+		BundlingTypes:     make([]string, 0),
+	}
+	if body.StartsAt != nil {
+		voucher.StartsAt = *body.StartsAt
+	}
+	for _, bundlingType := range body.BundlingTypes {
+		if !contains(voucher.BundlingTypes, bundlingType) {
+			voucher.BundlingTypes = append(voucher.BundlingTypes, bundlingType)
+		}
+	}
+
+	if !validDiscount(voucher) {
+		return domain.Voucher{}, domain.ErrBadParamInput
+	}
+	if voucher.EndsAt != nil && !voucher.EndsAt.After(voucher.StartsAt) {
+		return domain.Voucher{}, domain.ErrBadParamInput
+	}
+
+	if err := s.postgresRepoCommand.Store(ctx, &voucher); err != nil {
+		return domain.Voucher{}, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":      "voucher_created",
+		"voucher_id": voucher.ID,
+		"code":       voucher.Code,
+		"actor_id":   actorID,
+	}).Info("Voucher created")
+
+	return voucher, nil
+}
+
+// Deactivate stops a voucher from being redeemed
+func (s *Service) Deactivate(ctx context.Context, actorID int64, id int64) error {
+	if err := s.postgresRepoCommand.Deactivate(ctx, id); err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"event":      "voucher_deactivated",
+		"voucher_id": id,
+		"actor_id":   actorID,
+	}).Info("Voucher deactivated")
+
+	return nil
+}
+
+// validDiscount reports whether a fixed voucher has only an amount and a percentage
+// voucher only a percent of at most 100
+func validDiscount(voucher domain.Voucher) bool {
+	if voucher.DiscountType == domain.VoucherDiscountFixed {
+		return voucher.DiscountAmount > 0 && voucher.DiscountPercent == 0
+	}
+	return voucher.DiscountPercent > 0 && voucher.DiscountPercent <= 100 && voucher.DiscountAmount == 0
+}
+
+// normalizeCode makes codes case insensitive, they are stored in upper case
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func uniqueIDs(ids []int64) []int64 {
+	res := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool)
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			res = append(res, id)
+		}
+	}
+	return res
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}